@@ -0,0 +1,41 @@
+package main
+
+import (
+	"github.com/yjinheon/lazyflow/internal/app"
+	"github.com/yjinheon/lazyflow/internal/fakeairflow"
+)
+
+// Credentials the demo client logs in with; the fake server accepts any.
+const (
+	demoUser     = "demo"
+	demoPassword = "demo"
+)
+
+// startDemo serves the scenario at path (the built-in one when empty) on a
+// free local port and returns its base URL.
+func startDemo(path string) (string, *fakeairflow.Server, error) {
+	sc := fakeairflow.DefaultScenario()
+	if path != "" {
+		var err error
+		if sc, err = fakeairflow.LoadScenario(path); err != nil {
+			return "", nil, err
+		}
+	}
+	srv := fakeairflow.New(sc, nil)
+	url, err := srv.Start("127.0.0.1:0")
+	if err != nil {
+		return "", nil, err
+	}
+	return url, srv, nil
+}
+
+// demoConfig points the defaults at the fake server. The config file is not
+// read, so a demo never touches a real cluster's credentials or its SQLite
+// history; the cache lives in memory.
+func demoConfig(url string) app.Config {
+	cfg := app.DefaultConfig()
+	cfg.Airflow.BaseURL = url
+	cfg.Airflow.Auth = app.AuthConfig{Type: "basic", Username: demoUser, Password: demoPassword}
+	cfg.Cache.Enabled = false
+	return cfg
+}
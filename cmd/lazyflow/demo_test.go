@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yjinheon/lazyflow/internal/api"
+	"github.com/yjinheon/lazyflow/internal/fakeairflow"
+)
+
+// TestDemoWiring runs the client, cache and backfill plumbing main builds
+// against the simulated Airflow.
+func TestDemoWiring(t *testing.T) {
+	srv := httptest.NewServer(fakeairflow.New(fakeairflow.DefaultScenario(), nil))
+	defer srv.Close()
+	cfg := demoConfig(srv.URL)
+	client := newClient(cfg)
+	history := newHistoryCache(cfg)
+	defer history.Close()
+	ctx := context.Background()
+
+	dags, err := client.GetDAGs(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active, paused := countDAGActivity(dags.DAGs); active != 4 || paused != 1 {
+		t.Fatalf("active=%d paused=%d, want 4/1", active, paused)
+	}
+
+	runs, err := client.GetAllDAGRuns(ctx, &api.ListOptions{OrderBy: "-run_after", Limit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cacheDAGRunsByDAG(history, runs.DAGRuns)
+	if _, ok := history.GetDAGRunsHistory("orders_etl", time.Time{}, 0); !ok {
+		t.Fatal("orders_etl runs were not cached")
+	}
+
+	// reports_daily runs every 3m; this window holds ten intervals.
+	hour := time.Now().Add(-2 * time.Hour).UTC().Truncate(time.Hour)
+	bf, err := client.CreateBackfill(ctx, map[string]any{
+		"dag_id":    "reports_daily",
+		"from_date": hour.Add(time.Minute).Format(time.RFC3339),
+		"to_date":   hour.Add(31 * time.Minute).Format(time.RFC3339),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dagRuns, err := client.GetDAGRuns(ctx, "reports_daily", &api.ListOptions{Limit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	countBackfillRuns(bf, dagRuns.DAGRuns)
+	if bf.TotalRuns != 10 || bf.RunningRuns != 10 {
+		t.Fatalf("backfill runs total=%d running=%d, want 10 queued", bf.TotalRuns, bf.RunningRuns)
+	}
+
+	if err := client.PauseDAG(ctx, "orders_etl"); err != nil {
+		t.Fatal(err)
+	}
+	if dags, err = client.GetDAGs(ctx, &api.ListOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, paused := countDAGActivity(dags.DAGs); paused != 2 {
+		t.Fatalf("paused=%d after pausing orders_etl, want 2", paused)
+	}
+}
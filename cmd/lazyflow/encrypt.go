@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/yjinheon/lazyflow/internal/app"
+)
+
+// runEncrypt is `lazyflow encrypt`: it reads a secret from stdin and prints
+// the enc:v1: value to paste into the config as password or token.
+func runEncrypt(args []string) int {
+	fs := flag.NewFlagSet("encrypt", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: printf %%s \"$SECRET\" | lazyflow encrypt\n\n"+
+			"Prints an encrypted value for auth.password or auth.token. The key is\n"+
+			"$%s, or %s (created on first use).\n", app.SecretKeyEnv, app.SecretKeyPath())
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if st, err := os.Stdin.Stat(); err == nil && st.Mode()&os.ModeCharDevice != 0 {
+		fmt.Fprint(os.Stderr, "secret (input is echoed): ")
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	secret := strings.TrimRight(line, "\r\n")
+	if secret == "" {
+		if err != nil && !errors.Is(err, io.EOF) {
+			fmt.Fprintf(os.Stderr, "lazyflow encrypt: %v\n", err)
+		} else {
+			fmt.Fprintln(os.Stderr, "lazyflow encrypt: empty secret")
+		}
+		return 1
+	}
+	enc, err := app.EncryptSecret(secret)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lazyflow encrypt: %v\n", err)
+		return 1
+	}
+	fmt.Println(enc)
+	return 0
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/yjinheon/lazyflow/internal/app"
+	"github.com/yjinheon/lazyflow/internal/exporter"
+)
+
+// runExporter is the headless `lazyflow exporter` mode: it polls the cluster
+// like the TUI does and serves the rollups on /metrics. Logs go to stderr.
+func runExporter(args []string) int {
+	fs := flag.NewFlagSet("exporter", flag.ContinueOnError)
+	listen := fs.String("listen", "", "address to serve /metrics on, e.g. :9464 (required)")
+	interval := fs.Duration("interval", 30*time.Second, "how often to poll dag runs and task instances")
+	window := fs.Duration("window", 0, "lookback for run and task stats (default: ui.rollup_window)")
+	debug := fs.Bool("debug", false, "also log the TUI's freeze-diagnostics trace")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	log.SetFlags(log.LstdFlags)
+	if *debug {
+		log.SetOutput(os.Stderr)
+	} else {
+		log.SetOutput(traceFilter{os.Stderr})
+	}
+	if *listen == "" {
+		fmt.Fprintln(os.Stderr, "lazyflow exporter: --listen is required")
+		fs.Usage()
+		return 2
+	}
+
+	cfg, err := app.LoadConfig()
+	if err != nil {
+		log.Printf("load config: %v", err)
+		return 1
+	}
+	if *window <= 0 {
+		*window = app.ParseDuration(cfg.UI.RollupWindow, 168*time.Hour)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	c := newHistoryCache(cfg)
+	defer c.Close()
+	poller := app.NewPoller(ctx)
+	defer poller.Stop()
+
+	exp := exporter.New(newClient(cfg), c, *window)
+	exp.Start(poller, exporter.Intervals{
+		Runs:   *interval,
+		Pools:  app.ParseDuration(cfg.UI.RefreshIntervals.Pools, 10*time.Second),
+		Health: app.ParseDuration(cfg.UI.RefreshIntervals.Health, 10*time.Second),
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exp)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	srv := &http.Server{Addr: *listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	log.Printf("lazyflow exporter: serving %s/metrics for %s (window %s)", *listen, cfg.Airflow.BaseURL, *window)
+
+	select {
+	case err := <-errc:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("lazyflow exporter: %v", err)
+			return 1
+		}
+	case <-ctx.Done():
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdown)
+	}
+	return 0
+}
+
+// traceFilter drops debugutil.Tag lines ("[FZ-..."): the shared API client
+// and poller emit several per request, which is noise for a long-running
+// exporter. log.Logger issues one Write per line.
+type traceFilter struct{ w io.Writer }
+
+func (f traceFilter) Write(p []byte) (int, error) {
+	if bytes.Contains(p, []byte(" [FZ-")) {
+		return len(p), nil
+	}
+	return f.w.Write(p)
+}
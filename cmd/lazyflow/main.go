@@ -3,9 +3,13 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/user"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -24,7 +28,32 @@ import (
 	"github.com/yjinheon/lazyflow/pkg/airflow/models"
 )
 
+// anomalyHistory is how far back task durations are read to judge whether a
+// task in the selected run is unusually slow.
+const anomalyHistory = 30 * 24 * time.Hour
+
+// maxActionRows is how much of the audit trail the Action History page shows.
+const maxActionRows = 200
+
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "exporter":
+			os.Exit(runExporter(os.Args[2:]))
+		case "export":
+			os.Exit(runExport(os.Args[2:]))
+		case "import":
+			os.Exit(runImport(os.Args[2:]))
+		case "encrypt":
+			os.Exit(runEncrypt(os.Args[2:]))
+		}
+	}
+	flags := flag.NewFlagSet("lazyflow", flag.ExitOnError)
+	readOnlyFlag := flags.Bool("read-only", false, "disable every action that changes Airflow state (overrides airflow.read_only)")
+	demoFlag := flags.Bool("demo", false, "run against a built-in simulated Airflow instead of the configured one")
+	scenarioFlag := flags.String("scenario", "", "scenario file for the simulated Airflow (implies --demo)")
+	_ = flags.Parse(os.Args[1:])
+
 	// Debug log to file with microsecond resolution so we can correlate
 	// freezes with the last log line emitted before the UI stopped responding.
 	logFile, _ := os.Create("lazyflow.log")
@@ -56,32 +85,134 @@ func main() {
 	dispatcher := app.NewDispatcher(256)
 	go dispatcher.Start(context.Background(), tviewApp)
 
-	cfg, err := app.LoadConfig()
-	if err != nil {
-		log.Fatalf("load config: %v", err)
+	var cfg app.Config
+	if *demoFlag || *scenarioFlag != "" {
+		url, demo, err := startDemo(*scenarioFlag)
+		if err != nil {
+			log.Fatalf("demo: %v", err)
+		}
+		defer demo.Close()
+		cfg = demoConfig(url)
+	} else {
+		var err error
+		if cfg, err = app.LoadConfig(); err != nil {
+			log.Fatalf("load config: %v", err)
+		}
+	}
+	if *readOnlyFlag {
+		cfg.Airflow.ReadOnly = true
 	}
 
 	theme.ApplyTheme(theme.TokyoNightStorm)
 	mainLayout := layout.NewMainLayout(tviewApp)
+	mainLayout.SetSafety(cfg.Airflow.ReadOnly, cfg.Airflow.Protected)
 	store := state.NewStore()
 	bfCache := newHistoryCache(cfg)
 	defer bfCache.Close()
 
-	client := api.NewClient(api.ClientConfig{
-		BaseURL:  cfg.Airflow.BaseURL,
-		Username: cfg.Airflow.Auth.Username,
-		Password: cfg.Airflow.Auth.Password,
-		Token:    cfg.Airflow.Auth.Token,
-		AuthType: cfg.Airflow.Auth.Type,
-		Timeout:  app.ParseDuration(cfg.Airflow.Timeout, 30*time.Second),
-	})
+	client := newClient(cfg)
+	// Fetches and actions the user is waiting on go ahead of background
+	// polls in the client's request queue.
+	userCtx := api.WithPriority(context.Background(), api.Interactive)
 
 	poller := app.NewPoller(context.Background())
 	defer poller.Stop()
+	poller.SetPolicy(cfg.UI.Polling.PollPolicy())
 
 	// Lookback window for the cluster KPI bar.
 	rollupWindow := app.ParseDuration(cfg.UI.RollupWindow, 168*time.Hour)
 
+	// Per-DAG SLA rules; a bad entry is logged and skipped.
+	slas, slaErrs := app.ParseSLAs(cfg.SLA)
+	for _, err := range slaErrs {
+		log.Printf("[ERROR] %v", err)
+	}
+	if len(slaErrs) > 0 {
+		mainLayout.StatusBar().SetError(fmt.Sprintf("%d invalid SLA entries (see lazyflow.log)", len(slaErrs)))
+	}
+	mainLayout.SLA().Update(nil, len(slas))
+
+	// Offline mode: polls report whether they reached the API. After three
+	// straight transport failures the UI serves runs, tasks, DAG metadata and
+	// the Monitor tab from the history cache and refuses actions; the first
+	// successful poll switches back to live data.
+	//
+	// Every poll and fetch also records its outcome per endpoint, which drives
+	// the header and status bar health and the API errors page (!).
+	conn := app.NewConnectivity(3, store.SetOffline)
+	track := func(endpoint string, err error) error {
+		switch {
+		case err == nil:
+			conn.Success()
+			store.RecordAPIResult(endpoint, nil, "")
+		case errors.Is(err, context.Canceled):
+			// A poller restarted on selection change; says nothing about the API.
+		case api.IsUnreachable(err):
+			conn.Failure(err)
+			store.RecordAPIResult(endpoint, err, state.APIErrUnreachable)
+		case api.IsAuthError(err):
+			conn.Success() // reachable, but nothing will load until credentials change
+			store.RecordAPIResult(endpoint, err, state.APIErrAuth)
+		default:
+			conn.Success() // the API answered, just not with what we wanted
+			store.RecordAPIResult(endpoint, err, state.APIErrOther)
+		}
+		return err
+	}
+	// readOnly refuses an API action in read-only mode or while offline. It
+	// runs on the tview goroutine, from key handlers.
+	readOnly := func(action string) bool {
+		switch {
+		case cfg.Airflow.ReadOnly:
+			mainLayout.StatusBar().SetError(fmt.Sprintf("%s is disabled in read-only mode", action))
+		case store.Offline():
+			mainLayout.StatusBar().SetError(fmt.Sprintf("%s is unavailable offline (showing cached data)", action))
+		default:
+			return false
+		}
+		return true
+	}
+	// protect runs fn straight away, or on a protected cluster only after the
+	// user has typed the DAG id.
+	protect := func(action, dagId string, fn func()) {
+		if !cfg.Airflow.Protected {
+			fn()
+			return
+		}
+		mainLayout.ShowTypedConfirmModal(" Protected cluster ",
+			fmt.Sprintf("[red]%s[-] on protected cluster [yellow]%s[-].", action, tview.Escape(cfg.Airflow.BaseURL)),
+			dagId, fn)
+	}
+	// confirm asks before fn: a yes/no modal, or the typed DAG id on a
+	// protected cluster.
+	confirm := func(title, message, dagId string, fn func()) {
+		if cfg.Airflow.Protected {
+			protect(strings.TrimSpace(title), dagId, fn)
+			return
+		}
+		mainLayout.ShowConfirmModal(title, message, fn)
+	}
+	// Cache fallbacks used when a fetch cannot reach the API.
+	serveCachedRuns := func(dagId string) {
+		if runs, ok := bfCache.GetDAGRunsHistory(dagId, time.Time{}, 50); ok {
+			store.SetDAGRuns(dagId, runs)
+		}
+	}
+	serveCachedTaskInstances := func(dagId, runId string) {
+		if tis := cachedRunTasks(bfCache, dagId, runId); len(tis) > 0 {
+			store.SetTaskInstances(dagId, runId, tis)
+			store.SetCriticalPath(views.ComputeCriticalPath(store.GetTasks(dagId), tis, time.Now()))
+		}
+	}
+	serveCachedRollup := func() {
+		now := time.Now()
+		runs, _ := bfCache.GetAllDAGRunsHistory(now.Add(-rollupWindow), 0)
+		store.SetDAGStateRollup(metrics.RollupLatestState(runs))
+		if len(slas) > 0 {
+			store.SetSLABreaches(evaluateSLAs(slas, bfCache, store, runs, now))
+		}
+	}
+
 	// Top KPI cards double as DAG-list filter tabs.
 	mainLayout.KpiBar().SetOnSelected(func(filter string) {
 		mainLayout.DagList().SetFilter(filter)
@@ -119,6 +250,20 @@ func main() {
 		})
 	})
 
+	// SLA breaches updated → KPI card, DAG-list badges and the SLA page.
+	store.Subscribe(state.EventSLAUpdated, func(_ any) {
+		dispatcher.Post(func() {
+			breaches := store.GetSLABreaches()
+			breached := make(map[string]bool, len(breaches))
+			for _, b := range breaches {
+				breached[b.DagId] = true
+			}
+			mainLayout.KpiBar().SetSLABreachCount(len(breached))
+			mainLayout.DagList().SetSLABreaches(breached)
+			mainLayout.SLA().Update(breaches, len(slas))
+		})
+	})
+
 	// Pools updated → refresh cluster panel pool bars
 	store.Subscribe(state.EventPoolsUpdated, func(_ any) {
 		dispatcher.Post(func() {
@@ -134,6 +279,9 @@ func main() {
 	backfilledDAGs := map[string]bool{}
 
 	backfillMonitorHistory := func(dagId string) error {
+		if store.Offline() {
+			return nil // the cache is all there is; retried once back online
+		}
 		monitorMu.Lock()
 		done := backfilledDAGs[dagId]
 		backfilledDAGs[dagId] = true
@@ -148,8 +296,14 @@ func main() {
 			res, err := client.GetDAGRuns(ctx, dagId, &api.ListOptions{
 				Limit: 100, Offset: page * 100, OrderBy: "-run_after",
 			})
-			if err != nil {
+			if track("monitor", err) != nil {
 				log.Printf("[ERROR] monitor backfill %s: %v", dagId, err)
+				if api.IsUnreachable(err) {
+					monitorMu.Lock()
+					delete(backfilledDAGs, dagId)
+					monitorMu.Unlock()
+					return nil // offline: show what the cache has
+				}
 				return err
 			}
 			if len(res.DAGRuns) == 0 {
@@ -174,8 +328,20 @@ func main() {
 		}
 		dagId := store.SelectedDAG()
 		window := mainLayout.Monitor().Window()
-		if dagId == "" {
-			dispatcher.Post(func() { mainLayout.Monitor().Update("", nil, nil) })
+		if dagId == "" || mainLayout.Monitor().FleetMode() {
+			// Fleet table: every DAG's aggregates straight from the cache,
+			// which the rollup poll keeps filled for the whole cluster.
+			dispatcher.Post(func() { mainLayout.Monitor().SetFleetLoading() })
+			go func() {
+				since := time.Now().Add(-window)
+				rows, _ := bfCache.GetDagDashboardRows(since, 0)
+				runs, _ := bfCache.GetAllDAGRunsHistory(since, 0)
+				dispatcher.Post(func() {
+					if store.ActiveTab() == "monitor" && mainLayout.Monitor().FleetMode() {
+						mainLayout.Monitor().UpdateFleet(rows, runs)
+					}
+				})
+			}()
 			return
 		}
 		dispatcher.Post(func() {
@@ -201,8 +367,22 @@ func main() {
 		}()
 	}
 
+	// Enter on a fleet row drills into that DAG's dashboard.
+	mainLayout.Monitor().SetOnFleetSelected(func(dagId string) {
+		if store.SelectedDAG() == dagId {
+			refreshMonitor()
+			return
+		}
+		mainLayout.DagList().SelectDag(dagId)
+	})
+
 	store.Subscribe(state.EventDAGSelected, func(_ any) { refreshMonitor() })
 	store.Subscribe(state.EventDAGRunsUpdated, func(_ any) { refreshMonitor() })
+	store.Subscribe(state.EventDAGStateRollupUpdated, func(_ any) {
+		if mainLayout.Monitor().FleetMode() {
+			refreshMonitor() // the rollup poll just refreshed every DAG's history
+		}
+	})
 	store.Subscribe(state.EventTabChanged, func(_ any) {
 		if store.ActiveTab() == "monitor" {
 			refreshMonitor()
@@ -215,6 +395,14 @@ func main() {
 		dispatcher.Post(func() { mainLayout.StatusBar().SetContext(tab, hasDAG) })
 	}
 	store.Subscribe(state.EventTabChanged, func(_ any) { updateHints() })
+	// Loops feeding hidden tabs slow down; see app.ForTabs.
+	store.Subscribe(state.EventTabChanged, func(_ any) { poller.SetActiveTab(store.ActiveTab()) })
+	// The grid fetches a run's instances per poll; it only polls while shown.
+	store.Subscribe(state.EventTabChanged, func(_ any) {
+		if store.ActiveTab() != "grid" {
+			poller.StopSub("grid")
+		}
+	})
 	store.Subscribe(state.EventDAGSelected, func(_ any) { updateHints() })
 
 	// Selection → update status bar
@@ -258,7 +446,7 @@ func main() {
 				mainLayout.Tasks().UpdateGantt(store.SelectedRun(), tis, store.GetCriticalPath())
 			}
 			if mainLayout.Lineage().IsGraphMode() {
-				mainLayout.Lineage().UpdateGraph(stateByTask(tis))
+				mainLayout.Lineage().UpdateGraph(store.SelectedRun(), tis)
 			}
 		})
 		// Critical-path recompute off the UI goroutine.
@@ -276,7 +464,7 @@ func main() {
 			tasks := store.GetTasks(store.SelectedDAG())
 			mainLayout.Lineage().SetTasks(store.SelectedDAG(), tasks)
 			if mainLayout.Lineage().IsGraphMode() {
-				mainLayout.Lineage().UpdateGraph(stateByTask(store.GetTaskInstances(store.SelectedDAG(), store.SelectedRun())))
+				mainLayout.Lineage().UpdateGraph(store.SelectedRun(), store.GetTaskInstances(store.SelectedDAG(), store.SelectedRun()))
 			}
 			if store.SelectedRun() == "" {
 				mainLayout.Tasks().UpdateDefinitions(store.SelectedDAG(), tasks)
@@ -332,6 +520,122 @@ func main() {
 		})
 	})
 
+	// fetchTasks loads a DAG's task definitions with their task groups. The
+	// groups come from the UI structure endpoint; when that fails the tasks
+	// are still returned, flat.
+	fetchTasks := func(ctx context.Context, dagId string) ([]models.Task, error) {
+		tasks, err := client.GetTasks(ctx, dagId)
+		if track("tasks", err) != nil {
+			return nil, err
+		}
+		structure, err := client.GetDAGStructure(ctx, dagId)
+		if err != nil {
+			log.Printf("[ERROR] GetDAGStructure %s: %v (tasks shown without groups)", dagId, err)
+			return tasks.Tasks, nil
+		}
+		groups := structure.TaskGroups()
+		for i := range tasks.Tasks {
+			tasks.Tasks[i].Groups = groups[tasks.Tasks[i].TaskId]
+		}
+		return tasks.Tasks, nil
+	}
+
+	// loadDAGDetails fetches a DAG's task definitions (lineage) and source,
+	// falling back to the cached copies when the API is unreachable.
+	loadDAGDetails := func(dagId string) {
+		go func() {
+			tasks, err := fetchTasks(userCtx, dagId)
+			if err != nil {
+				if cached, ok := bfCache.GetTasks(dagId); ok && api.IsUnreachable(err) {
+					store.SetTasks(dagId, cached)
+				}
+				return
+			}
+			bfCache.PutTasks(dagId, tasks)
+			store.SetTasks(dagId, tasks)
+		}()
+
+		go func() {
+			code, err := client.GetDAGSource(userCtx, dagId)
+			if track("source", err) != nil {
+				cached, ok := bfCache.GetDAGSource(dagId)
+				if !ok || !api.IsUnreachable(err) {
+					dispatcher.Post(func() { mainLayout.Code().SetError(err.Error()) })
+					return
+				}
+				code = cached
+			} else {
+				bfCache.PutDAGSource(dagId, code)
+			}
+			// Highlight here, not in the posted closure: it is CPU-bound and
+			// would block the tview goroutine.
+			markup := views.HighlightPython(code)
+			dispatcher.Post(func() { mainLayout.Code().SetHighlighted(markup) })
+		}()
+	}
+
+	// Grid: the DAG's recent runs (kept fresh by the runs poller) against its
+	// tasks. A finished run's instances no longer change, so each is read from
+	// the history cache or fetched once; active runs are refetched every poll.
+	openGrid := func(dagId string) {
+		mainLayout.Grid().SetLoading(dagId)
+		var mu sync.Mutex
+		settled := map[string][]models.TaskInstance{}
+		seeded := false
+		load := func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if !seeded {
+				seeded = true
+				history, _ := bfCache.GetTaskInstancesHistory(dagId, time.Time{}, 0)
+				for _, ti := range history {
+					settled[ti.RunId] = append(settled[ti.RunId], ti)
+				}
+				for runId, tis := range settled {
+					if !taskInstancesSettled(tis) {
+						delete(settled, runId)
+					}
+				}
+			}
+			runs := views.GridColumns(store.GetDAGRuns(dagId))
+			var tis []models.TaskInstance
+			var errs []error
+			for _, r := range runs {
+				finished := r.State == "success" || r.State == "failed"
+				if cached, ok := settled[r.RunId]; ok && finished {
+					tis = append(tis, cached...)
+					continue
+				}
+				got, err := client.GetTaskInstances(ctx, dagId, r.RunId, &api.ListOptions{Limit: 100})
+				if track("task_instances", err) != nil {
+					errs = append(errs, err)
+					continue
+				}
+				bfCache.PutTaskInstances(dagId, r.RunId, got.TaskInstances)
+				if finished && taskInstancesSettled(got.TaskInstances) {
+					settled[r.RunId] = got.TaskInstances
+				}
+				tis = append(tis, got.TaskInstances...)
+			}
+			err := errors.Join(errs...)
+			defs := store.GetTasks(dagId)
+			dispatcher.Post(func() {
+				if mainLayout.Grid().DagId() != dagId {
+					return
+				}
+				if err != nil && len(tis) == 0 && len(runs) > 0 {
+					mainLayout.Grid().SetError(err.Error())
+					return
+				}
+				mainLayout.Grid().Update(dagId, runs, defs, tis)
+			})
+			return err
+		}
+		go load(userCtx)
+		poller.Restart("grid", 5*time.Second, load, app.ForTabs("grid"),
+			app.BoostWhen(func() bool { return dagHasActiveRun(store, dagId) }))
+	}
+
 	// DAG selected → info panel, fetch runs + lineage + code
 	mainLayout.DagList().SetOnSelected(func(dagId string) {
 		debugutil.Tag("FZ-evt", "DagList.OnSelected START dagId=%s", dagId)
@@ -341,9 +645,13 @@ func main() {
 		poller.StopSub("exec-logs")
 		store.SetCriticalPath(nil)
 		mainLayout.Runs().ClearFilter() // new DAG → drop any stale run-state filter
+		mainLayout.Runs().ClearMarks()
 		mainLayout.Tasks().UpdateDefinitions(dagId, nil)
 		mainLayout.Logs().SetMessage("Select a DAG run and task to view logs")
 		mainLayout.Code().SetMessage("Loading DAG source...")
+		if store.ActiveTab() == "grid" {
+			openGrid(dagId)
+		}
 
 		for _, d := range store.GetDAGs() {
 			if d.DagId == dagId {
@@ -354,10 +662,13 @@ func main() {
 
 		// Fetch runs (immediate)
 		go func() {
-			ctx := context.Background()
+			ctx := userCtx
 			runs, err := client.GetDAGRuns(ctx, dagId, &api.ListOptions{Limit: 50, OrderBy: "-start_date"})
-			if err != nil {
+			if track("runs", err) != nil {
 				log.Printf("[ERROR] GetDAGRuns: %v", err)
+				if api.IsUnreachable(err) {
+					serveCachedRuns(dagId)
+				}
 				return
 			}
 			log.Printf("[DATA] DAGRuns fetched: %d runs for %s", len(runs.DAGRuns), dagId)
@@ -365,29 +676,15 @@ func main() {
 			store.SetDAGRuns(dagId, runs.DAGRuns)
 		}()
 
-		// Fetch lineage
-		go func() {
-			ctx := context.Background()
-			tasks, err := client.GetTasks(ctx, dagId)
-			if err != nil {
-				return
-			}
-			store.SetTasks(dagId, tasks.Tasks)
-		}()
+		loadDAGDetails(dagId)
+	})
 
-		// Fetch DAG source code
-		go func() {
-			ctx := context.Background()
-			code, err := client.GetDAGSource(ctx, dagId)
-			if err != nil {
-				dispatcher.Post(func() { mainLayout.Code().SetError(err.Error()) })
-				return
-			}
-			// Highlight here, not in the posted closure: it is CPU-bound and
-			// would block the tview goroutine.
-			markup := views.HighlightPython(code)
-			dispatcher.Post(func() { mainLayout.Code().SetHighlighted(markup) })
-		}()
+	// SLA page Enter → select that DAG and show its runs.
+	mainLayout.SLA().SetOnSelected(func(dagId string) {
+		mainLayout.DagList().SelectDag(dagId)
+		mainLayout.SwitchTab("runs")
+		store.SetActiveTab("runs")
+		tviewApp.SetFocus(mainLayout.Runs())
 	})
 
 	// Run selected -> drill down to the tasks tab (run dashboard) and fetch task instances.
@@ -411,10 +708,13 @@ func main() {
 		tviewApp.SetFocus(mainLayout.ActiveTabPrimitive())
 
 		go func() {
-			ctx := context.Background()
+			ctx := userCtx
 			ti, err := client.GetTaskInstances(ctx, dagId, runId, &api.ListOptions{Limit: 100})
-			if err != nil {
+			if track("task_instances", err) != nil {
 				log.Printf("[ERROR] GetTaskInstances: %v", err)
+				if api.IsUnreachable(err) {
+					serveCachedTaskInstances(dagId, runId)
+				}
 				return
 			}
 			log.Printf("[DATA] TaskInstances fetched: %d tasks for %s/%s", len(ti.TaskInstances), dagId, runId)
@@ -423,15 +723,30 @@ func main() {
 			cp := views.ComputeCriticalPath(store.GetTasks(dagId), ti.TaskInstances, time.Now())
 			store.SetCriticalPath(cp)
 		}()
+		// Per-task duration baselines from cached history flag slow tasks;
+		// the same history places the Gantt's ghost bars.
+		go func() {
+			history, _ := bfCache.GetTaskInstancesHistory(dagId, time.Now().Add(-anomalyHistory), 5000)
+			baselines := metrics.TaskBaselines(history, runId)
+			timings := metrics.TaskTimings(history, runId)
+			dispatcher.Post(func() {
+				mainLayout.Execution().SetBaselines(runId, baselines)
+				mainLayout.Tasks().SetGanttTimings(runId, timings)
+			})
+		}()
 		if len(store.GetTasks(dagId)) == 0 {
 			go func() {
-				ctx := context.Background()
-				tasks, err := client.GetTasks(ctx, dagId)
+				ctx := userCtx
+				tasks, err := fetchTasks(ctx, dagId)
 				if err != nil {
 					log.Printf("[ERROR] Execution GetTasks: %v", err)
+					if cached, ok := bfCache.GetTasks(dagId); ok && api.IsUnreachable(err) {
+						store.SetTasks(dagId, cached)
+					}
 					return
 				}
-				store.SetTasks(dagId, tasks.Tasks)
+				bfCache.PutTasks(dagId, tasks)
+				store.SetTasks(dagId, tasks)
 			}()
 		}
 	})
@@ -450,15 +765,31 @@ func main() {
 			return
 		}
 
-		fetchLogs := func(ctx context.Context) {
-			logs, err := client.GetTaskLogs(ctx, dagId, runId, taskId, 1)
-			if err != nil {
+		// A mapped task's logs are per expansion: the one picked in the run's
+		// task list, else the first.
+		mapIndex := mainLayout.Tasks().SelectedMapIndex()
+		instances := store.GetTaskInstances(dagId, runId)
+		if mapIndex == models.NotMapped {
+			for _, ti := range instances {
+				if ti.TaskId == taskId && ti.Mapped() && (mapIndex == models.NotMapped || ti.MapIndex < mapIndex) {
+					mapIndex = ti.MapIndex
+				}
+			}
+		}
+
+		fetchLogs := func(ctx context.Context) error {
+			logs, err := client.GetTaskLogs(ctx, dagId, runId, taskId, mapIndex, 1)
+			if track("logs", err) != nil {
 				log.Printf("[ERROR] GetTaskLogs: %v", err)
+				msg := err.Error()
+				if api.IsUnreachable(err) {
+					msg = "Airflow is unreachable and task logs are not cached"
+				}
 				dispatcher.Post(func() {
-					mainLayout.Logs().SetError(err.Error())
-					mainLayout.Execution().SetLogError(err.Error())
+					mainLayout.Logs().SetError(msg)
+					mainLayout.Execution().SetLogError(msg)
 				})
-				return
+				return err
 			}
 			log.Printf("[DATA] TaskLogs fetched: %d chars", len(logs))
 			// Highlight off the tview goroutine; it is CPU-bound.
@@ -467,25 +798,63 @@ func main() {
 				mainLayout.Logs().SetHighlighted(markup)
 				mainLayout.Execution().SetHighlightedLogs(markup)
 			})
+			return nil
 		}
 		mainLayout.Logs().SetMessage("Loading logs...")
 		mainLayout.Execution().SetLogMessage("Loading logs...")
-		go fetchLogs(context.Background())
+		go fetchLogs(userCtx)
 
 		running := false
-		for _, ti := range store.GetTaskInstances(dagId, runId) {
-			if ti.TaskId == taskId && ti.State == "running" {
+		for _, ti := range instances {
+			if ti.TaskId == taskId && ti.MapIndex == mapIndex && ti.State == "running" {
 				running = true
 				break
 			}
 		}
 		if running {
-			poller.Restart("exec-logs", 5*time.Second, fetchLogs)
+			poller.Restart("exec-logs", 5*time.Second, fetchLogs, app.ForTabs("tasks", "logs"))
 		} else {
 			poller.StopSub("exec-logs")
 		}
 	})
 
+	// Enter on a Gantt row has selected the task above; show its logs.
+	mainLayout.Tasks().SetOnOpenLogs(func(string) {
+		mainLayout.SwitchTab("logs")
+		store.SetActiveTab("logs")
+		tviewApp.SetFocus(mainLayout.ActiveTabPrimitive())
+	})
+
+	// openTaskLogs selects a task of the selected run, or one expansion of a
+	// mapped task, the way the Tasks tab would and shows its logs.
+	openTaskLogs := func(taskId string, mapIndex int) {
+		mainLayout.Tasks().SelectInstance(taskId, mapIndex)
+		mainLayout.SwitchTab("logs")
+		store.SetActiveTab("logs")
+		tviewApp.SetFocus(mainLayout.ActiveTabPrimitive())
+	}
+
+	// Enter on a grid cell opens that task instance's logs, committing its run
+	// first the way the Runs tab would.
+	mainLayout.Grid().SetOnSelected(func(run models.DAGRun, ti models.TaskInstance) {
+		if len(store.GetTaskInstances(run.DagId, run.RunId)) == 0 {
+			// The run dashboard and log tailing read the store; start them off
+			// with what the grid already has.
+			store.SetTaskInstances(run.DagId, run.RunId, mainLayout.Grid().RunInstances(run.RunId))
+		}
+		mainLayout.Runs().SelectRun(run.RunId)
+		openTaskLogs(ti.TaskId, ti.MapIndex)
+	})
+
+	// Enter on a lineage graph node opens its logs in the selected run.
+	mainLayout.Lineage().SetOnOpenLogs(func(taskId string) {
+		if store.SelectedRun() == "" {
+			mainLayout.StatusBar().SetStatus("[yellow]Select a DAG run to open task logs[-]")
+			return
+		}
+		openTaskLogs(taskId, models.NotMapped)
+	})
+
 	// Backfills view selection callback
 	mainLayout.Backfills().SetOnSelected(func(id int) {
 		store.SelectBackfill(id)
@@ -494,33 +863,288 @@ func main() {
 	// ---------- Keybindings ----------
 
 	kb := ui.NewKeyBindings(tviewApp, mainLayout, store)
+	kb.SetOnActivity(poller.Touch) // an idle terminal polls slower
+	kb.SetReadOnly(cfg.Airflow.ReadOnly)
+
+	// Every state-changing request is recorded in the history cache with who
+	// sent it, to which cluster, the body and the outcome; H lists them.
+	// audit blocks on the cache write; call it off the tview goroutine.
+	actionUser := auditUser(cfg.Airflow.Auth)
+	refreshActions := func() {
+		recs, _ := bfCache.GetActions(maxActionRows)
+		dispatcher.Post(func() { mainLayout.Actions().Update(recs) })
+	}
+	audit := func(rec cache.ActionRecord, body any, err error) {
+		rec.At, rec.User, rec.Server = time.Now(), actionUser, cfg.Airflow.BaseURL
+		if body != nil {
+			if raw, merr := json.Marshal(body); merr == nil {
+				rec.Request = string(raw)
+			}
+		}
+		if err != nil {
+			rec.Error = err.Error()
+		}
+		if _, werr := bfCache.RecordAction(rec); werr != nil {
+			log.Printf("[ERROR] audit %s %s: %v", rec.Action, rec.DagId, werr)
+		}
+		refreshActions()
+	}
+
+	// triggerRun posts a run for dagId. It blocks; call it off the tview
+	// goroutine.
+	triggerRun := func(dagId string, params layout.TriggerParams) {
+		body := map[string]any{
+			"logical_date": params.LogicalDate,
+		}
+		if params.RunId != "" {
+			body["dag_run_id"] = params.RunId
+		}
+		if params.Note != "" {
+			body["note"] = params.Note
+		}
+		if params.Conf != "" && params.Conf != "{}" {
+			var conf map[string]any
+			if err := json.Unmarshal([]byte(params.Conf), &conf); err == nil {
+				body["conf"] = conf
+			}
+		}
+		_, err := client.TriggerDAGRun(userCtx, dagId, body)
+		if err == nil {
+			bfCache.PutTriggerConf(dagId, params.Conf)
+		}
+		audit(cache.ActionRecord{Action: cache.ActionTrigger, DagId: dagId, Target: params.RunId}, body, err)
+		dispatcher.Post(func() {
+			if err != nil {
+				mainLayout.StatusBar().SetError(fmt.Sprintf("Trigger failed: %v", err))
+			} else {
+				mainLayout.StatusBar().SetStatus(fmt.Sprintf("[green]DAG %s triggered[-]", dagId))
+			}
+		})
+	}
+	// openTrigger shows the trigger form for dagId, pre-filled from defaults and
+	// offering the DAG's recent confs. Confs that trigger successfully are
+	// remembered so they can be picked again.
+	openTrigger := func(dagId string, defaults layout.TriggerParams) {
+		if readOnly("Triggering") {
+			return
+		}
+		var history []string
+		if confs, ok := bfCache.GetTriggerConfs(dagId, 10); ok {
+			for _, c := range confs {
+				history = append(history, c.Conf)
+			}
+		}
+		mainLayout.ShowTriggerModal(dagId, defaults, history, func(params layout.TriggerParams) {
+			protect("Trigger "+dagId, dagId, func() { go triggerRun(dagId, params) })
+		})
+	}
+	retrigger := func(run models.DAGRun) {
+		openTrigger(run.DagId, layout.TriggerParams{Conf: views.PrettyConf(run.Conf)})
+	}
 
 	kb.SetOnTrigger(func(dagId string) {
-		mainLayout.ShowTriggerModal(dagId, func(params layout.TriggerParams) {
+		openTrigger(dagId, layout.TriggerParams{})
+	})
+
+	kb.SetOnViewConf(func(dagId, runId string) {
+		run, ok := findRun(store.GetDAGRuns(dagId), runId)
+		if !ok {
+			return
+		}
+		var onRetrigger func()
+		if !cfg.Airflow.ReadOnly {
+			onRetrigger = func() { retrigger(run) }
+		}
+		mainLayout.ShowConfModal(runId, run.Conf, onRetrigger)
+	})
+
+	kb.SetOnRetrigger(func(dagId, runId string) {
+		if run, ok := findRun(store.GetDAGRuns(dagId), runId); ok {
+			retrigger(run)
+		}
+	})
+
+	// Notes are PATCHed, then written into the store so the tables show the
+	// new text before the next poll confirms it.
+	saveRunNote := func(dagId, runId, note string) {
+		_, err := client.SetDAGRunNote(userCtx, dagId, runId, note)
+		audit(cache.ActionRecord{Action: cache.ActionRunNote, DagId: dagId, Target: runId}, map[string]any{"note": note}, err)
+		if err != nil {
+			dispatcher.Post(func() {
+				mainLayout.StatusBar().SetError(fmt.Sprintf("Note failed: %v", err))
+			})
+			return
+		}
+		runs := store.GetDAGRuns(dagId)
+		for i := range runs {
+			if runs[i].RunId == runId {
+				runs[i].Note = note
+			}
+		}
+		store.SetDAGRuns(dagId, runs)
+		dispatcher.Post(func() {
+			mainLayout.StatusBar().SetStatus(fmt.Sprintf("[green]Note saved on %s[-]", runId))
+		})
+	}
+	saveTaskNote := func(dagId, runId, taskId string, mapIndex int, note string) {
+		_, err := client.SetTaskInstanceNote(userCtx, dagId, runId, taskId, mapIndex, note)
+		target := runId + "/" + taskId
+		if mapIndex > models.NotMapped {
+			target += fmt.Sprintf("[%d]", mapIndex)
+		}
+		audit(cache.ActionRecord{Action: cache.ActionTaskNote, DagId: dagId, Target: target}, map[string]any{"note": note}, err)
+		if err != nil {
+			dispatcher.Post(func() {
+				mainLayout.StatusBar().SetError(fmt.Sprintf("Note failed: %v", err))
+			})
+			return
+		}
+		tis := store.GetTaskInstances(dagId, runId)
+		for i := range tis {
+			if tis[i].TaskId == taskId && (mapIndex == models.NotMapped || tis[i].MapIndex == mapIndex) {
+				tis[i].Note = note
+			}
+		}
+		store.SetTaskInstances(dagId, runId, tis)
+		dispatcher.Post(func() {
+			mainLayout.StatusBar().SetStatus(fmt.Sprintf("[green]Note saved on %s[-]", taskId))
+		})
+	}
+
+	kb.SetOnEditRunNote(func(dagId, runId string) {
+		if readOnly("Editing notes") {
+			return
+		}
+		run, ok := findRun(store.GetDAGRuns(dagId), runId)
+		if !ok {
+			return
+		}
+		mainLayout.ShowNoteModal(runId, run.Note, func(note string) {
+			protect("Edit the note on "+runId, dagId, func() { go saveRunNote(dagId, runId, note) })
+		})
+	})
+
+	kb.SetOnEditTaskNote(func(dagId, runId, taskId string, mapIndex int) {
+		if readOnly("Editing notes") {
+			return
+		}
+		var current string
+		for _, ti := range store.GetTaskInstances(dagId, runId) {
+			if ti.TaskId == taskId && (mapIndex == models.NotMapped || ti.MapIndex == mapIndex) {
+				current = ti.Note
+				break
+			}
+		}
+		mainLayout.ShowNoteModal(taskId, current, func(note string) {
+			protect("Edit the note on "+taskId, dagId, func() { go saveTaskNote(dagId, runId, taskId, mapIndex, note) })
+		})
+	})
+
+	// Compare fetches both runs fresh: the store only keeps instances of runs
+	// that were drilled into.
+	kb.SetOnGrid(openGrid)
+
+	kb.SetOnCompare(func(a, b models.DAGRun) {
+		mainLayout.Compare().SetLoading(a.RunId, b.RunId)
+		go func() {
+			ctx := userCtx
+			fetch := func(run models.DAGRun) ([]models.TaskInstance, error) {
+				ti, err := client.GetTaskInstances(ctx, run.DagId, run.RunId, &api.ListOptions{Limit: 100})
+				if track("task_instances", err) != nil {
+					if tis := cachedRunTasks(bfCache, run.DagId, run.RunId); len(tis) > 0 && api.IsUnreachable(err) {
+						return tis, nil
+					}
+					return nil, err
+				}
+				bfCache.PutTaskInstances(run.DagId, run.RunId, ti.TaskInstances)
+				return ti.TaskInstances, nil
+			}
+			tisA, errA := fetch(a)
+			tisB, errB := fetch(b)
+			dispatcher.Post(func() {
+				if err := errors.Join(errA, errB); err != nil {
+					mainLayout.Compare().SetError(err.Error())
+					return
+				}
+				mainLayout.Compare().Update(a, tisA, b, tisB)
+			})
+		}()
+	})
+
+	// Export reads only the local history cache, so it works offline and never
+	// touches the API.
+	kb.SetOnExport(func(dagId string) {
+		formats := make([]string, len(cache.Formats))
+		for i, f := range cache.Formats {
+			formats[i] = string(f)
+		}
+		defaults := layout.ExportParams{
+			DAGs:   dagId,
+			Since:  cfg.UI.RollupWindow,
+			Format: string(cache.FormatJSONL),
+			Path:   fmt.Sprintf("lazyflow-history-%s.jsonl", time.Now().Format("20060102-150405")),
+		}
+		mainLayout.ShowExportModal(defaults, formats, func(p layout.ExportParams) {
 			go func() {
-				ctx := context.Background()
-				body := map[string]any{
-					"logical_date": params.LogicalDate,
+				now := time.Now()
+				since, err := app.ParseTimeBound(p.Since, now)
+				var until time.Time
+				if err == nil {
+					until, err = app.ParseTimeBound(p.Until, now)
 				}
-				if params.Conf != "" && params.Conf != "{}" {
-					var conf map[string]any
-					if err := json.Unmarshal([]byte(params.Conf), &conf); err == nil {
-						body["conf"] = conf
-					}
+				var stats cache.TransferStats
+				if err == nil {
+					stats, err = cache.Export(bfCache, p.Path, cache.Format(p.Format), cache.ExportFilter{
+						DagIds: splitList(p.DAGs),
+						Since:  since,
+						Until:  until,
+					})
 				}
-				_, err := client.TriggerDAGRun(ctx, dagId, body)
 				dispatcher.Post(func() {
 					if err != nil {
-						mainLayout.StatusBar().SetError(fmt.Sprintf("Trigger failed: %v", err))
-					} else {
-						mainLayout.StatusBar().SetStatus(fmt.Sprintf("[green]DAG %s triggered[-]", dagId))
+						mainLayout.StatusBar().SetError(fmt.Sprintf("Export failed: %v", err))
+						return
 					}
+					mainLayout.StatusBar().SetStatus(fmt.Sprintf("[green]Exported %d runs, %d task instances to %s[-]",
+						stats.Runs, stats.Tasks, p.Path))
 				})
 			}()
 		})
 	})
 
+	// setPaused runs one of the reversible actions, pausing or unpausing a DAG
+	// or a backfill (target holds the backfill id), and records it; revertOf
+	// is the action it undoes, if any. It blocks; call it off the tview
+	// goroutine.
+	setPaused := func(action, dagId, target string, revertOf int64) error {
+		var err error
+		body := map[string]any{"is_paused": action == cache.ActionPauseDAG || action == cache.ActionPauseBackfill}
+		switch action {
+		case cache.ActionPauseDAG:
+			err = client.PauseDAG(userCtx, dagId)
+		case cache.ActionUnpauseDAG:
+			err = client.UnpauseDAG(userCtx, dagId)
+		case cache.ActionPauseBackfill, cache.ActionUnpauseBackfill:
+			id, perr := strconv.Atoi(target)
+			switch {
+			case perr != nil:
+				return fmt.Errorf("backfill id %q: %w", target, perr)
+			case action == cache.ActionPauseBackfill:
+				err = client.PauseBackfill(userCtx, id)
+			default:
+				err = client.UnpauseBackfill(userCtx, id)
+			}
+		default:
+			return fmt.Errorf("%s cannot be undone", action)
+		}
+		audit(cache.ActionRecord{Action: action, DagId: dagId, Target: target, RevertOf: revertOf}, body, err)
+		return err
+	}
+
 	kb.SetOnPause(func(dagId string) {
+		if readOnly("Pausing") {
+			return
+		}
 		var dag models.DAG
 		for _, d := range store.GetDAGs() {
 			if d.DagId == dagId {
@@ -528,22 +1152,17 @@ func main() {
 				break
 			}
 		}
-		action := "Pause"
+		action, kind := "Pause", cache.ActionPauseDAG
 		if dag.IsPaused {
-			action = "Unpause"
+			action, kind = "Unpause", cache.ActionUnpauseDAG
 		}
-		mainLayout.ShowConfirmModal(
+		confirm(
 			fmt.Sprintf(" %s DAG ", action),
 			fmt.Sprintf("%s DAG [yellow]%s[-]?", action, dagId),
+			dagId,
 			func() {
 				go func() {
-					ctx := context.Background()
-					var err error
-					if dag.IsPaused {
-						err = client.UnpauseDAG(ctx, dagId)
-					} else {
-						err = client.PauseDAG(ctx, dagId)
-					}
+					err := setPaused(kind, dagId, "", 0)
 					dispatcher.Post(func() {
 						if err != nil {
 							mainLayout.StatusBar().SetError(fmt.Sprintf("%s failed: %v", action, err))
@@ -556,74 +1175,147 @@ func main() {
 		)
 	})
 
+	createBackfill := func(dagId string, params layout.BackfillParams) {
+		body := map[string]any{
+			"dag_id":    dagId,
+			"from_date": params.FromDate,
+			"to_date":   params.ToDate,
+		}
+		if params.MaxActiveRuns != "" {
+			var n int
+			if _, err := fmt.Sscanf(params.MaxActiveRuns, "%d", &n); err == nil && n > 0 {
+				body["max_active_runs"] = n
+			}
+		}
+		if params.DagRunConf != "" && params.DagRunConf != "{}" {
+			var conf map[string]any
+			if err := json.Unmarshal([]byte(params.DagRunConf), &conf); err == nil {
+				body["dag_run_conf"] = conf
+			}
+		}
+		bf, err := client.CreateBackfill(userCtx, body)
+		rec := cache.ActionRecord{Action: cache.ActionCreateBackfill, DagId: dagId}
+		if err == nil {
+			rec.Target = strconv.Itoa(bf.ID)
+		}
+		audit(rec, body, err)
+		dispatcher.Post(func() {
+			if err != nil {
+				mainLayout.StatusBar().SetError(fmt.Sprintf("Backfill failed: %v", err))
+			} else {
+				mainLayout.StatusBar().SetStatus(fmt.Sprintf("[green]Backfill created for %s[-]", dagId))
+			}
+		})
+	}
+
 	kb.SetOnBackfill(func(dagId string) {
+		if readOnly("Backfilling") {
+			return
+		}
 		mainLayout.ShowBackfillModal(dagId, func(params layout.BackfillParams) {
-			go func() {
-				ctx := context.Background()
-				body := map[string]any{
-					"dag_id":    dagId,
-					"from_date": params.FromDate,
-					"to_date":   params.ToDate,
-				}
-				if params.MaxActiveRuns != "" {
-					var n int
-					if _, err := fmt.Sscanf(params.MaxActiveRuns, "%d", &n); err == nil && n > 0 {
-						body["max_active_runs"] = n
-					}
-				}
-				if params.DagRunConf != "" && params.DagRunConf != "{}" {
-					var conf map[string]any
-					if err := json.Unmarshal([]byte(params.DagRunConf), &conf); err == nil {
-						body["dag_run_conf"] = conf
-					}
-				}
-				_, err := client.CreateBackfill(ctx, body)
-				dispatcher.Post(func() {
-					if err != nil {
-						mainLayout.StatusBar().SetError(fmt.Sprintf("Backfill failed: %v", err))
-					} else {
-						mainLayout.StatusBar().SetStatus(fmt.Sprintf("[green]Backfill created for %s[-]", dagId))
-					}
-				})
-			}()
+			protect("Backfill "+dagId, dagId, func() { go createBackfill(dagId, params) })
 		})
 	})
 
 	kb.SetOnBackfillCancel(func(id int) {
-		mainLayout.ShowBackfillCancelModal(id, func() {
+		if readOnly("Cancelling a backfill") {
+			return
+		}
+		dagId := store.SelectedDAG()
+		cancel := func() {
 			go func() {
-				if err := client.CancelBackfill(context.Background(), id); err != nil {
+				err := client.CancelBackfill(userCtx, id)
+				audit(cache.ActionRecord{Action: cache.ActionCancelBackfill, DagId: dagId, Target: strconv.Itoa(id)}, nil, err)
+				if err != nil {
 					dispatcher.Post(func() {
 						mainLayout.StatusBar().SetError("cancel: " + err.Error())
 					})
 					return
 				}
 				// Optimistic refresh.
-				if col, err := client.ListBackfills(context.Background(), store.SelectedDAG(), nil); err == nil {
-					store.SetBackfills(store.SelectedDAG(), col.Backfills)
+				if col, err := client.ListBackfills(userCtx, dagId, nil); err == nil {
+					store.SetBackfills(dagId, col.Backfills)
 				}
 			}()
-		})
+		}
+		if cfg.Airflow.Protected {
+			protect(fmt.Sprintf("Cancel backfill #%d", id), dagId, cancel)
+			return
+		}
+		mainLayout.ShowBackfillCancelModal(id, cancel)
 	})
 
 	kb.SetOnBackfillPause(func(id int) {
-		go func() {
-			if err := client.PauseBackfill(context.Background(), id); err != nil {
-				dispatcher.Post(func() {
-					mainLayout.StatusBar().SetError("pause: " + err.Error())
-				})
-			}
-		}()
+		if readOnly("Pausing a backfill") {
+			return
+		}
+		dagId := store.SelectedDAG()
+		protect(fmt.Sprintf("Pause backfill #%d", id), dagId, func() {
+			go func() {
+				if err := setPaused(cache.ActionPauseBackfill, dagId, strconv.Itoa(id), 0); err != nil {
+					dispatcher.Post(func() {
+						mainLayout.StatusBar().SetError("pause: " + err.Error())
+					})
+				}
+			}()
+		})
 	})
 
 	kb.SetOnBackfillUnpause(func(id int) {
-		go func() {
-			if err := client.UnpauseBackfill(context.Background(), id); err != nil {
+		if readOnly("Unpausing a backfill") {
+			return
+		}
+		dagId := store.SelectedDAG()
+		protect(fmt.Sprintf("Unpause backfill #%d", id), dagId, func() {
+			go func() {
+				if err := setPaused(cache.ActionUnpauseBackfill, dagId, strconv.Itoa(id), 0); err != nil {
+					dispatcher.Post(func() {
+						mainLayout.StatusBar().SetError("unpause: " + err.Error())
+					})
+				}
+			}()
+		})
+	})
+
+	kb.SetOnShowActions(func() { go refreshActions() })
+
+	// Undo replays the inverse of a recorded pause or unpause. Only actions
+	// sent to the cluster lazyflow is connected to can be undone.
+	kb.SetOnRevert(func(a cache.ActionRecord) {
+		if readOnly("Undo") {
+			return
+		}
+		inverse, ok := a.Inverse()
+		switch {
+		case a.Error != "":
+			mainLayout.StatusBar().SetError(fmt.Sprintf("%s failed; there is nothing to undo", a.Action))
+			return
+		case !ok:
+			mainLayout.StatusBar().SetError(fmt.Sprintf("%s cannot be undone", a.Action))
+			return
+		case mainLayout.Actions().Reverted(a.ID):
+			mainLayout.StatusBar().SetError(fmt.Sprintf("#%d was already undone", a.ID))
+			return
+		case a.Server != cfg.Airflow.BaseURL:
+			mainLayout.StatusBar().SetError(fmt.Sprintf("#%d was sent to %s, not this cluster", a.ID, a.Server))
+			return
+		}
+		what := "DAG [yellow]" + a.DagId + "[-]"
+		if a.Target != "" {
+			what = fmt.Sprintf("backfill [yellow]#%s[-] of %s", a.Target, a.DagId)
+		}
+		confirm(" Undo ", fmt.Sprintf("Undo %s: %s %s?", a.Action, inverse, what), a.DagId, func() {
+			go func() {
+				err := setPaused(inverse, a.DagId, a.Target, a.ID)
 				dispatcher.Post(func() {
-					mainLayout.StatusBar().SetError("unpause: " + err.Error())
+					if err != nil {
+						mainLayout.StatusBar().SetError(fmt.Sprintf("Undo failed: %v", err))
+					} else {
+						mainLayout.StatusBar().SetStatus(fmt.Sprintf("[green]Undone: %s %s[-]", inverse, a.DagId))
+					}
 				})
-			}
-		}()
+			}()
+		})
 	})
 
 	kb.SetOnMonitorWindow(func(delta int) {
@@ -643,24 +1335,32 @@ func main() {
 	tasksInterval := app.ParseDuration(cfg.UI.RefreshIntervals.Tasks, 2*time.Second)
 
 	// Fixed: DAGs
-	poller.Fixed(dagInterval, true, func(ctx context.Context) {
+	poller.Fixed(dagInterval, true, func(ctx context.Context) error {
 		dags, err := client.GetDAGs(ctx, &api.ListOptions{Limit: 100})
-		if err != nil {
-			return
+		if track("dags", err) != nil {
+			if cached, ok := bfCache.GetDAGs(); ok && len(store.GetDAGs()) == 0 && api.IsUnreachable(err) {
+				store.SetDAGs(cached)
+			}
+			return err
 		}
+		bfCache.PutDAGs(dags.DAGs)
 		store.SetDAGs(dags.DAGs)
+		return nil
 	})
 
 	// Fixed: cluster DAG-state rollup (all DAGs, latest run state within window).
-	poller.Fixed(dagInterval, true, func(ctx context.Context) {
+	poller.Fixed(dagInterval, true, func(ctx context.Context) error {
 		since := time.Now().Add(-rollupWindow)
 		col, err := client.GetAllDAGRuns(ctx, &api.ListOptions{
 			Limit:          1000,
 			OrderBy:        "-run_after",
 			LogicalDateGte: since,
 		})
-		if err != nil {
-			return
+		if track("rollup", err) != nil {
+			if store.Offline() {
+				serveCachedRollup()
+			}
+			return err
 		}
 		if col.TotalEntries > len(col.DAGRuns) {
 			log.Printf("[ERROR] dag-state rollup truncated: total=%d fetched=%d window=%s",
@@ -668,51 +1368,59 @@ func main() {
 		}
 		cacheDAGRunsByDAG(bfCache, col.DAGRuns)
 		store.SetDAGStateRollup(metrics.RollupLatestState(col.DAGRuns))
+		if len(slas) > 0 {
+			store.SetSLABreaches(evaluateSLAs(slas, bfCache, store, col.DAGRuns, time.Now()))
+		}
+		return nil
 	})
 
 	// Fixed: Health
-	poller.Fixed(healthInterval, true, func(ctx context.Context) {
+	poller.Fixed(healthInterval, true, func(ctx context.Context) error {
 		h, err := client.GetHealth(ctx)
-		if err != nil {
-			return
+		if track("health", err) != nil {
+			return err
 		}
 		store.SetHealth(h)
+		return nil
 	})
 
 	// Fixed: Pools
-	poller.Fixed(poolsInterval, true, func(ctx context.Context) {
+	poller.Fixed(poolsInterval, true, func(ctx context.Context) error {
 		pools, err := client.ListPools(ctx, &api.ListOptions{Limit: 100})
-		if err != nil {
-			return
+		if track("pools", err) != nil {
+			return err
 		}
 		store.SetPools(pools.Pools)
+		return nil
 	})
 
 	// Dynamic: Runs (restart on DAG selection)
 	store.Subscribe(state.EventDAGSelected, func(_ any) {
 		dagId := store.SelectedDAG()
-		poller.Restart("runs", runsInterval, func(ctx context.Context) {
+		poller.Restart("runs", runsInterval, func(ctx context.Context) error {
 			runs, err := client.GetDAGRuns(ctx, dagId, &api.ListOptions{Limit: 50, OrderBy: "-start_date"})
-			if err != nil {
-				return
+			if track("runs", err) != nil {
+				return err
 			}
 			bfCache.PutDAGRuns(dagId, runs.DAGRuns)
 			store.SetDAGRuns(dagId, runs.DAGRuns)
-		})
+			return nil
+		}, app.ForTabs("runs", "compare", "grid"), app.BoostWhen(func() bool { return dagHasActiveRun(store, dagId) }))
 	})
 
 	// Dynamic: TaskInstances (restart on Run selection)
 	store.Subscribe(state.EventRunSelected, func(_ any) {
 		runId := store.SelectedRun()
 		dagId := store.SelectedDAG()
-		poller.Restart("tasks", tasksInterval, func(ctx context.Context) {
+		poller.Restart("tasks", tasksInterval, func(ctx context.Context) error {
 			ti, err := client.GetTaskInstances(ctx, dagId, runId, &api.ListOptions{Limit: 100})
-			if err != nil {
-				return
+			if track("task_instances", err) != nil {
+				return err
 			}
 			bfCache.PutTaskInstances(dagId, runId, ti.TaskInstances)
 			store.SetTaskInstances(dagId, runId, ti.TaskInstances)
-		})
+			return nil
+		}, app.ForTabs("tasks", "logs"), app.BoostWhen(func() bool { return runIsActive(store, dagId, runId) }))
 	})
 
 	// Backfills poller — runs only when the backfills tab is active AND a DAG is selected.
@@ -728,11 +1436,11 @@ func main() {
 		}
 
 		// 2) One-shot fresh fetch so first-entry isn't blank for 5s.
-		fetch := func(ctx context.Context) {
+		fetch := func(ctx context.Context) error {
 			col, err := client.ListBackfills(ctx, dagId, nil)
-			if err != nil {
+			if track("backfills", err) != nil {
 				debugutil.Tag("FZ-bf", "ListBackfills err=%v", err)
-				return
+				return err
 			}
 			runs, _ := client.GetDAGRuns(ctx, dagId, nil)
 			if runs != nil {
@@ -742,8 +1450,9 @@ func main() {
 			}
 			bfCache.PutBackfills(dagId, col.Backfills)
 			store.SetBackfills(dagId, col.Backfills)
+			return nil
 		}
-		go fetch(context.Background())
+		go fetch(userCtx)
 
 		// 3) Periodic refresh.
 		poller.Restart("backfills", backfillsInterval, fetch)
@@ -762,28 +1471,83 @@ func main() {
 		}
 	})
 
-	// One-shot fetches for Connections, Variables, Config (loaded once at startup)
-	go func() {
+	// One-shot fetches for Connections, Variables, Config (loaded at startup
+	// and again on reconnect; they are not cached).
+	loadGlobals := func() {
 		ctx := context.Background()
 
 		conns, err := client.GetConnections(ctx, &api.ListOptions{Limit: 100})
-		if err == nil {
+		if track("connections", err) == nil {
 			dispatcher.Post(func() { mainLayout.Connections().Update(conns.Connections) })
 		}
 
 		vars, err := client.GetVariables(ctx, &api.ListOptions{Limit: 100})
-		if err == nil {
+		if track("variables", err) == nil {
 			dispatcher.Post(func() { mainLayout.Variables().Update(vars.Variables) })
 		}
 
 		afCfg, err := client.GetConfig(ctx)
-		if err == nil {
+		if track("config", err) == nil {
 			dispatcher.Post(func() { mainLayout.Config().Update(afCfg) })
 		}
-	}()
+	}
+	go loadGlobals()
+
+	// API health → header, status bar and the errors page. The ticker keeps
+	// the "updated 5s ago" text honest between polls.
+	renderHealth := func() {
+		h := store.ConnectionHealth()
+		endpoints, apiErrs := store.GetEndpointHealth(), store.GetAPIErrors()
+		dispatcher.Post(func() {
+			mainLayout.Header().SetHealth(h.State, h.LastSuccess)
+			mainLayout.StatusBar().SetHealth(h.State, h.LastSuccess, h.Failing)
+			mainLayout.APIErrors().Update(endpoints, apiErrs)
+		})
+	}
+	store.Subscribe(state.EventAPIHealthChanged, func(_ any) { renderHealth() })
+	poller.Fixed(5*time.Second, false, func(context.Context) error {
+		renderHealth()
+		return nil
+	})
 
-	// Show connection status in header
+	// Connectivity flips: banner + status, then either fill the visible views
+	// from the cache or reload what offline mode could not serve.
+	store.Subscribe(state.EventConnectivityChanged, func(_ any) {
+		offline := store.Offline()
+		since, lastErr := conn.Status()
+		dispatcher.Post(func() {
+			mainLayout.Header().SetOffline(offline, since)
+			if offline {
+				mainLayout.StatusBar().SetError(fmt.Sprintf("Airflow unreachable (%v), showing cached data; actions are disabled", lastErr))
+			} else {
+				mainLayout.StatusBar().SetStatus("[green]Airflow reachable again, live data resumed[-]")
+			}
+		})
+		dagId, runId := store.SelectedDAG(), store.SelectedRun()
+		if offline {
+			if cached, ok := bfCache.GetDAGs(); ok && len(store.GetDAGs()) == 0 {
+				store.SetDAGs(cached)
+			}
+			serveCachedRollup()
+			if dagId != "" && len(store.GetDAGRuns(dagId)) == 0 {
+				serveCachedRuns(dagId)
+			}
+			if runId != "" && len(store.GetTaskInstances(dagId, runId)) == 0 {
+				serveCachedTaskInstances(dagId, runId)
+			}
+		} else {
+			go loadGlobals()
+			if dagId != "" {
+				loadDAGDetails(dagId)
+			}
+		}
+		refreshMonitor()
+		renderHealth()
+	})
+
+	// Nothing has answered yet: the header says "connecting" until a poll does.
 	mainLayout.Header().SetConnection(cfg.Airflow.BaseURL, true)
+	mainLayout.Header().SetHealth(state.ConnConnecting, time.Time{})
 
 	if err := tviewApp.SetRoot(mainLayout.Root(), true).EnableMouse(true).Run(); err != nil {
 		log.Fatalf("error running application: %v", err)
@@ -869,6 +1633,81 @@ func selectedRun(store *state.Store) models.DAGRun {
 	return models.DAGRun{DagId: dagId, RunId: runId}
 }
 
+// runIsActive reports whether the run is still queued or running, which
+// makes it worth polling faster.
+func runIsActive(store *state.Store, dagId, runId string) bool {
+	for _, r := range store.GetDAGRuns(dagId) {
+		if r.RunId == runId {
+			return r.State == "running" || r.State == "queued"
+		}
+	}
+	return false
+}
+
+// dagHasActiveRun reports whether any polled run of dagId is still going.
+func dagHasActiveRun(store *state.Store, dagId string) bool {
+	for _, r := range store.GetDAGRuns(dagId) {
+		if r.State == "running" || r.State == "queued" {
+			return true
+		}
+	}
+	return false
+}
+
+// taskInstancesSettled reports whether every instance reached a final state,
+// so a finished run's cached instances can be shown without refetching.
+func taskInstancesSettled(tis []models.TaskInstance) bool {
+	if len(tis) == 0 {
+		return false
+	}
+	for _, ti := range tis {
+		switch ti.State {
+		case "success", "failed", "skipped", "upstream_failed", "removed":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// cachedRunTasks returns one run's task instances from the history cache.
+func cachedRunTasks(c cache.Cache, dagId, runId string) []models.TaskInstance {
+	history, _ := c.GetTaskInstancesHistory(dagId, time.Time{}, 0)
+	var out []models.TaskInstance
+	for _, ti := range history {
+		if ti.RunId == runId {
+			out = append(out, ti)
+		}
+	}
+	return out
+}
+
+// findRun looks up runId among runs.
+func findRun(runs []models.DAGRun, runId string) (models.DAGRun, bool) {
+	for _, r := range runs {
+		if r.RunId == runId {
+			return r, true
+		}
+	}
+	return models.DAGRun{}, false
+}
+
+// auditUser names who sent an action: the local login, plus the Airflow user
+// when basic auth logs in as someone else.
+func auditUser(auth app.AuthConfig) string {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if name == "" {
+		name = "unknown"
+	}
+	if auth.Username != "" && auth.Username != name {
+		return fmt.Sprintf("%s (%s)", name, auth.Username)
+	}
+	return name
+}
+
 func newHistoryCache(cfg app.Config) cache.Cache {
 	if !cfg.Cache.Enabled {
 		return cache.NewMemory(30 * time.Second)
@@ -888,6 +1727,79 @@ func newHistoryCache(cfg app.Config) cache.Cache {
 	return cache.NewMemory(30 * time.Second)
 }
 
+func newClient(cfg app.Config) *api.Client {
+	auth, err := authProvider(cfg.Airflow.Auth)
+	if err != nil {
+		log.Fatalf("auth config: %v", err)
+	}
+	tls := cfg.Airflow.TLS
+	transport, err := api.NewTransport(api.TransportConfig{
+		CAFile:             app.ExpandHome(tls.CAFile),
+		CertFile:           app.ExpandHome(tls.CertFile),
+		KeyFile:            app.ExpandHome(tls.KeyFile),
+		InsecureSkipVerify: tls.InsecureSkipVerify,
+		ServerName:         tls.ServerName,
+		Proxy:              cfg.Airflow.Proxy,
+		Headers:            cfg.Airflow.Headers,
+	})
+	if err != nil {
+		log.Fatalf("airflow connection config: %v", err)
+	}
+	return api.NewClient(api.ClientConfig{
+		BaseURL:    cfg.Airflow.BaseURL,
+		PathPrefix: cfg.Airflow.PathPrefix,
+		Username:   cfg.Airflow.Auth.Username,
+		Password:   cfg.Airflow.Auth.Password,
+		Token:      cfg.Airflow.Auth.Token,
+		AuthType:   cfg.Airflow.Auth.Type,
+		Auth:       auth,
+		Transport:  transport,
+		Timeout:    app.ParseDuration(cfg.Airflow.Timeout, 30*time.Second),
+		Breaker:    newBreaker(cfg.Airflow.CircuitBreaker),
+		ReadOnly:   cfg.Airflow.ReadOnly,
+		Limits: api.Limits{
+			RequestsPerSecond: cfg.Airflow.RateLimit.RequestsPerSecond,
+			Burst:             cfg.Airflow.RateLimit.Burst,
+			MaxInFlight:       cfg.Airflow.RateLimit.MaxInFlight,
+		},
+	})
+}
+
+// authProvider returns the token source for the "exec" and "file" auth
+// types. For "basic" and "token" it returns nil and the client uses the
+// username/password or token fields directly.
+func authProvider(cfg app.AuthConfig) (api.AuthProvider, error) {
+	switch cfg.Type {
+	case "", "basic", "token":
+		return nil, nil
+	case "exec":
+		if cfg.Exec.Command == "" {
+			return nil, fmt.Errorf("auth type exec needs exec.command")
+		}
+		return &api.ExecAuth{
+			Command: cfg.Exec.Command,
+			Args:    cfg.Exec.Args,
+			Env:     cfg.Exec.Env,
+			Timeout: app.ParseDuration(cfg.Exec.Timeout, time.Minute),
+		}, nil
+	case "file":
+		if cfg.TokenFile == "" {
+			return nil, fmt.Errorf("auth type file needs token_file")
+		}
+		return api.TokenFile{Path: app.ExpandHome(cfg.TokenFile)}, nil
+	}
+	return nil, fmt.Errorf("unknown auth type %q (want basic, token, exec or file)", cfg.Type)
+}
+
+// newBreaker returns nil, which disables circuit breaking, for a zero
+// threshold.
+func newBreaker(cfg app.CircuitBreakerConfig) *api.Breaker {
+	if cfg.Threshold <= 0 {
+		return nil
+	}
+	return api.NewBreaker(cfg.Threshold, app.ParseDuration(cfg.Cooldown, 30*time.Second))
+}
+
 func cacheDAGRunsByDAG(c cache.Cache, runs []models.DAGRun) {
 	byDAG := make(map[string][]models.DAGRun)
 	for _, run := range runs {
@@ -901,10 +1813,36 @@ func cacheDAGRunsByDAG(c cache.Cache, runs []models.DAGRun) {
 	}
 }
 
-func stateByTask(tis []models.TaskInstance) map[string]string {
-	states := make(map[string]string, len(tis))
-	for _, ti := range tis {
-		states[ti.TaskId] = ti.State
+// evaluateSLAs checks every rule against the DAG's cached history merged with
+// the runs just polled and those in the store (fresher; cache writes are async).
+func evaluateSLAs(slas []metrics.SLA, c cache.Cache, store *state.Store, polled []models.DAGRun, now time.Time) []metrics.SLABreach {
+	byDAG := make(map[string][]models.DAGRun)
+	for _, run := range polled {
+		byDAG[run.DagId] = append(byDAG[run.DagId], run)
+	}
+	var breaches []metrics.SLABreach
+	for _, s := range slas {
+		history, _ := c.GetDAGRunsHistory(s.DagId, now.Add(-s.Lookback()), 500)
+		runs := mergeRuns(history, byDAG[s.DagId], store.GetDAGRuns(s.DagId))
+		breaches = append(breaches, metrics.EvaluateSLA(s, runs, now)...)
+	}
+	metrics.SortBreaches(breaches)
+	return breaches
+}
+
+// mergeRuns unions run slices by run id; later slices win.
+func mergeRuns(sets ...[]models.DAGRun) []models.DAGRun {
+	idx := make(map[string]int)
+	var out []models.DAGRun
+	for _, set := range sets {
+		for _, r := range set {
+			if i, ok := idx[r.RunId]; ok {
+				out[i] = r
+				continue
+			}
+			idx[r.RunId] = len(out)
+			out = append(out, r)
+		}
 	}
-	return states
+	return out
 }
@@ -4,6 +4,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/yjinheon/lazyflow/internal/api"
+	"github.com/yjinheon/lazyflow/internal/app"
+	"github.com/yjinheon/lazyflow/internal/cache"
 	"github.com/yjinheon/lazyflow/pkg/airflow/models"
 )
 
@@ -45,3 +48,83 @@ func TestWithLastRunState(t *testing.T) {
 			got[0].LastRunState, got[1].LastRunState, got[2].LastRunState)
 	}
 }
+
+func TestMergeRuns(t *testing.T) {
+	cached := []models.DAGRun{{RunId: "r1", State: "running"}, {RunId: "r0", State: "success"}}
+	polled := []models.DAGRun{{RunId: "r1", State: "success"}, {RunId: "r2", State: "queued"}}
+	got := mergeRuns(cached, polled)
+	if len(got) != 3 {
+		t.Fatalf("mergeRuns len = %d, want 3", len(got))
+	}
+	if got[0].RunId != "r1" || got[0].State != "success" {
+		t.Fatalf("mergeRuns r1 = %+v, want the later (polled) state", got[0])
+	}
+}
+
+func TestCachedRunTasks(t *testing.T) {
+	c := cache.NewMemory(time.Minute)
+	start := time.Now().Add(-time.Hour)
+	c.PutTaskInstances("etl", "r1", []models.TaskInstance{
+		{DagId: "etl", RunId: "r1", TaskId: "extract", StartDate: &start},
+		{DagId: "etl", RunId: "r1", TaskId: "load", StartDate: &start},
+	})
+	c.PutTaskInstances("etl", "r2", []models.TaskInstance{
+		{DagId: "etl", RunId: "r2", TaskId: "extract", StartDate: &start},
+	})
+
+	got := cachedRunTasks(c, "etl", "r1")
+	if len(got) != 2 {
+		t.Fatalf("cachedRunTasks(r1) = %+v, want 2 instances", got)
+	}
+	for _, ti := range got {
+		if ti.RunId != "r1" {
+			t.Fatalf("instance from another run: %+v", ti)
+		}
+	}
+	if got := cachedRunTasks(c, "etl", "missing"); len(got) != 0 {
+		t.Fatalf("unknown run returned %+v", got)
+	}
+}
+
+func TestAuthProvider(t *testing.T) {
+	for _, typ := range []string{"", "basic", "token"} {
+		if p, err := authProvider(app.AuthConfig{Type: typ}); p != nil || err != nil {
+			t.Errorf("type %q: got %v, %v; want the client's built-in auth", typ, p, err)
+		}
+	}
+	if p, err := authProvider(app.AuthConfig{Type: "exec", Exec: app.ExecConfig{Command: "gcloud"}}); err != nil {
+		t.Errorf("exec: %v", err)
+	} else if _, ok := p.(*api.ExecAuth); !ok {
+		t.Errorf("exec: got %T", p)
+	}
+	if _, ok := mustAuth(t, app.AuthConfig{Type: "file", TokenFile: "/run/token"}).(api.TokenFile); !ok {
+		t.Error("file: want a TokenFile")
+	}
+	for _, bad := range []app.AuthConfig{{Type: "exec"}, {Type: "file"}, {Type: "oauth"}} {
+		if _, err := authProvider(bad); err == nil {
+			t.Errorf("%+v: want a config error", bad)
+		}
+	}
+}
+
+func mustAuth(t *testing.T, cfg app.AuthConfig) api.AuthProvider {
+	t.Helper()
+	p, err := authProvider(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestAuditUser(t *testing.T) {
+	local := auditUser(app.AuthConfig{})
+	if local == "" {
+		t.Fatal("want the local login")
+	}
+	if got := auditUser(app.AuthConfig{Username: local}); got != local {
+		t.Errorf("same Airflow user: got %q, want %q", got, local)
+	}
+	if got, want := auditUser(app.AuthConfig{Username: "svc-airflow"}), local+" (svc-airflow)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
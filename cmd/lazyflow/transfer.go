@@ -0,0 +1,143 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yjinheon/lazyflow/internal/app"
+	"github.com/yjinheon/lazyflow/internal/cache"
+)
+
+// runExport is `lazyflow export`: it dumps cached run and task history to a
+// file without contacting Airflow.
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	out := fs.String("out", "", "file to write (a directory for csv) (required)")
+	format := fs.String("format", "", "csv, jsonl or sqlite (default: from the --out extension)")
+	dags := fs.String("dag", "", "comma-separated DAG ids (default: all cached DAGs)")
+	since := fs.String("since", "", "start of the range: 168h, 7d, 2006-01-02 or RFC 3339 (default: ui.rollup_window)")
+	until := fs.String("until", "", "end of the range, same forms as --since (default: now)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *out == "" {
+		fmt.Fprintln(os.Stderr, "lazyflow export: --out is required")
+		fs.Usage()
+		return 2
+	}
+	f, err := transferFormat(*format, *out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lazyflow export: %v\n", err)
+		return 2
+	}
+
+	cfg, c, ok := openTransferCache("export")
+	if !ok {
+		return 1
+	}
+	defer c.Close()
+
+	now := time.Now()
+	if *since == "" {
+		*since = cfg.UI.RollupWindow
+	}
+	filter := cache.ExportFilter{DagIds: splitList(*dags)}
+	if filter.Since, err = app.ParseTimeBound(*since, now); err == nil {
+		filter.Until, err = app.ParseTimeBound(*until, now)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lazyflow export: %v\n", err)
+		return 2
+	}
+
+	stats, err := cache.Export(c, *out, f, filter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lazyflow export: %v\n", err)
+		return 1
+	}
+	fmt.Printf("exported %d runs and %d task instances from %d DAGs to %s\n", stats.Runs, stats.Tasks, stats.DAGs, *out)
+	return 0
+}
+
+// runImport is `lazyflow import`: it merges an export (typically a
+// teammate's) into the local cache.
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "", "csv, jsonl or sqlite (default: from the file extension)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: lazyflow import [--format csv|jsonl|sqlite] PATH")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	path := fs.Arg(0)
+	f, err := transferFormat(*format, path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lazyflow import: %v\n", err)
+		return 2
+	}
+
+	_, c, ok := openTransferCache("import")
+	if !ok {
+		return 1
+	}
+	defer c.Close()
+
+	stats, err := cache.Import(c, path, f)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lazyflow import: %v\n", err)
+		return 1
+	}
+	fmt.Printf("imported %d runs and %d task instances from %d DAGs\n", stats.Runs, stats.Tasks, stats.DAGs)
+	return 0
+}
+
+// openTransferCache opens the configured SQLite cache. Unlike the TUI it
+// never falls back to memory: exporting or importing an empty in-process
+// cache would silently do nothing.
+func openTransferCache(cmd string) (app.Config, cache.Cache, bool) {
+	cfg, err := app.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lazyflow %s: load config: %v\n", cmd, err)
+		return cfg, nil, false
+	}
+	if !cfg.Cache.Enabled {
+		fmt.Fprintf(os.Stderr, "lazyflow %s: the history cache is disabled (cache.enabled: false)\n", cmd)
+		return cfg, nil, false
+	}
+	c, err := cache.NewSQLite(cfg.Cache.Path, cache.Options{
+		Retention:   app.ParseDuration(cfg.Cache.Retention, 30*24*time.Hour),
+		WriteBuffer: cfg.Cache.WriteBuffer,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lazyflow %s: open cache: %v\n", cmd, err)
+		return cfg, nil, false
+	}
+	return cfg, c, true
+}
+
+func transferFormat(flagValue, path string) (cache.Format, error) {
+	if flagValue != "" {
+		return cache.ParseFormat(flagValue)
+	}
+	return cache.FormatForPath(path)
+}
+
+// splitList parses a comma-separated flag, dropping blanks.
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Token is a bearer token and the moment it stops being valid. A zero
+// Expiry means the provider does not know; the client then reads the JWT
+// exp claim, or assumes Airflow's default lifetime.
+type Token struct {
+	Value  string
+	Expiry time.Time
+}
+
+// AuthProvider obtains bearer tokens for a Client. The client caches the
+// token it returns until shortly before Expiry, and asks again early when
+// the API rejects it with a 401.
+type AuthProvider interface {
+	Token(ctx context.Context) (Token, error)
+}
+
+// defaultTokenLifetime is what an Airflow 3 token without an exp claim is
+// assumed to last: the 24h default, less an hour to be safe.
+const defaultTokenLifetime = 23 * time.Hour
+
+// jwtExpiry reads the exp claim of a JWT, or returns the zero time for a
+// token that is not one or carries no exp.
+func jwtExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if json.Unmarshal(payload, &claims) != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
+
+// tokenExpiry is when the client should stop using tok.
+func tokenExpiry(tok Token, now time.Time) time.Time {
+	if !tok.Expiry.IsZero() {
+		return tok.Expiry
+	}
+	if exp := jwtExpiry(tok.Value); !exp.IsZero() {
+		return exp
+	}
+	return now.Add(defaultTokenLifetime)
+}
+
+// refreshAt is when a token expiring at expiry, fetched at now, is replaced so
+// no request goes out with a token about to lapse: a minute before expiry, or
+// a tenth of a shorter lifetime so a short-lived token is still reused.
+func refreshAt(expiry, now time.Time) time.Time {
+	margin := expiry.Sub(now) / 10
+	if margin > time.Minute {
+		margin = time.Minute
+	}
+	return expiry.Add(-margin)
+}
+
+// StaticToken always returns the same token, e.g. from AIRFLOW_TOKEN.
+type StaticToken string
+
+func (s StaticToken) Token(context.Context) (Token, error) {
+	if s == "" {
+		return Token{}, fmt.Errorf("%w: empty token", ErrAuthFailed)
+	}
+	return Token{Value: string(s)}, nil
+}
+
+// passwordAuth exchanges a username and password for a JWT at /auth/token,
+// Airflow 3's simple auth manager login.
+type passwordAuth struct {
+	url        string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+func (p *passwordAuth) Token(ctx context.Context) (Token, error) {
+	body, _ := json.Marshal(map[string]string{
+		"username": p.username,
+		"password": p.password,
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return Token{}, fmt.Errorf("create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return Token{}, fmt.Errorf("token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		apiErr := newAPIError(resp, respBody)
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return Token{}, fmt.Errorf("%w: token request: %w", ErrAuthFailed, apiErr)
+		}
+		return Token{}, fmt.Errorf("token request: %w", apiErr)
+	}
+
+	var tok tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return Token{}, fmt.Errorf("decode token: %w", err)
+	}
+	return Token{Value: tok.AccessToken}, nil
+}
+
+// TokenFile reads the token from a file on every refresh, so a token
+// rotated on disk (a mounted secret, a sidecar writing it) is picked up.
+type TokenFile struct {
+	Path string
+	// Refresh is how long a token without an exp claim is used before the
+	// file is read again; 0 means one minute.
+	Refresh time.Duration
+}
+
+func (f TokenFile) Token(context.Context) (Token, error) {
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return Token{}, fmt.Errorf("%w: read token file: %w", ErrAuthFailed, err)
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return Token{}, fmt.Errorf("%w: token file %s is empty", ErrAuthFailed, f.Path)
+	}
+	tok := Token{Value: value, Expiry: jwtExpiry(value)}
+	if tok.Expiry.IsZero() {
+		refresh := f.Refresh
+		if refresh <= 0 {
+			refresh = time.Minute
+		}
+		tok.Expiry = time.Now().Add(refresh)
+	}
+	return tok, nil
+}
+
+// ExecAuth runs a command that prints a token, like a kubectl credential
+// plugin. Its stdout is either the bare token or JSON carrying it:
+//
+//	{"token": "...", "expiry": "2026-01-02T15:04:05Z"}
+//	{"status": {"token": "...", "expirationTimestamp": "..."}}  (ExecCredential)
+//	{"access_token": "...", "expires_in": 3600}                 (OAuth2)
+//
+// The command runs only when the cached token nears its expiry or is
+// rejected, so an SSO helper that opens a browser is not run on every poll.
+type ExecAuth struct {
+	Command string
+	Args    []string
+	Env     map[string]string // added to lazyflow's own environment
+	Timeout time.Duration     // 0 means one minute
+}
+
+func (e *ExecAuth) Token(ctx context.Context) (Token, error) {
+	timeout := e.Timeout
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, e.Command, e.Args...)
+	cmd.Env = os.Environ()
+	for k, v := range e.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return Token{}, fmt.Errorf("%w: exec %s: %w", ErrAuthFailed, e.Command, err)
+		}
+		return Token{}, fmt.Errorf("%w: exec %s: %w: %s", ErrAuthFailed, e.Command, err, msg)
+	}
+	tok, err := parseExecOutput(stdout.Bytes(), time.Now())
+	if err != nil {
+		return Token{}, fmt.Errorf("%w: exec %s: %w", ErrAuthFailed, e.Command, err)
+	}
+	return tok, nil
+}
+
+func parseExecOutput(out []byte, now time.Time) (Token, error) {
+	out = bytes.TrimSpace(out)
+	if len(out) == 0 {
+		return Token{}, fmt.Errorf("printed no token")
+	}
+	if out[0] != '{' {
+		return Token{Value: string(out)}, nil
+	}
+	var v struct {
+		Token       string    `json:"token"`
+		Expiry      time.Time `json:"expiry"`
+		AccessToken string    `json:"access_token"`
+		ExpiresIn   int64     `json:"expires_in"`
+		Status      struct {
+			Token               string    `json:"token"`
+			ExpirationTimestamp time.Time `json:"expirationTimestamp"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(out, &v); err != nil {
+		return Token{}, fmt.Errorf("decode output: %w", err)
+	}
+	switch {
+	case v.Status.Token != "":
+		return Token{Value: v.Status.Token, Expiry: v.Status.ExpirationTimestamp}, nil
+	case v.AccessToken != "":
+		tok := Token{Value: v.AccessToken}
+		if v.ExpiresIn > 0 {
+			tok.Expiry = now.Add(time.Duration(v.ExpiresIn) * time.Second)
+		}
+		return tok, nil
+	case v.Token != "":
+		return Token{Value: v.Token, Expiry: v.Expiry}, nil
+	}
+	return Token{}, fmt.Errorf("no token in output")
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func fakeJWT(exp time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"sub":"admin","exp":%d}`, exp.Unix())))
+	return "h." + payload + ".s"
+}
+
+func TestTokenExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	exp := now.Add(15 * time.Minute)
+	given := now.Add(time.Hour)
+	for name, tc := range map[string]struct {
+		tok  Token
+		want time.Time
+	}{
+		"jwt exp":         {Token{Value: fakeJWT(exp)}, exp},
+		"provider expiry": {Token{Value: fakeJWT(exp), Expiry: given}, given},
+		"opaque":          {Token{Value: "opaque"}, now.Add(defaultTokenLifetime)},
+	} {
+		if got := tokenExpiry(tc.tok, now); !got.Equal(tc.want) {
+			t.Errorf("%s: tokenExpiry = %v, want %v", name, got, tc.want)
+		}
+	}
+}
+
+func TestParseExecOutput(t *testing.T) {
+	now := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	at := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	for out, want := range map[string]Token{
+		"tok-1\n": {Value: "tok-1"},
+		`{"token":"tok-2","expiry":"2026-01-02T10:00:00Z"}`:                                                 {Value: "tok-2", Expiry: at},
+		`{"kind":"ExecCredential","status":{"token":"tok-3","expirationTimestamp":"2026-01-02T10:00:00Z"}}`: {Value: "tok-3", Expiry: at},
+		`{"access_token":"tok-4","expires_in":3600}`:                                                        {Value: "tok-4", Expiry: at},
+	} {
+		got, err := parseExecOutput([]byte(out), now)
+		if err != nil || got.Value != want.Value || !got.Expiry.Equal(want.Expiry) {
+			t.Errorf("parseExecOutput(%s) = %+v, %v; want %+v", out, got, err, want)
+		}
+	}
+	for _, out := range []string{"", "  \n", `{"foo":"bar"}`} {
+		if _, err := parseExecOutput([]byte(out), now); err == nil {
+			t.Errorf("parseExecOutput(%q) accepted output without a token", out)
+		}
+	}
+}
+
+func TestExecAuth(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	tok, err := (&ExecAuth{
+		Command: "sh",
+		Args:    []string{"-c", `echo "{\"token\":\"$PREFIX-1\"}"`},
+		Env:     map[string]string{"PREFIX": "sso"},
+	}).Token(context.Background())
+	if err != nil || tok.Value != "sso-1" {
+		t.Fatalf("Token = %+v, %v; want sso-1", tok, err)
+	}
+
+	_, err = (&ExecAuth{Command: "sh", Args: []string{"-c", "echo login required >&2; exit 1"}}).Token(context.Background())
+	if !IsAuthError(err) {
+		t.Fatalf("failing command: err = %v, want an auth error", err)
+	}
+}
+
+func TestTokenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("file-tok\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tok, err := TokenFile{Path: path, Refresh: time.Second}.Token(context.Background())
+	if err != nil || tok.Value != "file-tok" || time.Until(tok.Expiry) > time.Second {
+		t.Fatalf("Token = %+v, %v; want file-tok re-read within 1s", tok, err)
+	}
+	if _, err := (TokenFile{Path: path + ".missing"}).Token(context.Background()); !IsAuthError(err) {
+		t.Fatalf("missing file: err = %v, want an auth error", err)
+	}
+}
+
+// countingAuth hands out tok-1, tok-2, ... with the given expiry.
+type countingAuth struct {
+	calls  atomic.Int32
+	expiry time.Duration
+}
+
+func (a *countingAuth) Token(context.Context) (Token, error) {
+	n := a.calls.Add(1)
+	return Token{Value: fmt.Sprintf("tok-%d", n), Expiry: time.Now().Add(a.expiry)}, nil
+}
+
+func TestClient_refreshesTokenBeforeExpiry(t *testing.T) {
+	var seen []string
+	_, srv := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	// An expired token is replaced on every request.
+	auth := &countingAuth{expiry: -time.Second}
+	c := NewClient(ClientConfig{BaseURL: srv.URL, Auth: auth})
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetHealth(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if auth.calls.Load() != 2 || seen[1] != "Bearer tok-2" {
+		t.Fatalf("provider calls=%d headers=%v, want a refresh per request", auth.calls.Load(), seen)
+	}
+
+	// A token shorter-lived than the one-minute margin is still reused.
+	auth = &countingAuth{expiry: 30 * time.Second}
+	c = NewClient(ClientConfig{BaseURL: srv.URL, Auth: auth})
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetHealth(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if auth.calls.Load() != 1 {
+		t.Fatalf("provider called %d times for a 30s token, want it cached", auth.calls.Load())
+	}
+
+	// Well before expiry the cached token is reused.
+	auth = &countingAuth{expiry: time.Hour}
+	c = NewClient(ClientConfig{BaseURL: srv.URL, Auth: auth})
+	for i := 0; i < 3; i++ {
+		if _, err := c.GetHealth(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if auth.calls.Load() != 1 {
+		t.Fatalf("provider called %d times, want the token cached", auth.calls.Load())
+	}
+}
+
+func TestRefreshAt(t *testing.T) {
+	now := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	for lifetime, want := range map[time.Duration]time.Duration{
+		time.Hour:        59 * time.Minute,
+		time.Minute:      54 * time.Second,
+		30 * time.Second: 27 * time.Second,
+	} {
+		if got := refreshAt(now.Add(lifetime), now).Sub(now); got != want {
+			t.Errorf("refreshAt(%v) = %v after now, want %v", lifetime, got, want)
+		}
+	}
+}
+
+func TestClient_tokenFileReadOncePerRefresh(t *testing.T) {
+	var seen []string
+	_, srv := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "token")
+	c := NewClient(ClientConfig{BaseURL: srv.URL, Auth: TokenFile{Path: path}})
+	for _, tok := range []string{"file-1", "file-2"} {
+		if err := os.WriteFile(path, []byte(tok), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.GetHealth(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if seen[0] != "Bearer file-1" || seen[1] != "Bearer file-1" {
+		t.Fatalf("headers = %v, want the file read once within its refresh", seen)
+	}
+}
+
+func TestClient_staticTokenNot401Retried(t *testing.T) {
+	var hits atomic.Int32
+	c, srv := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	if _, err := c.GetHealth(context.Background()); !IsAuthError(err) || hits.Load() != 1 {
+		t.Fatalf("err=%v hits=%d, want one attempt with a static token", err, hits.Load())
+	}
+}
@@ -7,12 +7,14 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func newTestClient(t *testing.T, handler http.Handler) (*Client, *httptest.Server) {
 	t.Helper()
 	srv := httptest.NewServer(handler)
 	c := NewClient(ClientConfig{BaseURL: srv.URL, Token: "test"})
+	c.retryDelay = time.Millisecond
 	return c, srv
 }
 
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Breaker is a per-endpoint circuit breaker for read requests. After
+// threshold consecutive failures that point at an unreachable or overloaded
+// server, the endpoint's circuit opens and calls fail fast with a
+// *CircuitOpenError until cooldown has passed; then a single probe is let
+// through, and its outcome closes the circuit or opens it again.
+//
+// One Breaker is shared by the Client and the pollers that drive it: the
+// pollers read CircuitOpenError.RetryAfter to sleep out the open window
+// instead of hammering a circuit that will refuse them. A nil *Breaker
+// allows everything.
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	circuits  map[string]*circuit
+}
+
+type circuit struct {
+	failures  int
+	openUntil time.Time // zero while closed
+	probing   bool      // a half-open probe is in flight
+	cause     error     // the failure that opened the circuit
+}
+
+// NewBreaker returns a breaker that opens after threshold consecutive
+// failures and stays open for cooldown.
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		circuits:  make(map[string]*circuit),
+	}
+}
+
+// CircuitOpenError is returned instead of making a request while the
+// endpoint's circuit is open.
+type CircuitOpenError struct {
+	Endpoint string
+	Until    time.Time
+	Cause    error // the failure that opened the circuit
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit open for %s until %s: %v", e.Endpoint, e.Until.Local().Format("15:04:05"), e.Cause)
+}
+
+func (e *CircuitOpenError) Unwrap() error { return e.Cause }
+
+// RetryAfter is how long until the circuit lets a probe through.
+func (e *CircuitOpenError) RetryAfter() time.Duration {
+	return time.Until(e.Until)
+}
+
+// Allow reports whether a request to endpoint may proceed.
+func (b *Breaker) Allow(endpoint string) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuits[endpoint]
+	if c == nil || c.openUntil.IsZero() {
+		return nil
+	}
+	if b.now().Before(c.openUntil) || c.probing {
+		return &CircuitOpenError{Endpoint: endpoint, Until: c.openUntil, Cause: c.cause}
+	}
+	c.probing = true
+	return nil
+}
+
+// Record feeds the outcome of an allowed request back into the breaker.
+// Only failures that say the server is unreachable or struggling count;
+// a 404 or a rejected token is the server working fine. A cancelled
+// request says nothing about the server, but it still ends a probe so the
+// next request can take its place.
+func (b *Breaker) Record(endpoint string, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuits[endpoint]
+	if c == nil {
+		c = &circuit{}
+		b.circuits[endpoint] = c
+	}
+	wasProbe := c.probing
+	c.probing = false
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	if !tripsBreaker(err) {
+		*c = circuit{}
+		return
+	}
+	c.failures++
+	if wasProbe || c.failures >= b.threshold {
+		c.openUntil = b.now().Add(b.cooldown)
+		c.cause = err
+	}
+}
+
+// tripsBreaker reports whether err is the kind of failure that more requests
+// would only make worse.
+func tripsBreaker(err error) bool {
+	if err == nil {
+		return false
+	}
+	return IsUnreachable(err) || errors.Is(err, ErrRateLimited) ||
+		statusOf(err) == http.StatusInternalServerError
+}
+
+// breakerKey maps a request path to its endpoint: the collection names with
+// the ids between them dropped, so every DAG's runs share one circuit.
+// "/api/v2/dags/etl/dagRuns/r1/taskInstances" becomes
+// "dags/dagRuns/taskInstances".
+func breakerKey(path string) string {
+	path = strings.TrimPrefix(path, "/api/v2/")
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	keep := parts[:0]
+	for i, p := range parts {
+		if i%2 == 0 {
+			keep = append(keep, p)
+		}
+	}
+	return strings.Join(keep, "/")
+}
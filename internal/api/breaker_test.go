@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBreaker_opensAndProbes(t *testing.T) {
+	now := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	b := NewBreaker(2, 30*time.Second)
+	b.now = func() time.Time { return now }
+	down := &APIError{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable", Detail: "down"}
+
+	b.Record("dags", down)
+	if err := b.Allow("dags"); err != nil {
+		t.Fatalf("one failure opened the circuit: %v", err)
+	}
+	b.Record("dags", down)
+	err := b.Allow("dags")
+	var open *CircuitOpenError
+	if !errors.As(err, &open) || !open.Until.Equal(now.Add(30*time.Second)) {
+		t.Fatalf("Allow after threshold = %v, want open until +30s", err)
+	}
+	if !IsUnreachable(err) {
+		t.Error("an open circuit caused by a 503 should count as unreachable")
+	}
+	if b.Allow("pools") != nil {
+		t.Error("circuits must be per endpoint")
+	}
+
+	now = now.Add(31 * time.Second)
+	if err := b.Allow("dags"); err != nil {
+		t.Fatalf("probe refused after cooldown: %v", err)
+	}
+	if b.Allow("dags") == nil {
+		t.Fatal("a second request got through while the probe was in flight")
+	}
+	b.Record("dags", down)
+	if b.Allow("dags") == nil {
+		t.Fatal("a failed probe should reopen the circuit")
+	}
+
+	now = now.Add(31 * time.Second)
+	_ = b.Allow("dags")
+	b.Record("dags", nil)
+	if err := b.Allow("dags"); err != nil {
+		t.Fatalf("a successful probe should close the circuit: %v", err)
+	}
+}
+
+func TestBreaker_cancelledProbeFreesTheCircuit(t *testing.T) {
+	now := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	b := NewBreaker(1, 30*time.Second)
+	b.now = func() time.Time { return now }
+	b.Record("dags", &APIError{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable"})
+
+	now = now.Add(31 * time.Second)
+	if err := b.Allow("dags"); err != nil {
+		t.Fatalf("probe refused after cooldown: %v", err)
+	}
+	// A poller restart cancels the probe mid-flight.
+	b.Record("dags", context.Canceled)
+	if err := b.Allow("dags"); err != nil {
+		t.Fatalf("a cancelled probe kept the circuit shut: %v", err)
+	}
+}
+
+func TestBreaker_ignoresClientErrors(t *testing.T) {
+	b := NewBreaker(1, time.Minute)
+	b.Record("dags", &APIError{StatusCode: http.StatusNotFound, Status: "404 Not Found"})
+	b.Record("dags", &APIError{StatusCode: http.StatusUnauthorized, Status: "401 Unauthorized"})
+	b.Record("dags", context.Canceled)
+	if err := b.Allow("dags"); err != nil {
+		t.Fatalf("client errors opened the circuit: %v", err)
+	}
+}
+
+func TestClient_failsFastWhileCircuitOpen(t *testing.T) {
+	var hits atomic.Int32
+	c, srv := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	c.breaker = NewBreaker(2, time.Minute)
+	c.retries = 0
+
+	for i := 0; i < 5; i++ {
+		_, _ = c.GetDAGRuns(context.Background(), "dag_"+string(rune('a'+i)), nil)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Fatalf("server hit %d times, want 2 before the circuit opened", got)
+	}
+	if _, err := c.GetHealth(context.Background()); err == nil || hits.Load() != 3 {
+		t.Fatalf("other endpoints must still be tried: err=%v hits=%d", err, hits.Load())
+	}
+}
+
+func TestBreakerKey(t *testing.T) {
+	for path, want := range map[string]string{
+		"/api/v2/dags":                                       "dags",
+		"/api/v2/dags/etl/dagRuns":                           "dags/dagRuns",
+		"/api/v2/dags/etl/dagRuns/r1/taskInstances":          "dags/dagRuns/taskInstances",
+		"/api/v2/dags/etl/dagRuns/r1/taskInstances/t/logs/1": "dags/dagRuns/taskInstances/logs",
+		"/api/v2/backfills?dag_id=etl":                       "backfills",
+		"/api/v2/monitor/health":                             "monitor",
+	} {
+		if got := breakerKey(path); got != want {
+			t.Errorf("breakerKey(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -32,24 +36,31 @@ const (
 	EndpointAuthToken     = "/auth/token"
 	EndpointBackfills     = "/api/v2/backfills"
 	EndpointPools         = "/api/v2/pools"
+	// EndpointStructure is the UI's graph endpoint, the only one that knows
+	// task groups; it lives outside /api/v2.
+	EndpointStructure = "/ui/structure/structure_data"
 )
 
 type Client struct {
-	baseURL     string
-	httpClient  *http.Client
-	username    string
-	password    string
-	rateLimiter <-chan time.Time
+	baseURL    string
+	httpClient *http.Client
+	auth       AuthProvider // nil: no credentials configured
+	sched      *scheduler
+	flights    flightGroup
+	breaker    *Breaker      // nil disables circuit breaking
+	retries    int           // extra attempts after a transient failure
+	retryDelay time.Duration // first backoff; doubles per attempt
+	readOnly   bool
 
 	// JWT token management.
-	// mu protects accessToken/tokenExpiry only; it is held for nanoseconds.
+	// mu protects accessToken/tokenRenew only; it is held for nanoseconds.
 	// refreshMu serializes concurrent refresh HTTP calls (avoid thundering
 	// herd) but is *not* held while reading the token, so unrelated API
 	// calls do not block on each other while one goroutine refreshes.
 	mu          sync.Mutex
 	refreshMu   sync.Mutex
 	accessToken string
-	tokenExpiry time.Time
+	tokenRenew  time.Time // when to ask the provider again, see refreshAt
 }
 
 type ClientConfig struct {
@@ -58,7 +69,20 @@ type ClientConfig struct {
 	Password string
 	Token    string // pre-existing token (optional)
 	AuthType string // ignored in Airflow 3 (always JWT)
-	Timeout  time.Duration
+	// Auth, when set, supplies tokens instead of Username/Password/Token.
+	Auth AuthProvider
+	// PathPrefix is where Airflow is mounted under BaseURL ("/airflow").
+	PathPrefix string
+	// Transport, when set, replaces http.DefaultTransport; see NewTransport.
+	Transport http.RoundTripper
+	Timeout   time.Duration
+	// Breaker, when set, fails reads fast on endpoints that keep failing.
+	// Writes always go through so an action reports the server's answer.
+	Breaker *Breaker
+	// Limits caps request rate and concurrency across every call.
+	Limits Limits
+	// ReadOnly refuses every POST, PATCH and DELETE with ErrReadOnly.
+	ReadOnly bool
 }
 
 func NewClient(cfg ClientConfig) *Client {
@@ -68,19 +92,30 @@ func NewClient(cfg ClientConfig) *Client {
 	}
 
 	c := &Client{
-		baseURL:  cfg.BaseURL,
-		username: cfg.Username,
-		password: cfg.Password,
+		baseURL: joinBaseURL(cfg.BaseURL, cfg.PathPrefix),
 		httpClient: &http.Client{
-			Timeout: timeout,
+			Transport: cfg.Transport,
+			Timeout:   timeout,
 		},
-		rateLimiter: time.Tick(100 * time.Millisecond), //nolint:staticcheck
-	}
-
-	// If a static token was provided, use it
-	if cfg.Token != "" {
-		c.accessToken = cfg.Token
-		c.tokenExpiry = time.Now().Add(24 * time.Hour)
+		sched:      newScheduler(cfg.Limits),
+		breaker:    cfg.Breaker,
+		retries:    defaultRetries,
+		retryDelay: defaultRetryDelay,
+		readOnly:   cfg.ReadOnly,
+	}
+
+	switch {
+	case cfg.Auth != nil:
+		c.auth = cfg.Auth
+	case cfg.Token != "":
+		c.auth = StaticToken(cfg.Token)
+	case cfg.Username != "":
+		c.auth = &passwordAuth{
+			url:        c.baseURL + EndpointAuthToken,
+			username:   cfg.Username,
+			password:   cfg.Password,
+			httpClient: c.httpClient,
+		}
 	}
 
 	return c
@@ -124,21 +159,24 @@ type tokenResponse struct {
 	AccessToken string `json:"access_token"`
 }
 
-// tokenIsFresh reports whether the cached token is still valid (with 60s buffer).
+// tokenIsFresh reports whether the cached token is still valid and not yet
+// due for renewal.
 func (c *Client) tokenIsFresh() bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	return c.accessToken != "" && time.Now().Add(60*time.Second).Before(c.tokenExpiry)
+	return c.accessToken != "" && time.Now().Before(c.tokenRenew)
 }
 
-// ensureToken acquires or refreshes a JWT token if needed.
+// ensureToken returns the cached token, asking the auth provider for a new
+// one when it is missing or about to expire.
 //
-// IMPORTANT: HTTP calls are made *outside* c.mu so they do not serialize
-// other goroutines reading the token via setAuth(). c.refreshMu serializes
-// concurrent refreshes so we don't issue duplicate /auth/token requests.
-func (c *Client) ensureToken() error {
+// IMPORTANT: the provider is called *outside* c.mu so a slow refresh (an
+// HTTP login, an exec plugin opening a browser) does not block goroutines
+// reading the token. c.refreshMu serializes concurrent refreshes so the
+// provider is asked once.
+func (c *Client) ensureToken(ctx context.Context) (string, error) {
 	if c.tokenIsFresh() {
-		return nil
+		return c.token(), nil
 	}
 
 	debugutil.Tag("FZ-api", "ensureToken acquiring refreshMu")
@@ -150,51 +188,28 @@ func (c *Client) ensureToken() error {
 
 	// Re-check: another goroutine may have refreshed while we waited.
 	if c.tokenIsFresh() {
-		return nil
-	}
-
-	if c.username == "" {
-		return fmt.Errorf("no credentials configured")
+		return c.token(), nil
 	}
 
-	body, _ := json.Marshal(map[string]string{
-		"username": c.username,
-		"password": c.password,
-	})
-
-	req, err := http.NewRequest("POST", c.baseURL+EndpointAuthToken, bytes.NewReader(body))
-	if err != nil {
-		return fmt.Errorf("create token request: %w", err)
+	if c.auth == nil {
+		return "", fmt.Errorf("%w: no credentials configured", ErrAuthFailed)
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	debugutil.Tag("FZ-api", "ensureToken POST %s START", EndpointAuthToken)
+	debugutil.Tag("FZ-api", "ensureToken %T START", c.auth)
 	tStart := time.Now()
-	resp, err := c.httpClient.Do(req)
-	debugutil.Tag("FZ-api", "ensureToken POST %s END elapsed=%v err=%v",
-		EndpointAuthToken, time.Since(tStart), err)
+	tok, err := c.auth.Token(ctx)
+	debugutil.Tag("FZ-api", "ensureToken %T END elapsed=%v err=%v", c.auth, time.Since(tStart), err)
 	if err != nil {
-		return fmt.Errorf("token request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("token request failed %s: %s", resp.Status, string(respBody))
-	}
-
-	var tok tokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
-		return fmt.Errorf("decode token: %w", err)
+		return "", err
 	}
 
 	// Store new token under c.mu (held for nanoseconds).
+	now := time.Now()
 	c.mu.Lock()
-	c.accessToken = tok.AccessToken
-	// Airflow 3 default token lifetime is 24h; use 23h to be safe
-	c.tokenExpiry = time.Now().Add(23 * time.Hour)
+	c.accessToken = tok.Value
+	c.tokenRenew = refreshAt(tokenExpiry(tok, now), now)
 	c.mu.Unlock()
-	return nil
+	return tok.Value, nil
 }
 
 // ---------- DAGs ----------
@@ -239,6 +254,16 @@ func (c *Client) GetTasks(ctx context.Context, dagId string) (*models.TaskCollec
 	return &out, nil
 }
 
+// GetDAGStructure fetches a DAG's node tree with its task groups.
+func (c *Client) GetDAGStructure(ctx context.Context, dagId string) (*models.DAGStructure, error) {
+	var out models.DAGStructure
+	endpoint := EndpointStructure + "?dag_id=" + url.QueryEscape(dagId)
+	if err := c.get(ctx, endpoint, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
 // ---------- Task Instances ----------
 
 func (c *Client) GetTaskInstances(ctx context.Context, dagId, runId string, opts *ListOptions) (*models.TaskInstanceCollection, error) {
@@ -253,42 +278,18 @@ func (c *Client) GetTaskInstances(ctx context.Context, dagId, runId string, opts
 // ---------- Task Logs ----------
 
 // GetTaskLogs fetches logs for a task instance. tryNumber defaults to 1 if <= 0.
-func (c *Client) GetTaskLogs(ctx context.Context, dagId, runId, taskId string, tryNumber int) (string, error) {
-	debugutil.Tag("FZ-api", "GET TaskLogs waitRateLimiter")
-	tWait := time.Now()
-	<-c.rateLimiter
-	debugutil.Tag("FZ-api", "GET TaskLogs rateLimiterAcquired waited=%v", time.Since(tWait))
-
-	if err := c.ensureToken(); err != nil {
-		return "", err
-	}
-
+// mapIndex picks one expansion of a mapped task; models.NotMapped for others.
+func (c *Client) GetTaskLogs(ctx context.Context, dagId, runId, taskId string, mapIndex, tryNumber int) (string, error) {
 	if tryNumber <= 0 {
 		tryNumber = 1
 	}
-
 	endpoint := fmt.Sprintf(EndpointTaskLogs, dagId, runId, taskId, tryNumber)
-	reqURL := c.baseURL + endpoint
-
-	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
-	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+	if mapIndex > models.NotMapped {
+		endpoint += "?map_index=" + strconv.Itoa(mapIndex)
 	}
-	c.setAuth(req)
-	req.Header.Set("Accept", "application/json")
-
-	debugutil.Tag("FZ-api", "GET %s START", endpoint)
-	tStart := time.Now()
-	resp, err := c.httpClient.Do(req)
+	body, err := c.getRaw(ctx, endpoint, nil, "application/json")
 	if err != nil {
-		debugutil.Tag("FZ-api", "GET %s END elapsed=%v err=%v", endpoint, time.Since(tStart), err)
-		return "", fmt.Errorf("execute request: %w", err)
-	}
-	defer resp.Body.Close()
-	debugutil.Tag("FZ-api", "GET %s END elapsed=%v status=%d", endpoint, time.Since(tStart), resp.StatusCode)
-
-	if resp.StatusCode != http.StatusOK {
-		return "", c.readError(resp)
+		return "", err
 	}
 
 	// Airflow 3 returns JSON:
@@ -301,7 +302,7 @@ func (c *Client) GetTaskLogs(ctx context.Context, dagId, runId, taskId string, t
 			Logger    string `json:"logger"`
 		} `json:"content"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&logResp); err != nil {
+	if err := json.Unmarshal(body, &logResp); err != nil {
 		return "", fmt.Errorf("decode log response: %w", err)
 	}
 
@@ -343,42 +344,9 @@ func (c *Client) GetHealth(ctx context.Context) (*models.HealthInfo, error) {
 // ---------- DAG Source ----------
 
 func (c *Client) GetDAGSource(ctx context.Context, dagId string) (string, error) {
-	debugutil.Tag("FZ-api", "GET DAGSource waitRateLimiter")
-	tWait := time.Now()
-	<-c.rateLimiter
-	debugutil.Tag("FZ-api", "GET DAGSource rateLimiterAcquired waited=%v", time.Since(tWait))
-
-	if err := c.ensureToken(); err != nil {
-		return "", err
-	}
-
-	endpoint := fmt.Sprintf(EndpointDAGSource, dagId)
-	reqURL := c.baseURL + endpoint
-
-	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
-	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
-	}
-	c.setAuth(req)
-	req.Header.Set("Accept", "text/plain")
-
-	debugutil.Tag("FZ-api", "GET %s START", endpoint)
-	tStart := time.Now()
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		debugutil.Tag("FZ-api", "GET %s END elapsed=%v err=%v", endpoint, time.Since(tStart), err)
-		return "", fmt.Errorf("execute request: %w", err)
-	}
-	defer resp.Body.Close()
-	debugutil.Tag("FZ-api", "GET %s END elapsed=%v status=%d", endpoint, time.Since(tStart), resp.StatusCode)
-
-	if resp.StatusCode != http.StatusOK {
-		return "", c.readError(resp)
-	}
-
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.getRaw(ctx, fmt.Sprintf(EndpointDAGSource, dagId), nil, "text/plain")
 	if err != nil {
-		return "", fmt.Errorf("read body: %w", err)
+		return "", err
 	}
 	return string(body), nil
 }
@@ -415,15 +383,28 @@ func (c *Client) GetVariables(ctx context.Context, opts *ListOptions) (*models.V
 
 // ---------- DAG Operations ----------
 
+// TriggerDAGRun creates a run. It is retried after a 5xx or timeout only
+// when body pins dag_run_id or logical_date: Airflow rejects a duplicate of
+// either with 409, so a retry cannot start a second run.
 func (c *Client) TriggerDAGRun(ctx context.Context, dagId string, body map[string]any) (*models.DAGRun, error) {
 	var out models.DAGRun
 	endpoint := fmt.Sprintf(EndpointDAGRuns, dagId)
-	if err := c.post(ctx, endpoint, body, &out); err != nil {
+	if err := c.write(ctx, http.MethodPost, endpoint, body, &out, pinsRun(body)); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
+// pinsRun reports whether a trigger body names its run uniquely.
+func pinsRun(body map[string]any) bool {
+	for _, k := range []string{"dag_run_id", "logical_date"} {
+		if v, ok := body[k].(string); ok && v != "" {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Client) PauseDAG(ctx context.Context, dagId string) error {
 	return c.patch(ctx, fmt.Sprintf(EndpointDAGs+"/%s", dagId), map[string]any{"is_paused": true}, nil)
 }
@@ -442,165 +423,242 @@ func (c *Client) CreateBackfill(ctx context.Context, body map[string]any) (*mode
 
 // ---------- internal helpers ----------
 
+// request is one API call as the retry loop sees it.
+type request struct {
+	method   string
+	endpoint string // path, for logs and errors
+	target   string // full URL
+	body     []byte // JSON; nil for none
+	accept   string
+	// retry marks the call as safe to resend after a 5xx or a timeout,
+	// when the server may already have acted on it.
+	retry bool
+}
+
+// Retry pacing for transient failures.
+const (
+	defaultRetries    = 2
+	defaultRetryDelay = 250 * time.Millisecond
+	// A Retry-After longer than this is handed back to the caller (the
+	// pollers back off on it) rather than slept out here.
+	maxRetryWait = 10 * time.Second
+)
+
 func (c *Client) post(ctx context.Context, endpoint string, body any, out any) error {
-	<-c.rateLimiter
+	return c.write(ctx, http.MethodPost, endpoint, body, out, false)
+}
 
-	if err := c.ensureToken(); err != nil {
-		return err
-	}
+func (c *Client) patch(ctx context.Context, endpoint string, body any, out any) error {
+	return c.write(ctx, http.MethodPatch, endpoint, body, out, true)
+}
 
-	jsonBody, err := json.Marshal(body)
-	if err != nil {
-		return fmt.Errorf("marshal body: %w", err)
-	}
+func (c *Client) delete(ctx context.Context, endpoint string) error {
+	return c.write(ctx, http.MethodDelete, endpoint, nil, nil, true)
+}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+endpoint, bytes.NewReader(jsonBody))
-	if err != nil {
-		return fmt.Errorf("create request: %w", err)
+// write sends a mutating request. Writes are always user actions, so they
+// jump the queue of background polls.
+func (c *Client) write(ctx context.Context, method, endpoint string, body, out any, retry bool) error {
+	if c.readOnly {
+		return fmt.Errorf("%s %s: %w", method, endpoint, ErrReadOnly)
+	}
+	r := request{method: method, endpoint: endpoint, target: c.baseURL + endpoint, accept: "application/json", retry: retry}
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal body: %w", err)
+		}
+		r.body = jsonBody
 	}
-	c.setAuth(req)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := c.httpClient.Do(req)
+	respBody, err := c.do(WithPriority(ctx, Interactive), r)
 	if err != nil {
-		return fmt.Errorf("execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return c.readError(resp)
+		return err
 	}
-
 	if out != nil {
-		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
 			return fmt.Errorf("decode response: %w", err)
 		}
 	}
 	return nil
 }
 
-func (c *Client) patch(ctx context.Context, endpoint string, body any, out any) error {
-	<-c.rateLimiter
-
-	if err := c.ensureToken(); err != nil {
+func (c *Client) get(ctx context.Context, endpoint string, opts *ListOptions, out any) error {
+	body, err := c.getRaw(ctx, endpoint, opts, "application/json")
+	if err != nil {
 		return err
 	}
-
-	jsonBody, err := json.Marshal(body)
-	if err != nil {
-		return fmt.Errorf("marshal body: %w", err)
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
 	}
+	return nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, "PATCH", c.baseURL+endpoint, bytes.NewReader(jsonBody))
-	if err != nil {
-		return fmt.Errorf("create request: %w", err)
+// getRaw sends a GET through the circuit breaker, de-duplication and the
+// scheduler, and returns the body of a 2xx response. Identical GETs in
+// flight at the same time share one request.
+func (c *Client) getRaw(ctx context.Context, endpoint string, opts *ListOptions, accept string) ([]byte, error) {
+	key := breakerKey(endpoint)
+	if err := c.breaker.Allow(key); err != nil {
+		return nil, err
 	}
-	c.setAuth(req)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	reqURL, err := url.Parse(c.baseURL + endpoint)
 	if err != nil {
-		return fmt.Errorf("execute request: %w", err)
+		return nil, fmt.Errorf("parse url: %w", err)
 	}
-	defer resp.Body.Close()
+	q := reqURL.Query()
+	opts.apply(q)
+	reqURL.RawQuery = q.Encode()
+	target := reqURL.String()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return c.readError(resp)
-	}
+	return c.flights.do(ctx, accept+" "+target, func(ctx context.Context) ([]byte, error) {
+		body, err := c.do(ctx, request{method: http.MethodGet, endpoint: endpoint, target: target, accept: accept, retry: true})
+		c.breaker.Record(key, err)
+		return body, err
+	})
+}
 
-	if out != nil {
-		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
-			return fmt.Errorf("decode response: %w", err)
+// do sends r, retrying transient failures with jittered exponential backoff
+// or the server's Retry-After.
+func (c *Client) do(ctx context.Context, r request) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		body, err := c.send(ctx, r)
+		if err == nil {
+			return body, nil
+		}
+		wait, ok := c.retryWait(ctx, r, attempt, err)
+		if !ok {
+			if attempt > 0 && r.method == http.MethodPost && errors.Is(err, ErrConflict) {
+				return nil, fmt.Errorf("%w (an earlier attempt that failed in transit may have succeeded)", err)
+			}
+			return nil, err
+		}
+		debugutil.Tag("FZ-api", "%s %s retry %d in %v: %v", r.method, r.endpoint, attempt+1, wait, err)
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, err
+		case <-t.C:
 		}
 	}
-	return nil
 }
 
-func (c *Client) delete(ctx context.Context, endpoint string) error {
-	<-c.rateLimiter
+// retryWait decides whether a failed attempt is worth repeating and after
+// how long. A 429 or a refused connection never reached the server's
+// handlers, so even non-idempotent requests retry on those.
+func (c *Client) retryWait(ctx context.Context, r request, attempt int, err error) (time.Duration, bool) {
+	if attempt >= c.retries || ctx.Err() != nil || errors.Is(err, ErrAuthFailed) {
+		return 0, false
+	}
+	var retryAfter time.Duration
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		retryAfter = apiErr.RetryAfter()
+	}
+	switch {
+	case errors.Is(err, ErrRateLimited), isRefused(err):
+	case r.retry && (IsUnreachable(err) || statusOf(err) == http.StatusInternalServerError):
+	default:
+		return 0, false
+	}
+	if retryAfter > maxRetryWait {
+		return 0, false
+	}
+	d := c.retryDelay << attempt
+	d = d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+	return max(d, retryAfter), true
+}
 
-	if err := c.ensureToken(); err != nil {
-		return err
-	}
+// isRefused reports a connection that failed before the request was sent.
+func isRefused(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.baseURL+endpoint, nil)
-	if err != nil {
-		return fmt.Errorf("create request: %w", err)
+// send makes one attempt. A 401 means the token was revoked or expired
+// early: ask the provider for a new one and, if it gives a different token,
+// try once more.
+func (c *Client) send(ctx context.Context, r request) ([]byte, error) {
+	debugutil.Tag("FZ-api", "%s %s waitScheduler", r.method, r.endpoint)
+	tWait := time.Now()
+	if err := c.sched.acquire(ctx); err != nil {
+		return nil, err
 	}
-	c.setAuth(req)
+	defer c.sched.release()
+	debugutil.Tag("FZ-api", "%s %s admitted waited=%v", r.method, r.endpoint, time.Since(tWait))
 
-	resp, err := c.httpClient.Do(req)
+	token, err := c.ensureToken(ctx)
 	if err != nil {
-		return fmt.Errorf("execute request: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 300 {
-		return c.readError(resp)
+	body, err := c.roundTrip(ctx, r, token)
+	if !errors.Is(err, ErrUnauthorized) {
+		return body, err
 	}
-	return nil
+	debugutil.Tag("FZ-api", "%s %s 401, re-authenticating", r.method, r.endpoint)
+	c.invalidateToken(token)
+	fresh, authErr := c.ensureToken(ctx)
+	if authErr != nil {
+		return nil, authErr
+	}
+	if fresh == token {
+		return nil, err // a static token: asking again changes nothing
+	}
+	return c.roundTrip(ctx, r, fresh)
 }
 
-func (c *Client) get(ctx context.Context, endpoint string, opts *ListOptions, out any) error {
-	debugutil.Tag("FZ-api", "GET %s waitRateLimiter", endpoint)
-	tWait := time.Now()
-	<-c.rateLimiter
-	debugutil.Tag("FZ-api", "GET %s rateLimiterAcquired waited=%v", endpoint, time.Since(tWait))
-
-	if err := c.ensureToken(); err != nil {
-		return err
+func (c *Client) roundTrip(ctx context.Context, r request, token string) ([]byte, error) {
+	var reqBody io.Reader
+	if r.body != nil {
+		reqBody = bytes.NewReader(r.body)
 	}
-
-	reqURL, err := url.Parse(c.baseURL + endpoint)
+	req, err := http.NewRequestWithContext(ctx, r.method, r.target, reqBody)
 	if err != nil {
-		return fmt.Errorf("parse url: %w", err)
+		return nil, fmt.Errorf("create request: %w", err)
 	}
-
-	q := reqURL.Query()
-	opts.apply(q)
-	reqURL.RawQuery = q.Encode()
-
-	req, err := http.NewRequestWithContext(ctx, "GET", reqURL.String(), nil)
-	if err != nil {
-		return fmt.Errorf("create request: %w", err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
 	}
-	c.setAuth(req)
-	req.Header.Set("Accept", "application/json")
+	if r.body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", r.accept)
 
-	debugutil.Tag("FZ-api", "GET %s START", endpoint)
+	debugutil.Tag("FZ-api", "%s %s START", r.method, r.endpoint)
 	tStart := time.Now()
 	resp, err := c.httpClient.Do(req)
 	elapsed := time.Since(tStart)
 	if err != nil {
-		debugutil.Tag("FZ-api", "GET %s END elapsed=%v err=%v", endpoint, elapsed, err)
-		return fmt.Errorf("execute request: %w", err)
+		debugutil.Tag("FZ-api", "%s %s END elapsed=%v err=%v", r.method, r.endpoint, elapsed, err)
+		return nil, fmt.Errorf("execute request: %w", err)
 	}
 	defer resp.Body.Close()
-	debugutil.Tag("FZ-api", "GET %s END elapsed=%v status=%d", endpoint, elapsed, resp.StatusCode)
+	debugutil.Tag("FZ-api", "%s %s END elapsed=%v status=%d", r.method, r.endpoint, elapsed, resp.StatusCode)
 
-	if resp.StatusCode != http.StatusOK {
-		return c.readError(resp)
+	body, err := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, newAPIError(resp, body)
 	}
-
-	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
-		return fmt.Errorf("decode response: %w", err)
+	if err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
 	}
-	return nil
+	return body, nil
 }
 
-func (c *Client) setAuth(req *http.Request) {
+func (c *Client) token() string {
 	c.mu.Lock()
-	token := c.accessToken
-	c.mu.Unlock()
-
-	if token != "" {
-		req.Header.Set("Authorization", "Bearer "+token)
-	}
+	defer c.mu.Unlock()
+	return c.accessToken
 }
 
-func (c *Client) readError(resp *http.Response) error {
-	body, _ := io.ReadAll(resp.Body)
-	return fmt.Errorf("api error %s: %s", resp.Status, string(body))
+// invalidateToken drops token so the next ensureToken fetches a new one,
+// unless another goroutine has already replaced it.
+func (c *Client) invalidateToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.accessToken == token {
+		c.accessToken = ""
+		c.tokenRenew = time.Time{}
+	}
 }
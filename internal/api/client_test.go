@@ -0,0 +1,199 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+func TestClient_retriesTransientGetFailures(t *testing.T) {
+	var hits atomic.Int32
+	c, srv := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"metadatabase":{"status":"healthy"}}`))
+	}))
+	defer srv.Close()
+
+	if _, err := c.GetHealth(context.Background()); err != nil {
+		t.Fatalf("GetHealth after one 502: %v", err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Fatalf("server hit %d times, want 2", got)
+	}
+}
+
+func TestClient_honorsRetryAfter(t *testing.T) {
+	var hits atomic.Int32
+	var first time.Time
+	var gap time.Duration
+	c, srv := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		gap = time.Since(first)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	// A POST is retried on 429: the server never started on it.
+	if _, err := c.TriggerDAGRun(context.Background(), "etl", map[string]any{}); err != nil {
+		t.Fatalf("TriggerDAGRun after 429: %v", err)
+	}
+	if gap < time.Second {
+		t.Fatalf("retried after %v, want >= Retry-After of 1s", gap)
+	}
+}
+
+func TestClient_doesNotRetryUnpinnedTrigger(t *testing.T) {
+	var hits atomic.Int32
+	c, srv := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	if _, err := c.TriggerDAGRun(context.Background(), "etl", map[string]any{"conf": map[string]any{}}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("unpinned trigger sent %d times; a retry could start a second run", got)
+	}
+
+	hits.Store(0)
+	_, err := c.TriggerDAGRun(context.Background(), "etl", map[string]any{"dag_run_id": "manual_1"})
+	if got := hits.Load(); got != 3 || !IsUnreachable(err) {
+		t.Fatalf("pinned trigger: hits=%d err=%v, want 3 attempts", got, err)
+	}
+}
+
+func TestClient_reauthenticatesOn401(t *testing.T) {
+	var logins, calls atomic.Int32
+	srv := newAuthServer(t, &logins, func(w http.ResponseWriter, r *http.Request, token string) {
+		calls.Add(1)
+		if token != "tok-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"detail":"Invalid JWT token"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	})
+	defer srv.Close()
+	c := NewClient(ClientConfig{BaseURL: srv.URL, Username: "admin", Password: "admin"})
+
+	if _, err := c.GetHealth(context.Background()); err != nil {
+		t.Fatalf("GetHealth with a revoked token: %v", err)
+	}
+	if logins.Load() != 2 || calls.Load() != 2 {
+		t.Fatalf("logins=%d calls=%d, want one re-login and one retry", logins.Load(), calls.Load())
+	}
+}
+
+func TestClient_givesUpAfterOneReauth(t *testing.T) {
+	var logins, calls atomic.Int32
+	srv := newAuthServer(t, &logins, func(w http.ResponseWriter, r *http.Request, token string) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	defer srv.Close()
+	c := NewClient(ClientConfig{BaseURL: srv.URL, Username: "admin", Password: "admin"})
+
+	_, err := c.GetHealth(context.Background())
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("err = %v, want ErrUnauthorized", err)
+	}
+	if logins.Load() != 2 || calls.Load() != 2 {
+		t.Fatalf("logins=%d calls=%d, want exactly one re-login", logins.Load(), calls.Load())
+	}
+}
+
+// newAuthServer serves /auth/token with tokens tok-1, tok-2, ... and hands
+// every other request to api with the bearer token it carried.
+func newAuthServer(t *testing.T, logins *atomic.Int32, api func(http.ResponseWriter, *http.Request, string)) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == EndpointAuthToken {
+			n := logins.Add(1)
+			_ = json.NewEncoder(w).Encode(map[string]string{"access_token": fmt.Sprintf("tok-%d", n)})
+			return
+		}
+		api(w, r, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	}))
+}
+
+func TestClient_readOnlyRefusesWrites(t *testing.T) {
+	var writes atomic.Int32
+	_, srv := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writes.Add(1)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	c := NewClient(ClientConfig{BaseURL: srv.URL, Token: "test", ReadOnly: true})
+
+	if _, err := c.TriggerDAGRun(context.Background(), "etl", map[string]any{}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("TriggerDAGRun: err = %v, want ErrReadOnly", err)
+	}
+	if err := c.PauseDAG(context.Background(), "etl"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("PauseDAG: err = %v, want ErrReadOnly", err)
+	}
+	if writes.Load() != 0 {
+		t.Fatalf("%d writes reached the server", writes.Load())
+	}
+	if _, err := c.GetHealth(context.Background()); err != nil {
+		t.Errorf("reads must still work: %v", err)
+	}
+}
+
+func TestClient_getDAGStructure(t *testing.T) {
+	var path, dagId string
+	c, srv := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, dagId = r.URL.Path, r.URL.Query().Get("dag_id")
+		_, _ = w.Write([]byte(`{"nodes":[{"id":"g","type":"task","children":[{"id":"g.t","type":"task"}]}],"edges":[]}`))
+	}))
+	defer srv.Close()
+
+	s, err := c.GetDAGStructure(context.Background(), "etl v2")
+	if err != nil {
+		t.Fatalf("GetDAGStructure: %v", err)
+	}
+	if path != EndpointStructure || dagId != "etl v2" {
+		t.Fatalf("requested %s?dag_id=%s", path, dagId)
+	}
+	if groups := s.TaskGroups()["g.t"]; len(groups) != 1 || groups[0] != "g" {
+		t.Fatalf("groups of g.t = %v", groups)
+	}
+}
+
+func TestClient_getTaskLogsOfMappedInstance(t *testing.T) {
+	var mapIndex []string
+	c, srv := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mapIndex = append(mapIndex, r.URL.Query().Get("map_index"))
+		_, _ = w.Write([]byte(`{"content":[]}`))
+	}))
+	defer srv.Close()
+
+	for _, i := range []int{models.NotMapped, 0, 3} {
+		if _, err := c.GetTaskLogs(context.Background(), "etl", "r1", "load", i, 1); err != nil {
+			t.Fatalf("GetTaskLogs map index %d: %v", i, err)
+		}
+	}
+	if got := strings.Join(mapIndex, ","); got != ",0,3" {
+		t.Fatalf("map_index sent = %q, want none for an unmapped task", got)
+	}
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sentinels matched by *APIError through errors.Is.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrConflict     = errors.New("conflict")
+	ErrRateLimited  = errors.New("rate limited")
+	// ErrAuthFailed wraps a token request the server refused, or missing
+	// credentials: retrying with the same configuration cannot succeed.
+	ErrAuthFailed = errors.New("authentication failed")
+	// ErrReadOnly is returned, without contacting the server, for any write
+	// made by a read-only Client.
+	ErrReadOnly = errors.New("read-only mode: writes are disabled")
+)
+
+// APIError is a non-2xx answer from the Airflow API. Detail comes from the
+// problem-details body Airflow sends ({"detail": ...}), or is the raw body
+// when there is none.
+type APIError struct {
+	StatusCode int
+	Status     string // "404 Not Found"
+	Title      string
+	Detail     string
+	retryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error %s: %s", e.Status, e.Detail)
+}
+
+// Is maps the status code onto the package sentinels.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// RetryAfter is the wait the server asked for in a Retry-After header, or 0.
+func (e *APIError) RetryAfter() time.Duration { return e.retryAfter }
+
+// newAPIError reads resp's body into an *APIError.
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	e := &APIError{StatusCode: resp.StatusCode, Status: resp.Status}
+	e.Title, e.Detail = parseProblem(body)
+	e.retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	return e
+}
+
+// parseProblem extracts title and detail from a problem-details body. FastAPI
+// validation errors put a list of {loc, msg} objects in detail.
+func parseProblem(body []byte) (title, detail string) {
+	var p struct {
+		Title  string          `json:"title"`
+		Detail json.RawMessage `json:"detail"`
+	}
+	if err := json.Unmarshal(body, &p); err != nil || len(p.Detail) == 0 {
+		return p.Title, strings.TrimSpace(string(body))
+	}
+	if err := json.Unmarshal(p.Detail, &detail); err == nil {
+		return p.Title, detail
+	}
+	var items []struct {
+		Loc []any  `json:"loc"`
+		Msg string `json:"msg"`
+	}
+	if err := json.Unmarshal(p.Detail, &items); err == nil && len(items) > 0 {
+		msgs := make([]string, 0, len(items))
+		for _, it := range items {
+			msg := it.Msg
+			if len(it.Loc) > 0 {
+				msg = fmt.Sprint(it.Loc[len(it.Loc)-1]) + ": " + msg
+			}
+			msgs = append(msgs, msg)
+		}
+		return p.Title, strings.Join(msgs, "; ")
+	}
+	return p.Title, string(p.Detail)
+}
+
+// parseRetryAfter reads a Retry-After header in either delay-seconds or
+// HTTP-date form.
+func parseRetryAfter(h string, now time.Time) time.Duration {
+	if h == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(strings.TrimSpace(h)); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(h); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// statusOf returns the HTTP status of an API error in err's chain, or 0.
+func statusOf(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// IsUnreachable reports whether err means the API could not be reached at
+// all — a transport failure, timeout, or a gateway answering for a down
+// webserver — as opposed to the API rejecting the request.
+func IsUnreachable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var open *CircuitOpenError
+	if errors.As(err, &open) {
+		return IsUnreachable(open.Cause)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return !errors.Is(urlErr.Err, context.Canceled)
+	}
+	switch statusOf(err) {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// IsAuthError reports whether err means the credentials were rejected or
+// missing, so retrying with the same configuration cannot succeed.
+func IsAuthError(err error) bool {
+	return errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrAuthFailed)
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIsUnreachable(t *testing.T) {
+	status := http.StatusOK
+	c, srv := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+
+	for code, want := range map[int]bool{
+		http.StatusNotFound:            false,
+		http.StatusForbidden:           false,
+		http.StatusInternalServerError: false,
+		http.StatusBadGateway:          true,
+		http.StatusServiceUnavailable:  true,
+	} {
+		status = code
+		_, err := c.GetHealth(context.Background())
+		if got := IsUnreachable(err); got != want {
+			t.Errorf("status %d: IsUnreachable(%v) = %v, want %v", code, err, got, want)
+		}
+	}
+
+	srv.Close()
+	_, err := c.GetHealth(context.Background())
+	if !IsUnreachable(err) {
+		t.Errorf("closed server: IsUnreachable(%v) = false", err)
+	}
+	if IsUnreachable(nil) {
+		t.Error("nil error reported unreachable")
+	}
+}
+
+func TestIsAuthError(t *testing.T) {
+	status := http.StatusOK
+	c, srv := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	for code, want := range map[int]bool{
+		http.StatusUnauthorized:       true,
+		http.StatusForbidden:          false,
+		http.StatusServiceUnavailable: false,
+	} {
+		status = code
+		_, err := c.GetHealth(context.Background())
+		if got := IsAuthError(err); got != want {
+			t.Errorf("status %d: IsAuthError(%v) = %v, want %v", code, err, got, want)
+		}
+	}
+
+	status = http.StatusUnauthorized
+	pw := NewClient(ClientConfig{BaseURL: srv.URL, Username: "admin", Password: "expired"})
+	if _, err := pw.GetHealth(context.Background()); !IsAuthError(err) {
+		t.Errorf("rejected password: IsAuthError(%v) = false", err)
+	}
+}
+
+func TestAPIError_problemDetails(t *testing.T) {
+	for body, want := range map[string]string{
+		`{"title":"Not Found","detail":"DAG with dag_id: 'etl' was not found"}`:           "DAG with dag_id: 'etl' was not found",
+		`{"detail":[{"loc":["body","conf"],"msg":"Input should be a valid dictionary"}]}`: "conf: Input should be a valid dictionary",
+		`upstream connect error`: "upstream connect error",
+	} {
+		_, got := parseProblem([]byte(body))
+		if got != want {
+			t.Errorf("parseProblem(%s) = %q, want %q", body, got, want)
+		}
+	}
+
+	c, srv := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"detail":"DAGRun with dag_run_id: 'r1' already exists"}`))
+	}))
+	defer srv.Close()
+	_, err := c.TriggerDAGRun(context.Background(), "etl", map[string]any{})
+	var apiErr *APIError
+	if !errors.Is(err, ErrConflict) || !errors.As(err, &apiErr) || apiErr.Detail != "DAGRun with dag_run_id: 'r1' already exists" {
+		t.Fatalf("TriggerDAGRun err = %#v, want ErrConflict with detail", err)
+	}
+	if errors.Is(err, ErrNotFound) {
+		t.Error("a 409 matched ErrNotFound")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	for h, want := range map[string]time.Duration{
+		"":                              0,
+		"3":                             3 * time.Second,
+		"Fri, 02 Jan 2026 09:00:10 GMT": 10 * time.Second,
+		"Fri, 02 Jan 2026 08:59:00 GMT": 0,
+		"soon":                          0,
+	} {
+		if got := parseRetryAfter(h, now); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", h, got, want)
+		}
+	}
+}
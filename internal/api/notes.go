@@ -0,0 +1,37 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+// noteMask limits a PATCH to the note so the rest of the resource (state in
+// particular) is left untouched.
+const noteMask = "?update_mask=note"
+
+// SetDAGRunNote replaces a DAG run's note. An empty note clears it.
+func (c *Client) SetDAGRunNote(ctx context.Context, dagId, runId, note string) (*models.DAGRun, error) {
+	var out models.DAGRun
+	endpoint := fmt.Sprintf(EndpointDAGRuns+"/%s", dagId, runId) + noteMask
+	if err := c.patch(ctx, endpoint, map[string]any{"note": note}, &out); err != nil {
+		return nil, fmt.Errorf("set run note: %w", err)
+	}
+	return &out, nil
+}
+
+// SetTaskInstanceNote replaces a task instance's note. An empty note clears it.
+// mapIndex picks one expansion of a mapped task; models.NotMapped for others.
+func (c *Client) SetTaskInstanceNote(ctx context.Context, dagId, runId, taskId string, mapIndex int, note string) (*models.TaskInstance, error) {
+	var out models.TaskInstance
+	endpoint := fmt.Sprintf(EndpointTaskInstances+"/%s", dagId, runId, taskId) + noteMask
+	if mapIndex > models.NotMapped {
+		endpoint += "&map_index=" + strconv.Itoa(mapIndex)
+	}
+	if err := c.patch(ctx, endpoint, map[string]any{"note": note}, &out); err != nil {
+		return nil, fmt.Errorf("set task note: %w", err)
+	}
+	return &out, nil
+}
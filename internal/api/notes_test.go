@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+func TestSetNotes_patchOnlyNote(t *testing.T) {
+	type call struct {
+		method, path, mask string
+		body               map[string]any
+	}
+	var calls []call
+	c, srv := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		calls = append(calls, call{r.Method, r.URL.Path, r.URL.Query().Get("update_mask"), body})
+		_ = json.NewEncoder(w).Encode(map[string]any{"note": body["note"]})
+	}))
+	defer srv.Close()
+
+	run, err := c.SetDAGRunNote(context.Background(), "etl", "manual_1", "rerun after fix")
+	if err != nil {
+		t.Fatalf("SetDAGRunNote: %v", err)
+	}
+	if run.Note != "rerun after fix" {
+		t.Fatalf("run note = %q", run.Note)
+	}
+	if _, err := c.SetTaskInstanceNote(context.Background(), "etl", "manual_1", "load", models.NotMapped, ""); err != nil {
+		t.Fatalf("SetTaskInstanceNote: %v", err)
+	}
+
+	want := []call{
+		{http.MethodPatch, "/api/v2/dags/etl/dagRuns/manual_1", "note", nil},
+		{http.MethodPatch, "/api/v2/dags/etl/dagRuns/manual_1/taskInstances/load", "note", nil},
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %d, want %d", len(calls), len(want))
+	}
+	for i, w := range want {
+		got := calls[i]
+		if got.method != w.method || got.path != w.path || got.mask != w.mask {
+			t.Errorf("call %d = %s %s mask=%q, want %s %s mask=%q", i, got.method, got.path, got.mask, w.method, w.path, w.mask)
+		}
+		if len(got.body) != 1 {
+			t.Errorf("call %d body = %v, want only note", i, got.body)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Priority orders requests waiting for the rate limit or an in-flight slot.
+type Priority int
+
+const (
+	// Background is the default: periodic polls and prefetches.
+	Background Priority = iota
+	// Interactive is a request the user is waiting on — a fetch triggered
+	// by a selection, or an action. Writes are always Interactive.
+	Interactive
+)
+
+type priorityKey struct{}
+
+// WithPriority marks requests made with ctx. Waiting Interactive requests
+// are always served before Background ones.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+func priorityOf(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	return Background
+}
+
+// Limits bounds the request rate and concurrency of a Client. Zero fields
+// take DefaultLimits' values; a negative field disables that limit.
+type Limits struct {
+	RequestsPerSecond float64 // token bucket refill rate
+	Burst             int     // token bucket size
+	MaxInFlight       int     // concurrent requests
+}
+
+// DefaultLimits matches what a single Airflow webserver handles comfortably.
+func DefaultLimits() Limits {
+	return Limits{RequestsPerSecond: 10, Burst: 5, MaxInFlight: 6}
+}
+
+func (l Limits) withDefaults() Limits {
+	d := DefaultLimits()
+	if l.RequestsPerSecond == 0 {
+		l.RequestsPerSecond = d.RequestsPerSecond
+	}
+	if l.Burst == 0 {
+		l.Burst = d.Burst
+	}
+	if l.MaxInFlight == 0 {
+		l.MaxInFlight = d.MaxInFlight
+	}
+	return l
+}
+
+// scheduler admits requests under a token bucket and an in-flight cap,
+// Interactive first, FIFO within a priority.
+type scheduler struct {
+	mu          sync.Mutex
+	rate        float64 // tokens per second; <= 0 is unlimited
+	burst       float64
+	tokens      float64
+	refilled    time.Time
+	maxInFlight int // <= 0 is unlimited
+	inFlight    int
+	queues      [Interactive + 1][]*waiter
+	timer       *time.Timer // armed while the queue waits for a token
+	now         func() time.Time
+}
+
+type waiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+func newScheduler(l Limits) *scheduler {
+	l = l.withDefaults()
+	burst := float64(max(l.Burst, 1))
+	return &scheduler{
+		rate:        l.RequestsPerSecond,
+		burst:       burst,
+		tokens:      burst,
+		refilled:    time.Now(),
+		maxInFlight: l.MaxInFlight,
+		now:         time.Now,
+	}
+}
+
+// acquire blocks until the request may be sent. Every successful acquire
+// must be paired with a release.
+func (s *scheduler) acquire(ctx context.Context) error {
+	w := &waiter{ready: make(chan struct{})}
+	prio := priorityOf(ctx)
+
+	s.mu.Lock()
+	s.queues[prio] = append(s.queues[prio], w)
+	s.pump()
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if w.granted {
+			// Lost the race with pump: hand the slot back.
+			s.inFlight--
+			s.pump()
+		} else {
+			s.queues[prio] = removeWaiter(s.queues[prio], w)
+		}
+		return ctx.Err()
+	}
+}
+
+func (s *scheduler) release() {
+	s.mu.Lock()
+	s.inFlight--
+	s.pump()
+	s.mu.Unlock()
+}
+
+// pump grants queued waiters while a slot and a token are available. When
+// only a token is missing it arms a timer for when one will be. Callers hold
+// s.mu.
+func (s *scheduler) pump() {
+	for {
+		prio := Interactive
+		for prio >= Background && len(s.queues[prio]) == 0 {
+			prio--
+		}
+		if prio < Background {
+			return
+		}
+		if s.maxInFlight > 0 && s.inFlight >= s.maxInFlight {
+			return // release pumps again
+		}
+		if s.rate > 0 {
+			now := s.now()
+			s.tokens = min(s.burst, s.tokens+now.Sub(s.refilled).Seconds()*s.rate)
+			s.refilled = now
+			if s.tokens < 1 {
+				if s.timer == nil {
+					wait := time.Duration((1 - s.tokens) / s.rate * float64(time.Second))
+					s.timer = time.AfterFunc(wait, func() {
+						s.mu.Lock()
+						s.timer = nil
+						s.pump()
+						s.mu.Unlock()
+					})
+				}
+				return
+			}
+			s.tokens--
+		}
+		w := s.queues[prio][0]
+		s.queues[prio] = s.queues[prio][1:]
+		s.inFlight++
+		w.granted = true
+		close(w.ready)
+	}
+}
+
+func removeWaiter(q []*waiter, w *waiter) []*waiter {
+	for i, x := range q {
+		if x == w {
+			return append(q[:i], q[i+1:]...)
+		}
+	}
+	return q
+}
+
+// flightGroup de-duplicates identical concurrent GETs: callers asking for
+// the same URL while a request for it is outstanding share its response.
+// The shared request is cancelled only once every caller has given up.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+type flight struct {
+	done    chan struct{}
+	body    []byte
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+func (g *flightGroup) do(ctx context.Context, key string, fn func(context.Context) ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flight)
+	}
+	f, ok := g.calls[key]
+	if !ok {
+		// Detached from the first caller's cancellation (it may leave
+		// while others still wait) but keeping its values, i.e. priority.
+		fctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = f
+		go func() {
+			f.body, f.err = fn(fctx)
+			cancel()
+			g.forget(key, f)
+			close(f.done)
+		}()
+	}
+	f.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.body, f.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		f.waiters--
+		last := f.waiters == 0
+		g.mu.Unlock()
+		if last {
+			f.cancel()
+			g.forget(key, f)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// forget drops key so the next caller starts a fresh request, unless a newer
+// flight has already replaced f.
+func (g *flightGroup) forget(key string, f *flight) {
+	g.mu.Lock()
+	if g.calls[key] == f {
+		delete(g.calls, key)
+	}
+	g.mu.Unlock()
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduler_capsInFlightAndPrefersInteractive(t *testing.T) {
+	s := newScheduler(Limits{RequestsPerSecond: -1, MaxInFlight: 1})
+	ctx := context.Background()
+	if err := s.acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	start := func(name string, ctx context.Context) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.acquire(ctx); err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			s.release()
+		}()
+	}
+	start("poll", ctx)
+	time.Sleep(20 * time.Millisecond) // queue the poll first
+	start("action", WithPriority(ctx, Interactive))
+	time.Sleep(20 * time.Millisecond)
+
+	s.release()
+	wg.Wait()
+	if len(order) != 2 || order[0] != "action" {
+		t.Fatalf("admission order = %v, want the interactive request first", order)
+	}
+}
+
+func TestScheduler_tokenBucket(t *testing.T) {
+	s := newScheduler(Limits{RequestsPerSecond: 50, Burst: 2, MaxInFlight: -1})
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := s.acquire(ctx); err != nil {
+			t.Fatal(err)
+		}
+		s.release()
+	}
+	// Two from the burst, then two refills at 20ms each.
+	if d := time.Since(start); d < 30*time.Millisecond {
+		t.Fatalf("4 requests at 50/s with burst 2 took %v, want >= 40ms", d)
+	}
+}
+
+func TestScheduler_cancelWhileQueued(t *testing.T) {
+	s := newScheduler(Limits{RequestsPerSecond: -1, MaxInFlight: 1})
+	if err := s.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.acquire(ctx); err == nil {
+		t.Fatal("acquire should fail once its context ends")
+	}
+	s.release()
+	if err := s.acquire(context.Background()); err != nil {
+		t.Fatalf("slot leaked by the cancelled waiter: %v", err)
+	}
+}
+
+// Selecting a DAG fires the same runs GET from two places at once; the
+// server should see it once.
+func TestClient_deduplicatesConcurrentGets(t *testing.T) {
+	var hits atomic.Int32
+	release := make(chan struct{})
+	c, srv := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		_, _ = w.Write([]byte(`{"dag_runs":[{"dag_id":"etl","dag_run_id":"r1"}],"total_entries":1}`))
+	}))
+	defer srv.Close()
+
+	var wg sync.WaitGroup
+	results := make([]int, 3)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runs, err := c.GetDAGRuns(context.Background(), "etl", &ListOptions{Limit: 50})
+			if err == nil {
+				results[i] = len(runs.DAGRuns)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("server hit %d times, want 1", got)
+	}
+	for i, n := range results {
+		if n != 1 {
+			t.Errorf("caller %d got %d runs", i, n)
+		}
+	}
+}
+
+// One caller giving up must not fail the others sharing its request.
+func TestFlightGroup_survivesLeaderCancel(t *testing.T) {
+	var g flightGroup
+	gate := make(chan struct{})
+	fn := func(ctx context.Context) ([]byte, error) {
+		select {
+		case <-gate:
+			return []byte("ok"), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := g.do(leaderCtx, "k", fn)
+		leaderErr <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	follower := make(chan string, 1)
+	go func() {
+		body, _ := g.do(context.Background(), "k", fn)
+		follower <- string(body)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	cancelLeader()
+	if err := <-leaderErr; err == nil {
+		t.Fatal("cancelled leader should return its context error")
+	}
+	close(gate)
+	if got := <-follower; got != "ok" {
+		t.Fatalf("follower got %q after the leader left", got)
+	}
+}
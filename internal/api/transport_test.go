@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writePEM(t *testing.T, path, typ string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newClientCert writes a self-signed client certificate and key under dir.
+func newClientCert(t *testing.T, dir string) (certFile, keyFile string, cert *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "lazyflow"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	cert, _ = x509.ParseCertificate(der)
+	return certFile, keyFile, cert
+}
+
+func TestNewTransport_privateCAAndClientCert(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, clientCert := newClientCert(t, dir)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	srv.StartTLS()
+	defer srv.Close()
+	caFile := filepath.Join(dir, "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", srv.Certificate().Raw)
+
+	get := func(cfg TransportConfig) error {
+		rt, err := NewTransport(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := NewClient(ClientConfig{BaseURL: srv.URL, Token: "test", Transport: rt})
+		c.retries = 0
+		_, err = c.GetHealth(context.Background())
+		return err
+	}
+	if err := get(TransportConfig{CertFile: certFile, KeyFile: keyFile}); err == nil {
+		t.Error("server certificate from an unknown CA was accepted")
+	}
+	if err := get(TransportConfig{CAFile: caFile}); err == nil {
+		t.Error("request without a client certificate was accepted")
+	}
+	if err := get(TransportConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile}); err != nil {
+		t.Errorf("private CA + client certificate: %v", err)
+	}
+	if err := get(TransportConfig{InsecureSkipVerify: true, CertFile: certFile, KeyFile: keyFile}); err != nil {
+		t.Errorf("insecure_skip_verify: %v", err)
+	}
+}
+
+func TestNewTransport_rejectsBadConfig(t *testing.T) {
+	for name, cfg := range map[string]TransportConfig{
+		"missing ca":    {CAFile: "/nonexistent/ca.pem"},
+		"cert no key":   {CertFile: "client.crt"},
+		"proxy scheme":  {Proxy: "ftp://proxy:21"},
+		"proxy garbage": {Proxy: "http://[::1"},
+	} {
+		if _, err := NewTransport(cfg); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestClient_headersProxyAndPathPrefix(t *testing.T) {
+	var gotPath, gotGateway, gotAuth string
+	var viaProxy bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy sees the absolute URL in the request line.
+		viaProxy = r.URL.IsAbs()
+		gotPath = r.URL.Path
+		gotGateway = r.Header.Get("X-Gateway-Key")
+		gotAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	rt, err := NewTransport(TransportConfig{
+		Proxy:   srv.URL,
+		Headers: map[string]string{"X-Gateway-Key": "k1", "Authorization": "Basic nope"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewClient(ClientConfig{BaseURL: "http://airflow.internal/", PathPrefix: "/airflow/", Token: "test", Transport: rt})
+	if _, err := c.GetHealth(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !viaProxy || gotPath != "/airflow"+EndpointHealth {
+		t.Errorf("proxied=%v path=%q, want the prefixed path through the proxy", viaProxy, gotPath)
+	}
+	if gotGateway != "k1" || gotAuth != "Bearer test" {
+		t.Errorf("gateway=%q auth=%q: custom headers must not replace the client's own", gotGateway, gotAuth)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -13,19 +15,76 @@ type Config struct {
 	Airflow AirflowConfig `yaml:"airflow"`
 	UI      UIConfig      `yaml:"ui"`
 	Cache   CacheConfig   `yaml:"cache"`
+	// SLA declares per-DAG completion expectations, keyed by dag id.
+	SLA map[string]SLAConfig `yaml:"sla"`
 }
 
 type AirflowConfig struct {
-	BaseURL string     `yaml:"base_url"`
-	Timeout string     `yaml:"timeout"`
-	Auth    AuthConfig `yaml:"auth"`
+	BaseURL string `yaml:"base_url"`
+	// PathPrefix is the sub-path Airflow is served under ("/airflow").
+	PathPrefix     string               `yaml:"path_prefix"`
+	Timeout        string               `yaml:"timeout"`
+	Auth           AuthConfig           `yaml:"auth"`
+	TLS            TLSConfig            `yaml:"tls"`
+	Proxy          string               `yaml:"proxy"`   // URL; "" = HTTP(S)_PROXY env, "direct" = none
+	Headers        map[string]string    `yaml:"headers"` // sent with every request
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker"`
+	RateLimit      RateLimitConfig      `yaml:"rate_limit"`
+	// ReadOnly disables every action that changes Airflow state.
+	ReadOnly bool `yaml:"read_only"`
+	// Protected marks a production cluster: each action asks for the DAG id
+	// to be typed before it runs, and the header turns red.
+	Protected bool `yaml:"protected"`
 }
 
+// TLSConfig adds a private CA, an mTLS client certificate, or relaxes server
+// verification for talking to the Airflow API.
+type TLSConfig struct {
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+	ServerName         string `yaml:"server_name"`
+}
+
+// RateLimitConfig bounds the requests lazyflow sends: a token bucket of
+// Burst refilled at RequestsPerSecond, and at most MaxInFlight at once. A
+// negative value disables that limit.
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second"`
+	Burst             int     `yaml:"burst"`
+	MaxInFlight       int     `yaml:"max_in_flight"`
+}
+
+// CircuitBreakerConfig stops reads to an endpoint after Threshold straight
+// failures for Cooldown. A Threshold of 0 disables the breaker.
+type CircuitBreakerConfig struct {
+	Threshold int    `yaml:"threshold"`
+	Cooldown  string `yaml:"cooldown"`
+}
+
+// AuthConfig holds credentials. Password and Token may also come from a
+// command (first line of its output), a file, or an "enc:" value made by
+// `lazyflow encrypt`; LoadConfig resolves them into Password and Token.
 type AuthConfig struct {
-	Type     string `yaml:"type"` // "basic" or "token"
-	Username string `yaml:"username"`
-	Password string `yaml:"password"`
-	Token    string `yaml:"token"`
+	Type            string     `yaml:"type"` // "basic", "token", "exec" or "file"
+	Username        string     `yaml:"username"`
+	Password        string     `yaml:"password"`
+	PasswordCommand string     `yaml:"password_command"` // e.g. "pass show airflow/prod"
+	PasswordFile    string     `yaml:"password_file"`
+	Token           string     `yaml:"token"`
+	TokenCommand    string     `yaml:"token_command"`
+	TokenFile       string     `yaml:"token_file"` // type "file": re-read on every refresh
+	Exec            ExecConfig `yaml:"exec"`
+}
+
+// ExecConfig runs a credential helper that prints a token (type "exec"),
+// the way kubectl runs its exec plugins.
+type ExecConfig struct {
+	Command string            `yaml:"command"`
+	Args    []string          `yaml:"args"`
+	Env     map[string]string `yaml:"env"`
+	Timeout string            `yaml:"timeout"`
 }
 
 type CacheConfig struct {
@@ -36,12 +95,43 @@ type CacheConfig struct {
 	FallbackToMemory bool   `yaml:"fallback_to_memory"`
 }
 
+// SLAConfig is one DAG's expectations; any subset may be set.
+type SLAConfig struct {
+	FinishBy      string `yaml:"finish_by"`      // "HH:MM": a success must have ended by then each day
+	Timezone      string `yaml:"timezone"`       // IANA name for finish_by; default UTC
+	MaxDuration   string `yaml:"max_duration"`   // Go duration a run may take
+	SuccessWithin string `yaml:"success_within"` // Go duration since the last success
+}
+
 type UIConfig struct {
 	RefreshIntervals RefreshIntervals `yaml:"refresh_intervals"`
 	// RollupWindow is the lookback window for the cluster KPI bar and per-DAG
 	// cluster KPI rollup. Parsed by ParseDuration (Go duration; max unit "h", so
 	// 7 days = "168h").
 	RollupWindow string `yaml:"rollup_window"`
+	// Polling paces the refresh loops; see PollPolicy.
+	Polling PollingConfig `yaml:"polling"`
+}
+
+// PollingConfig is the YAML form of PollPolicy.
+type PollingConfig struct {
+	MaxBackoff      string  `yaml:"max_backoff"`       // cap on the backoff after failed polls
+	IdleAfter       string  `yaml:"idle_after"`        // no keypress for this long = idle; "0" never idles
+	IdleFactor      float64 `yaml:"idle_factor"`       // interval multiplier while idle
+	HiddenFactor    float64 `yaml:"hidden_factor"`     // multiplier for loops whose tab is hidden
+	ActiveRunFactor float64 `yaml:"active_run_factor"` // multiplier while the watched run is running
+}
+
+// PollPolicy converts the polling config, keeping defaults for unparsable
+// durations.
+func (c PollingConfig) PollPolicy() PollPolicy {
+	return PollPolicy{
+		MaxBackoff:   ParseDuration(c.MaxBackoff, time.Minute),
+		IdleAfter:    ParseDuration(c.IdleAfter, 5*time.Minute),
+		IdleFactor:   c.IdleFactor,
+		HiddenFactor: c.HiddenFactor,
+		ActiveFactor: c.ActiveRunFactor,
+	}
 }
 
 type RefreshIntervals struct {
@@ -61,6 +151,15 @@ func DefaultConfig() Config {
 			Auth: AuthConfig{
 				Type: "basic",
 			},
+			CircuitBreaker: CircuitBreakerConfig{
+				Threshold: 5,
+				Cooldown:  "30s",
+			},
+			RateLimit: RateLimitConfig{
+				RequestsPerSecond: 10,
+				Burst:             5,
+				MaxInFlight:       6,
+			},
 		},
 		UI: UIConfig{
 			RefreshIntervals: RefreshIntervals{
@@ -72,6 +171,13 @@ func DefaultConfig() Config {
 				Pools:  "10s",
 			},
 			RollupWindow: "168h", // 7 days
+			Polling: PollingConfig{
+				MaxBackoff:      "1m",
+				IdleAfter:       "5m",
+				IdleFactor:      4,
+				HiddenFactor:    3,
+				ActiveRunFactor: 0.5,
+			},
 		},
 		Cache: CacheConfig{
 			Enabled:          true,
@@ -94,13 +200,21 @@ func LoadConfig() (Config, error) {
 		if err != nil {
 			continue
 		}
-		if err := yaml.Unmarshal(data, &cfg); err != nil {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return cfg, fmt.Errorf("parse config %s: %w", p, err)
+		}
+		if err := expandEnv(&doc); err != nil {
+			return cfg, fmt.Errorf("config %s: %w", p, err)
+		}
+		if err := doc.Decode(&cfg); err != nil {
 			return cfg, fmt.Errorf("parse config %s: %w", p, err)
 		}
 		break
 	}
 
-	// Environment variable overrides
+	// Environment variable overrides. An overridden secret drops its
+	// command and file sources so they are not run for nothing.
 	if v := os.Getenv("AIRFLOW_BASE_URL"); v != "" {
 		cfg.Airflow.BaseURL = v
 	}
@@ -109,12 +223,21 @@ func LoadConfig() (Config, error) {
 	}
 	if v := os.Getenv("AIRFLOW_PASSWORD"); v != "" {
 		cfg.Airflow.Auth.Password = v
+		cfg.Airflow.Auth.PasswordCommand, cfg.Airflow.Auth.PasswordFile = "", ""
 	}
 	if v := os.Getenv("AIRFLOW_TOKEN"); v != "" {
 		cfg.Airflow.Auth.Token = v
+		cfg.Airflow.Auth.TokenCommand = ""
 		cfg.Airflow.Auth.Type = "token"
 	}
+	if v := os.Getenv("AIRFLOW_TOKEN_FILE"); v != "" {
+		cfg.Airflow.Auth.TokenFile = v
+		cfg.Airflow.Auth.Type = "file"
+	}
 
+	if err := cfg.Airflow.Auth.resolveSecrets(); err != nil {
+		return cfg, fmt.Errorf("auth: %w", err)
+	}
 	return cfg, nil
 }
 
@@ -140,3 +263,28 @@ func ParseDuration(s string, fallback time.Duration) time.Duration {
 	}
 	return d
 }
+
+// ParseTimeBound parses one end of a time range: empty means unbounded (the
+// zero time), a duration such as "168h" or "7d" means that long before now,
+// and otherwise an RFC 3339 timestamp or a YYYY-MM-DD date (UTC midnight).
+func ParseTimeBound(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: want a duration (168h, 7d), RFC 3339 or YYYY-MM-DD", s)
+}
@@ -0,0 +1,26 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"":                     {},
+		"6h":                   now.Add(-6 * time.Hour),
+		"7d":                   now.AddDate(0, 0, -7),
+		"2026-03-01":           time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		"2026-03-01T08:30:00Z": time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC),
+	}
+	for in, want := range cases {
+		got, err := ParseTimeBound(in, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("ParseTimeBound(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseTimeBound("last week", now); err == nil {
+		t.Error("expected an error for free text")
+	}
+}
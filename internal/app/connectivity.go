@@ -0,0 +1,102 @@
+package app
+
+import (
+	"sync"
+	"time"
+)
+
+// Connectivity decides whether the Airflow API is reachable from the outcome
+// of the calls the pollers make anyway. It goes offline after threshold
+// consecutive unreachable failures — one dropped request is not an outage —
+// and back online on the first success. Callers classify errors: anything
+// that proves the server answered (a 4xx, a decode error) should be reported
+// as a success for this purpose.
+type Connectivity struct {
+	mu        sync.Mutex
+	threshold int
+	failures  int
+	offline   bool
+	since     time.Time
+	lastErr   error
+	now       func() time.Time
+	onChange  func(offline bool)
+
+	// deliverMu serialises onChange; delivered is the state it last got.
+	deliverMu sync.Mutex
+	delivered bool
+}
+
+// NewConnectivity starts online. onChange runs on the reporting goroutine,
+// outside the state lock, each time the state flips. Calls never overlap and
+// always hand over the current state, so racing flips cannot leave the
+// listener with a stale one.
+func NewConnectivity(threshold int, onChange func(offline bool)) *Connectivity {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &Connectivity{threshold: threshold, now: time.Now, onChange: onChange}
+}
+
+// Success records a call that reached the API.
+func (c *Connectivity) Success() {
+	c.mu.Lock()
+	c.failures = 0
+	flipped := c.offline
+	c.offline = false
+	c.lastErr = nil
+	c.mu.Unlock()
+	if flipped {
+		c.notify()
+	}
+}
+
+// Failure records a call that could not reach the API.
+func (c *Connectivity) Failure(err error) {
+	c.mu.Lock()
+	c.failures++
+	c.lastErr = err
+	flipped := !c.offline && c.failures >= c.threshold
+	if flipped {
+		c.offline = true
+		c.since = c.now()
+	}
+	c.mu.Unlock()
+	if flipped {
+		c.notify()
+	}
+}
+
+// notify hands onChange the state as it is now, not the flip that called
+// it: when a Failure and a Success race, whichever delivers last reads the
+// final state, and one that finds it already delivered stays quiet.
+func (c *Connectivity) notify() {
+	if c.onChange == nil {
+		return
+	}
+	c.deliverMu.Lock()
+	defer c.deliverMu.Unlock()
+	offline := c.Offline()
+	if offline == c.delivered {
+		return
+	}
+	c.delivered = offline
+	c.onChange(offline)
+}
+
+// Offline reports whether the API is currently considered unreachable.
+func (c *Connectivity) Offline() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.offline
+}
+
+// Status returns when the current outage started and the latest error; both
+// are zero while online.
+func (c *Connectivity) Status() (since time.Time, lastErr error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.offline {
+		return time.Time{}, nil
+	}
+	return c.since, c.lastErr
+}
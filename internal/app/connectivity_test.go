@@ -0,0 +1,62 @@
+package app
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestConnectivity_flipsAfterThreshold(t *testing.T) {
+	var changes []bool
+	c := NewConnectivity(3, func(offline bool) { changes = append(changes, offline) })
+	down := errors.New("connection refused")
+
+	c.Failure(down)
+	c.Failure(down)
+	c.Success() // a success resets the streak
+	c.Failure(down)
+	c.Failure(down)
+	if c.Offline() {
+		t.Fatal("offline before threshold consecutive failures")
+	}
+	c.Failure(down)
+	if !c.Offline() {
+		t.Fatal("still online after threshold failures")
+	}
+	if since, err := c.Status(); since.IsZero() || err != down {
+		t.Fatalf("Status = %v, %v", since, err)
+	}
+	c.Failure(down) // already offline: no second notification
+	c.Success()
+	if c.Offline() {
+		t.Fatal("first success should bring it back online")
+	}
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Fatalf("changes = %v, want [true false]", changes)
+	}
+}
+
+func TestConnectivity_racingFlipsEndOnTheFinalState(t *testing.T) {
+	var mu sync.Mutex
+	listener := false
+	c := NewConnectivity(1, func(offline bool) {
+		mu.Lock()
+		listener = offline
+		mu.Unlock()
+	})
+	down := errors.New("connection refused")
+
+	for range 200 {
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() { defer wg.Done(); c.Failure(down) }()
+		go func() { defer wg.Done(); c.Success() }()
+		wg.Wait()
+		mu.Lock()
+		got := listener
+		mu.Unlock()
+		if got != c.Offline() {
+			t.Fatalf("listener offline=%v, connectivity offline=%v", got, c.Offline())
+		}
+	}
+}
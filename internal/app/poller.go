@@ -2,70 +2,165 @@ package app
 
 import (
 	"context"
+	"errors"
 	"math/rand"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/yjinheon/lazyflow/internal/debugutil"
 )
 
+// PollFunc is one poll. A non-nil error backs the loop off; an error with a
+// RetryAfter() time.Duration method (an open circuit, a 429) sets the
+// minimum wait. context.Canceled is not counted as a failure.
+type PollFunc func(ctx context.Context) error
+
+// PollPolicy tunes how poll loops pace themselves. A factor of 0 or 1
+// disables that adjustment.
+type PollPolicy struct {
+	// MaxBackoff caps the exponential backoff after consecutive failures.
+	MaxBackoff time.Duration
+	// IdleAfter is how long without a keypress before the terminal counts
+	// as idle; 0 never idles.
+	IdleAfter  time.Duration
+	IdleFactor float64
+	// HiddenFactor stretches loops whose tabs are not on screen.
+	HiddenFactor float64
+	// ActiveFactor scales loops while their BoostWhen condition holds (a
+	// watched run still running); below 1 polls faster.
+	ActiveFactor float64
+}
+
+// minPollInterval keeps a boosted loop from spinning.
+const minPollInterval = 500 * time.Millisecond
+
+// PollOption adjusts a single loop.
+type PollOption func(*pollLoop)
+
+// ForTabs marks a loop as feeding only these tabs; it slows down by
+// HiddenFactor while none of them is active.
+func ForTabs(tabs ...string) PollOption {
+	return func(l *pollLoop) { l.tabs = tabs }
+}
+
+// BoostWhen speeds a loop up by ActiveFactor while cond returns true.
+func BoostWhen(cond func() bool) PollOption {
+	return func(l *pollLoop) { l.boost = cond }
+}
+
 // Poller manages periodic data fetching with context-based lifecycle.
+// Loops back off exponentially (with jitter) while their poll fails and pace
+// themselves to what the user is looking at: hidden tabs and an idle
+// terminal poll less, a watched run that is still going polls more.
 type Poller struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	// mu protects subCancels. Restart is normally called from the tview main
-	// goroutine, but defending the map costs nothing and prevents a future
-	// caller from introducing a silent race.
+	// mu protects subCancels, policy, activeTab and wake. Restart is
+	// normally called from the tview main goroutine, but defending the map
+	// costs nothing and prevents a future caller from introducing a silent
+	// race.
 	mu         sync.Mutex
 	subCancels map[string]context.CancelFunc
+	policy     PollPolicy
+	activeTab  string
+	wake       chan struct{} // closed and replaced to reschedule every loop
+
+	lastActivity atomic.Int64 // unix nanos of the last keypress
+	idle         atomic.Bool
 }
 
 func NewPoller(parent context.Context) *Poller {
 	ctx, cancel := context.WithCancel(parent)
-	return &Poller{
+	p := &Poller{
 		ctx:        ctx,
 		cancel:     cancel,
 		subCancels: make(map[string]context.CancelFunc),
+		policy:     PollPolicy{MaxBackoff: time.Minute}, // backoff only; the TUI adds pacing
+		wake:       make(chan struct{}),
 	}
+	p.lastActivity.Store(time.Now().UnixNano())
+	return p
+}
+
+// SetPolicy replaces the pacing policy; running loops pick it up at once.
+func (p *Poller) SetPolicy(pol PollPolicy) {
+	p.mu.Lock()
+	p.policy = pol
+	p.mu.Unlock()
+	p.reschedule()
+}
+
+// Touch records user activity. Coming back from idle reschedules every loop
+// so the screen catches up straight away.
+func (p *Poller) Touch() {
+	p.lastActivity.Store(time.Now().UnixNano())
+	if p.idle.Swap(false) {
+		debugutil.Tag("FZ-poll", "activity after idle, rescheduling")
+		p.reschedule()
+	}
+}
+
+// SetActiveTab tells the poller which tab is on screen. Until it is called
+// every loop counts as visible.
+func (p *Poller) SetActiveTab(tab string) {
+	p.mu.Lock()
+	changed := p.activeTab != tab
+	p.activeTab = tab
+	p.mu.Unlock()
+	if changed {
+		p.reschedule()
+	}
+}
+
+// reschedule wakes every loop to recompute when its next poll is due.
+func (p *Poller) reschedule() {
+	p.mu.Lock()
+	close(p.wake)
+	p.wake = make(chan struct{})
+	p.mu.Unlock()
+}
+
+func (p *Poller) wakeChan() <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.wake
+}
+
+// pollLoop is the per-loop scheduling state; only its goroutine touches it.
+type pollLoop struct {
+	name     string
+	base     time.Duration
+	tabs     []string
+	boost    func() bool
+	failures int
+	backoff  time.Duration // non-zero while failing
 }
 
 // Fixed starts a polling loop that runs for the lifetime of the poller.
-func (p *Poller) Fixed(interval time.Duration, immediate bool, fn func(ctx context.Context)) {
+func (p *Poller) Fixed(interval time.Duration, immediate bool, fn PollFunc, opts ...PollOption) {
+	l := &pollLoop{name: "fixed", base: interval}
+	for _, o := range opts {
+		o(l)
+	}
 	go func() {
 		// ±15% jitter on first tick to avoid startup thundering-herd
 		// when multiple Fixed pollers spin up together.
 		jitter := time.Duration(float64(interval) * 0.15 * (rand.Float64()*2 - 1))
 		debugutil.Tag("FZ-poll", "Fixed loop START interval=%v immediate=%v jitter=%v", interval, immediate, jitter)
 		if immediate {
-			tStart := time.Now()
-			fn(p.ctx)
-			debugutil.Tag("FZ-poll", "Fixed immediate fn END elapsed=%v", time.Since(tStart))
-		}
-		if jitter > 0 {
-			time.Sleep(jitter)
-		}
-		ticker := time.NewTicker(interval)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-p.ctx.Done():
-				debugutil.Tag("FZ-poll", "Fixed loop EXIT")
-				return
-			case <-ticker.C:
-				tStart := time.Now()
-				fn(p.ctx)
-				if d := time.Since(tStart); d > 500*time.Millisecond {
-					debugutil.Tag("FZ-poll", "Fixed fn slow elapsed=%v interval=%v", d, interval)
-				}
-			}
+			p.tick(p.ctx, l, fn)
 		}
+		p.loop(p.ctx, l, fn, max(jitter, 0))
+		debugutil.Tag("FZ-poll", "Fixed loop EXIT")
 	}()
 }
 
 // Restart cancels any existing sub-poller with the given name and starts a new one.
 // Use this for polls that change target when a selection changes (e.g. runs for a DAG).
-func (p *Poller) Restart(name string, interval time.Duration, fn func(ctx context.Context)) {
+func (p *Poller) Restart(name string, interval time.Duration, fn PollFunc, opts ...PollOption) {
 	debugutil.Tag("FZ-poll", "Restart name=%s interval=%v", name, interval)
 
 	p.mu.Lock()
@@ -77,26 +172,106 @@ func (p *Poller) Restart(name string, interval time.Duration, fn func(ctx contex
 	p.subCancels[name] = subCancel
 	p.mu.Unlock()
 
+	l := &pollLoop{name: name, base: interval}
+	for _, o := range opts {
+		o(l)
+	}
 	go func() {
 		debugutil.Tag("FZ-poll", "sub-poller %s START", name)
-		ticker := time.NewTicker(interval)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-subCtx.Done():
-				debugutil.Tag("FZ-poll", "sub-poller %s EXIT", name)
-				return
-			case <-ticker.C:
-				tStart := time.Now()
-				fn(subCtx)
-				if d := time.Since(tStart); d > 500*time.Millisecond {
-					debugutil.Tag("FZ-poll", "sub-poller %s fn slow elapsed=%v", name, d)
-				}
-			}
-		}
+		p.loop(subCtx, l, fn, 0)
+		debugutil.Tag("FZ-poll", "sub-poller %s EXIT", name)
 	}()
 }
 
+// loop polls until ctx ends. Each wait is recomputed when the poller is
+// rescheduled, measured from the previous poll, so a loop that was slowed
+// down catches up immediately once its tab is shown again.
+func (p *Poller) loop(ctx context.Context, l *pollLoop, fn PollFunc, extra time.Duration) {
+	last := time.Now()
+	timer := time.NewTimer(p.next(l) + extra)
+	defer timer.Stop()
+	for {
+		wake := p.wakeChan()
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+			timer.Reset(max(time.Until(last.Add(p.next(l))), 0))
+		case <-timer.C:
+			p.tick(ctx, l, fn)
+			last = time.Now()
+			timer.Reset(p.next(l))
+		}
+	}
+}
+
+func (p *Poller) tick(ctx context.Context, l *pollLoop, fn PollFunc) {
+	tStart := time.Now()
+	err := fn(ctx)
+	if d := time.Since(tStart); d > 500*time.Millisecond {
+		debugutil.Tag("FZ-poll", "%s fn slow elapsed=%v interval=%v", l.name, d, l.base)
+	}
+	switch {
+	case err == nil:
+		l.failures, l.backoff = 0, 0
+	case errors.Is(err, context.Canceled):
+	default:
+		l.failures++
+		p.mu.Lock()
+		ceiling := p.policy.MaxBackoff
+		p.mu.Unlock()
+		l.backoff = backoff(l.base, l.failures, ceiling)
+		var ra interface{ RetryAfter() time.Duration }
+		if errors.As(err, &ra) && ra.RetryAfter() > l.backoff {
+			l.backoff = ra.RetryAfter()
+		}
+		debugutil.Tag("FZ-poll", "%s failed (%d in a row), next in %v: %v", l.name, l.failures, l.backoff, err)
+	}
+}
+
+// next is the wait before l's next poll under the current conditions.
+func (p *Poller) next(l *pollLoop) time.Duration {
+	if l.backoff > 0 {
+		return l.backoff
+	}
+	p.mu.Lock()
+	pol, tab := p.policy, p.activeTab
+	p.mu.Unlock()
+
+	factor := 1.0
+	if len(l.tabs) > 0 && tab != "" && !slices.Contains(l.tabs, tab) {
+		factor *= scale(pol.HiddenFactor)
+	}
+	if pol.IdleAfter > 0 && time.Since(time.Unix(0, p.lastActivity.Load())) >= pol.IdleAfter {
+		p.idle.Store(true)
+		factor *= scale(pol.IdleFactor)
+	} else if l.boost != nil && l.boost() {
+		factor *= scale(pol.ActiveFactor)
+	}
+	return max(time.Duration(float64(l.base)*factor), minPollInterval)
+}
+
+// backoff doubles base for each consecutive failure up to ceiling, then
+// picks a random point in the upper half so loops that failed together
+// do not retry together.
+func backoff(base time.Duration, failures int, ceiling time.Duration) time.Duration {
+	d := base
+	for i := 0; i < failures && (ceiling <= 0 || d < ceiling); i++ {
+		d *= 2
+	}
+	if ceiling > 0 && d > ceiling {
+		d = ceiling
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func scale(f float64) float64 {
+	if f <= 0 {
+		return 1
+	}
+	return f
+}
+
 // Stop cancels all polling.
 func (p *Poller) Stop() {
 	debugutil.Tag("FZ-poll", "Stop")
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoff_doublesWithJitterUpToCeiling(t *testing.T) {
+	base := time.Second
+	for failures, want := range map[int]time.Duration{1: 2 * time.Second, 3: 8 * time.Second, 10: time.Minute} {
+		for i := 0; i < 50; i++ {
+			got := backoff(base, failures, time.Minute)
+			if got < want/2 || got > want {
+				t.Fatalf("backoff(%d failures) = %v, want within [%v, %v]", failures, got, want/2, want)
+			}
+		}
+	}
+}
+
+type retryAfterErr struct{ d time.Duration }
+
+func (e retryAfterErr) Error() string             { return "circuit open" }
+func (e retryAfterErr) RetryAfter() time.Duration { return e.d }
+
+func TestPollerTick_backsOffAndRecovers(t *testing.T) {
+	p := NewPoller(t.Context())
+	l := &pollLoop{name: "dags", base: time.Second}
+
+	p.tick(t.Context(), l, func(context.Context) error { return errors.New("boom") })
+	if l.failures != 1 || l.backoff < time.Second || l.backoff > 2*time.Second {
+		t.Fatalf("after one failure: failures=%d backoff=%v", l.failures, l.backoff)
+	}
+	if got := p.next(l); got != l.backoff {
+		t.Fatalf("next while failing = %v, want the backoff %v", got, l.backoff)
+	}
+
+	p.tick(t.Context(), l, func(context.Context) error { return retryAfterErr{45 * time.Second} })
+	if l.backoff != 45*time.Second {
+		t.Fatalf("RetryAfter not honoured: backoff=%v", l.backoff)
+	}
+
+	p.tick(t.Context(), l, func(context.Context) error { return context.Canceled })
+	if l.failures != 2 {
+		t.Fatalf("a cancelled poll changed the failure count to %d", l.failures)
+	}
+
+	p.tick(t.Context(), l, func(context.Context) error { return nil })
+	if l.failures != 0 || p.next(l) != time.Second {
+		t.Fatalf("after success: failures=%d next=%v", l.failures, p.next(l))
+	}
+}
+
+func TestPollerNext_pacing(t *testing.T) {
+	p := NewPoller(t.Context())
+	p.SetPolicy(PollPolicy{IdleAfter: time.Minute, IdleFactor: 4, HiddenFactor: 3, ActiveFactor: 0.5})
+	active := false
+	l := &pollLoop{base: 2 * time.Second, tabs: []string{"tasks"}, boost: func() bool { return active }}
+
+	if got := p.next(l); got != 2*time.Second {
+		t.Fatalf("before any tab is known = %v, want the base interval", got)
+	}
+	p.SetActiveTab("runs")
+	if got := p.next(l); got != 6*time.Second {
+		t.Fatalf("hidden = %v, want 6s", got)
+	}
+	p.SetActiveTab("tasks")
+	active = true
+	if got := p.next(l); got != time.Second {
+		t.Fatalf("boosted = %v, want 1s", got)
+	}
+
+	p.lastActivity.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+	if got := p.next(l); got != 8*time.Second {
+		t.Fatalf("idle = %v, want 8s (idle outranks the boost)", got)
+	}
+	p.Touch()
+	if got := p.next(l); got != time.Second {
+		t.Fatalf("after a keypress = %v, want 1s", got)
+	}
+}
+
+// Showing a slowed loop's tab again must poll at once rather than sitting out
+// the stretched wait.
+func TestPoller_rescheduleOnTabChange(t *testing.T) {
+	p := NewPoller(t.Context())
+	defer p.Stop()
+	p.SetPolicy(PollPolicy{HiddenFactor: 1000})
+	p.SetActiveTab("runs")
+
+	var polls atomic.Int32
+	p.Restart("tasks", 50*time.Millisecond, func(context.Context) error {
+		polls.Add(1)
+		return nil
+	}, ForTabs("tasks"))
+
+	time.Sleep(150 * time.Millisecond)
+	if got := polls.Load(); got != 0 {
+		t.Fatalf("hidden loop polled %d times", got)
+	}
+	p.SetActiveTab("tasks")
+	deadline := time.Now().Add(2 * time.Second)
+	for polls.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if polls.Load() == 0 {
+		t.Fatal("loop did not catch up after its tab was shown")
+	}
+}
@@ -0,0 +1,259 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envRef matches ${NAME} and ${NAME:-default} in config values. A bare $NAME
+// is left alone so passwords containing '$' survive.
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// expandEnv replaces ${NAME} references in every scalar value of the parsed
+// YAML, before it is decoded into Config. Expanding the parsed values rather
+// than the file text keeps a variable holding quotes or newlines from
+// changing the document's structure.
+func expandEnv(n *yaml.Node) error {
+	var missing []string
+	var walk func(*yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Kind == yaml.ScalarNode && strings.Contains(n.Value, "${") {
+			n.Value = envRef.ReplaceAllStringFunc(n.Value, func(ref string) string {
+				m := envRef.FindStringSubmatch(ref)
+				if v, ok := os.LookupEnv(m[1]); ok && v != "" {
+					return v
+				}
+				if strings.Contains(ref, ":-") {
+					return m[2]
+				}
+				missing = append(missing, m[1])
+				return ""
+			})
+		}
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(n)
+	if len(missing) > 0 {
+		return fmt.Errorf("unset environment variable(s) %s (use ${NAME:-default} for optional ones)", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// secretCommandTimeout bounds password_command/token_command; a password
+// manager may prompt for its own unlock.
+const secretCommandTimeout = time.Minute
+
+// resolveSecrets fills Password and Token from their _command and _file
+// sources, and decrypts "enc:" values. A source that is not configured, or
+// that the auth type does not use, is never touched, so a failing password
+// manager only matters when it is actually used.
+func (a *AuthConfig) resolveSecrets() error {
+	var err error
+	if a.Type == "" || a.Type == "basic" {
+		if a.Password, err = resolveSecret("password", a.Password, a.PasswordCommand, a.PasswordFile); err != nil {
+			return err
+		}
+	}
+	if a.Type == "" || a.Type == "token" {
+		if a.Token, err = resolveSecret("token", a.Token, a.TokenCommand, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resolveSecret(name, value, command, file string) (string, error) {
+	switch {
+	case command != "":
+		out, err := runSecretCommand(command)
+		if err != nil {
+			return "", fmt.Errorf("%s_command: %w", name, err)
+		}
+		return out, nil
+	case file != "":
+		data, err := os.ReadFile(ExpandHome(file))
+		if err != nil {
+			return "", fmt.Errorf("%s_file: %w", name, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case IsEncrypted(value):
+		plain, err := DecryptSecret(value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", name, err)
+		}
+		return plain, nil
+	}
+	return value, nil
+}
+
+// runSecretCommand runs command through the shell and returns the first line
+// it prints, the convention of `pass show` and most password managers.
+func runSecretCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretCommandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	line, _, _ := strings.Cut(stdout.String(), "\n")
+	line = strings.TrimRight(line, "\r")
+	if line == "" {
+		return "", errors.New("printed nothing")
+	}
+	return line, nil
+}
+
+// Encrypted config values look like "enc:v1:<base64 salt|nonce|ciphertext>",
+// sealed with AES-256-GCM under a key derived (PBKDF2-SHA256) from the
+// passphrase in LAZYFLOW_SECRET_KEY or, failing that, the secret key file.
+// The config can then be shared or committed while the key stays on the
+// machine (or in a CI secret).
+const (
+	encPrefix     = "enc:v1:"
+	saltSize      = 16
+	kdfIterations = 600_000
+)
+
+// SecretKeyEnv names the variable holding the passphrase for encrypted
+// values; it takes precedence over the key file.
+const SecretKeyEnv = "LAZYFLOW_SECRET_KEY"
+
+// IsEncrypted reports whether a config value was produced by EncryptSecret.
+func IsEncrypted(v string) bool { return strings.HasPrefix(v, encPrefix) }
+
+// SecretKeyPath is where the generated passphrase lives when
+// LAZYFLOW_SECRET_KEY is not set, next to the user config file.
+func SecretKeyPath() string {
+	return ExpandHome("~/.config/lazyflow/secret.key")
+}
+
+// EncryptSecret seals plain for the config file. Without LAZYFLOW_SECRET_KEY
+// it creates the key file (mode 0600) on first use.
+func EncryptSecret(plain string) (string, error) {
+	pass, err := secretPassphrase(true)
+	if err != nil {
+		return "", err
+	}
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	aead, err := secretAEAD(pass, salt)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(append(salt, nonce...), nonce, []byte(plain), nil)
+	return encPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret opens a value produced by EncryptSecret.
+func DecryptSecret(v string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(v, encPrefix))
+	if err != nil {
+		return "", fmt.Errorf("decode encrypted value: %w", err)
+	}
+	pass, err := secretPassphrase(false)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) < saltSize {
+		return "", errors.New("encrypted value is truncated")
+	}
+	aead, err := secretAEAD(pass, raw[:saltSize])
+	if err != nil {
+		return "", err
+	}
+	rest := raw[saltSize:]
+	if len(rest) < aead.NonceSize() {
+		return "", errors.New("encrypted value is truncated")
+	}
+	plain, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("cannot decrypt value: wrong secret key?")
+	}
+	return string(plain), nil
+}
+
+func secretAEAD(pass, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, string(pass), salt, kdfIterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// secretPassphrase reads LAZYFLOW_SECRET_KEY or the key file, generating the
+// file when create is set and it does not exist yet.
+func secretPassphrase(create bool) ([]byte, error) {
+	if v := os.Getenv(SecretKeyEnv); v != "" {
+		return []byte(v), nil
+	}
+	path := SecretKeyPath()
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return bytes.TrimSpace(data), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) || !create {
+		return nil, fmt.Errorf("secret key: %w (set %s or run `lazyflow encrypt` on this machine)", err, SecretKeyEnv)
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	pass := []byte(base64.StdEncoding.EncodeToString(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("secret key: %w", err)
+	}
+	if err := os.WriteFile(path, append(pass, '\n'), 0o600); err != nil {
+		return nil, fmt.Errorf("secret key: %w", err)
+	}
+	return pass, nil
+}
+
+// ExpandHome resolves a leading "~/" against the user's home directory.
+func ExpandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
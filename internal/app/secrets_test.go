@@ -0,0 +1,102 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("LF_TEST_PW", `pa"ss: word`)
+	t.Setenv("LF_TEST_EMPTY", "")
+	src := "airflow:\n" +
+		"  base_url: 'https://${LF_TEST_HOST:-localhost}:8080'\n" +
+		"  auth:\n" +
+		"    password: '${LF_TEST_PW}'\n" +
+		"    token: '$literal'\n"
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := expandEnv(&doc); err != nil {
+		t.Fatal(err)
+	}
+	var cfg Config
+	if err := doc.Decode(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Airflow.BaseURL != "https://localhost:8080" {
+		t.Errorf("default not applied: %q", cfg.Airflow.BaseURL)
+	}
+	if cfg.Airflow.Auth.Password != `pa"ss: word` {
+		t.Errorf("password = %q", cfg.Airflow.Auth.Password)
+	}
+	if cfg.Airflow.Auth.Token != "$literal" {
+		t.Errorf("bare $ was expanded: %q", cfg.Airflow.Auth.Token)
+	}
+
+	if err := yaml.Unmarshal([]byte("a: '${LF_TEST_EMPTY}'"), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := expandEnv(&doc); err == nil || !strings.Contains(err.Error(), "LF_TEST_EMPTY") {
+		t.Errorf("unset variable: err = %v", err)
+	}
+}
+
+func TestResolveSecrets(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	pwFile := filepath.Join(t.TempDir(), "pw")
+	if err := os.WriteFile(pwFile, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	a := AuthConfig{Password: "plain", PasswordFile: pwFile, TokenCommand: "printf 'tok\\nmetadata: x\\n'"}
+	if err := a.resolveSecrets(); err != nil {
+		t.Fatal(err)
+	}
+	if a.Password != "from-file" || a.Token != "tok" {
+		t.Errorf("resolved password=%q token=%q", a.Password, a.Token)
+	}
+
+	a = AuthConfig{PasswordCommand: "echo locked >&2; exit 3"}
+	if err := a.resolveSecrets(); err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Errorf("failing command: err = %v, want its stderr", err)
+	}
+
+	// Token auth, e.g. from AIRFLOW_TOKEN, never runs the password command.
+	a = AuthConfig{Type: "token", Token: "tok", PasswordCommand: "echo locked >&2; exit 3"}
+	if err := a.resolveSecrets(); err != nil || a.Token != "tok" {
+		t.Errorf("token auth: token=%q err = %v, want the password source skipped", a.Token, err)
+	}
+}
+
+func TestEncryptSecret(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(SecretKeyEnv, "")
+
+	enc, err := EncryptSecret("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(enc) || strings.Contains(enc, "s3cret") {
+		t.Fatalf("EncryptSecret = %q", enc)
+	}
+	if st, err := os.Stat(SecretKeyPath()); err != nil || st.Mode().Perm() != 0o600 {
+		t.Fatalf("key file: %v %v", st, err)
+	}
+	a := AuthConfig{Password: enc}
+	if err := a.resolveSecrets(); err != nil || a.Password != "s3cret" {
+		t.Fatalf("decrypt: %q, %v", a.Password, err)
+	}
+
+	t.Setenv(SecretKeyEnv, "another key")
+	if _, err := DecryptSecret(enc); err == nil {
+		t.Fatal("decrypted with the wrong key")
+	}
+}
@@ -0,0 +1,23 @@
+package app
+
+import (
+	"sort"
+
+	"github.com/yjinheon/lazyflow/internal/metrics"
+)
+
+// ParseSLAs turns the sla config section into evaluable rules, sorted by dag
+// id. Invalid entries are skipped and reported in errs so one typo does not
+// disable tracking for every other DAG.
+func ParseSLAs(cfg map[string]SLAConfig) (slas []metrics.SLA, errs []error) {
+	for dagId, c := range cfg {
+		s, err := metrics.ParseSLA(dagId, c.FinishBy, c.Timezone, c.MaxDuration, c.SuccessWithin)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		slas = append(slas, s)
+	}
+	sort.Slice(slas, func(i, j int) bool { return slas[i].DagId < slas[j].DagId })
+	return slas, errs
+}
@@ -24,6 +24,76 @@ type DagDashboardRow struct {
 	RetriedTasks int
 }
 
+// TriggerConf is one conf payload previously submitted when triggering a DAG.
+// Conf is canonical JSON (sorted keys, no insignificant whitespace) so that
+// resubmitting an equivalent payload bumps Uses instead of adding a row.
+type TriggerConf struct {
+	Conf     string
+	Uses     int
+	LastUsed time.Time
+}
+
+// Action names recorded in the audit trail.
+const (
+	ActionTrigger         = "trigger"
+	ActionPauseDAG        = "pause"
+	ActionUnpauseDAG      = "unpause"
+	ActionCreateBackfill  = "backfill"
+	ActionPauseBackfill   = "backfill_pause"
+	ActionUnpauseBackfill = "backfill_unpause"
+	ActionCancelBackfill  = "backfill_cancel"
+	ActionRunNote         = "run_note"
+	ActionTaskNote        = "task_note"
+)
+
+// inverseActions maps each reversible action to the one that undoes it.
+// Triggers, backfill creation and cancellation cannot be taken back, and a
+// note's previous text is not kept.
+var inverseActions = map[string]string{
+	ActionPauseDAG:        ActionUnpauseDAG,
+	ActionUnpauseDAG:      ActionPauseDAG,
+	ActionPauseBackfill:   ActionUnpauseBackfill,
+	ActionUnpauseBackfill: ActionPauseBackfill,
+}
+
+// ActionRecord is one state-changing request lazyflow sent to Airflow. Target
+// is what the action applies to beyond the DAG (a run id, a backfill id);
+// Request is the JSON body sent, if any; Error is empty when it succeeded.
+// RevertOf is the ID of the action this one undid.
+type ActionRecord struct {
+	ID       int64
+	At       time.Time
+	User     string
+	Server   string
+	Action   string
+	DagId    string
+	Target   string
+	Request  string
+	Error    string
+	RevertOf int64
+}
+
+// Inverse returns the action that undoes a, if a succeeded and can be undone.
+func (a ActionRecord) Inverse() (string, bool) {
+	if a.Error != "" {
+		return "", false
+	}
+	inv, ok := inverseActions[a.Action]
+	return inv, ok
+}
+
+// RevertedIDs returns the IDs of the actions in recs that a later record
+// reverted.
+func RevertedIDs(recs []ActionRecord) map[int64]bool {
+	out := make(map[int64]bool)
+	for _, r := range recs {
+		if r.RevertOf != 0 && r.Error == "" {
+			out[r.RevertOf] = true
+		}
+	}
+	return out
+}
+
 // Options configures persistent cache implementations.
 type Options struct {
 	Retention   time.Duration
@@ -49,5 +119,25 @@ type Cache interface {
 	GetTaskInstancesHistory(dagId string, since time.Time, limit int) ([]models.TaskInstance, bool)
 
 	GetDagDashboardRows(since time.Time, limit int) ([]DagDashboardRow, bool)
+
+	PutTriggerConf(dagId, conf string)
+	GetTriggerConfs(dagId string, limit int) ([]TriggerConf, bool)
+
+	// DAG metadata, kept so the UI stays browseable while the API is down.
+	// PutDAGs replaces the whole list; the others are per DAG.
+	PutDAGs(dags []models.DAG)
+	GetDAGs() ([]models.DAG, bool)
+	PutTasks(dagId string, tasks []models.Task)
+	GetTasks(dagId string) ([]models.Task, bool)
+	PutDAGSource(dagId, source string)
+	GetDAGSource(dagId string) (string, bool)
+
+	// Audit trail of the actions taken from this machine. Unlike Put,
+	// RecordAction writes synchronously so a record is never dropped under
+	// write pressure; call it off the UI goroutine. GetActions returns the
+	// newest first.
+	RecordAction(a ActionRecord) (int64, error)
+	GetActions(limit int) ([]ActionRecord, bool)
+
 	Close() error
 }
@@ -1,6 +1,8 @@
 package cache
 
 import (
+	"encoding/json"
+	"fmt"
 	"sort"
 	"time"
 
@@ -24,12 +26,16 @@ func mergeDAGRuns(existing, incoming []models.DAGRun) []models.DAGRun {
 }
 
 func mergeTaskInstances(existing, incoming []models.TaskInstance) []models.TaskInstance {
+	// A mapped task's instances share a task id and differ in map index.
+	key := func(ti models.TaskInstance) string {
+		return fmt.Sprintf("%s/%s/%s/%d", ti.DagId, ti.RunId, ti.TaskId, ti.MapIndex)
+	}
 	byKey := make(map[string]models.TaskInstance, len(existing)+len(incoming))
 	for _, ti := range existing {
-		byKey[ti.DagId+"/"+ti.RunId+"/"+ti.TaskId] = ti
+		byKey[key(ti)] = ti
 	}
 	for _, ti := range incoming {
-		byKey[ti.DagId+"/"+ti.RunId+"/"+ti.TaskId] = ti
+		byKey[key(ti)] = ti
 	}
 	out := make([]models.TaskInstance, 0, len(byKey))
 	for _, ti := range byKey {
@@ -207,3 +213,27 @@ func queueDuration(ti models.TaskInstance) time.Duration {
 	}
 	return ti.StartDate.Sub(*ti.QueuedDttm)
 }
+
+// canonicalConf normalizes a trigger conf to compact JSON with sorted keys.
+// ok is false for empty, "{}", or invalid JSON — none of which is worth
+// remembering.
+func canonicalConf(conf string) (string, bool) {
+	var v any
+	if err := json.Unmarshal([]byte(conf), &v); err != nil {
+		return "", false
+	}
+	if m, isMap := v.(map[string]any); v == nil || (isMap && len(m) == 0) {
+		return "", false
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+	return string(out), true
+}
+
+func sortTriggerConfs(confs []TriggerConf) {
+	sort.Slice(confs, func(i, j int) bool {
+		return confs[i].LastUsed.After(confs[j].LastUsed)
+	})
+}
@@ -19,6 +19,11 @@ type memoryCache struct {
 	backfills     map[string]cachedBackfills
 	dagRuns       map[string][]models.DAGRun
 	taskInstances map[string][]models.TaskInstance
+	triggerConfs  map[string][]TriggerConf
+	dags          []models.DAG
+	tasks         map[string][]models.Task
+	sources       map[string]string
+	actions       []ActionRecord // oldest first
 }
 
 func NewMemory(ttl time.Duration) Cache {
@@ -27,6 +32,9 @@ func NewMemory(ttl time.Duration) Cache {
 		backfills:     make(map[string]cachedBackfills),
 		dagRuns:       make(map[string][]models.DAGRun),
 		taskInstances: make(map[string][]models.TaskInstance),
+		triggerConfs:  make(map[string][]TriggerConf),
+		tasks:         make(map[string][]models.Task),
+		sources:       make(map[string]string),
 	}
 }
 
@@ -112,4 +120,100 @@ func (m *memoryCache) GetDagDashboardRows(since time.Time, limit int) ([]DagDash
 	return rows, len(rows) > 0
 }
 
+func (m *memoryCache) PutTriggerConf(dagId, conf string) {
+	canon, ok := canonicalConf(conf)
+	if !ok {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	confs := m.triggerConfs[dagId]
+	for i := range confs {
+		if confs[i].Conf == canon {
+			confs[i].Uses++
+			confs[i].LastUsed = time.Now()
+			return
+		}
+	}
+	m.triggerConfs[dagId] = append(confs, TriggerConf{Conf: canon, Uses: 1, LastUsed: time.Now()})
+}
+
+func (m *memoryCache) GetTriggerConfs(dagId string, limit int) ([]TriggerConf, bool) {
+	m.mu.RLock()
+	out := make([]TriggerConf, len(m.triggerConfs[dagId]))
+	copy(out, m.triggerConfs[dagId])
+	m.mu.RUnlock()
+	sortTriggerConfs(out)
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, len(out) > 0
+}
+
+func (m *memoryCache) PutDAGs(dags []models.DAG) {
+	dup := make([]models.DAG, len(dags))
+	copy(dup, dags)
+	m.mu.Lock()
+	m.dags = dup
+	m.mu.Unlock()
+}
+
+func (m *memoryCache) GetDAGs() ([]models.DAG, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]models.DAG, len(m.dags))
+	copy(out, m.dags)
+	return out, len(out) > 0
+}
+
+func (m *memoryCache) PutTasks(dagId string, tasks []models.Task) {
+	dup := make([]models.Task, len(tasks))
+	copy(dup, tasks)
+	m.mu.Lock()
+	m.tasks[dagId] = dup
+	m.mu.Unlock()
+}
+
+func (m *memoryCache) GetTasks(dagId string) ([]models.Task, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]models.Task, len(m.tasks[dagId]))
+	copy(out, m.tasks[dagId])
+	return out, len(out) > 0
+}
+
+func (m *memoryCache) PutDAGSource(dagId, source string) {
+	m.mu.Lock()
+	m.sources[dagId] = source
+	m.mu.Unlock()
+}
+
+func (m *memoryCache) GetDAGSource(dagId string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	source, ok := m.sources[dagId]
+	return source, ok
+}
+
+func (m *memoryCache) RecordAction(a ActionRecord) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a.ID = int64(len(m.actions) + 1)
+	m.actions = append(m.actions, a)
+	return a.ID, nil
+}
+
+func (m *memoryCache) GetActions(limit int) ([]ActionRecord, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]ActionRecord, 0, len(m.actions))
+	for i := len(m.actions) - 1; i >= 0; i-- {
+		if limit > 0 && len(out) == limit {
+			break
+		}
+		out = append(out, m.actions[i])
+	}
+	return out, len(out) > 0
+}
+
 func (m *memoryCache) Close() error { return nil }
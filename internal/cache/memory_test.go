@@ -85,3 +85,43 @@ func TestMemory_taskInstancesHistoryAndDashboard(t *testing.T) {
 		t.Fatalf("unexpected dashboard row: %+v", rows[0])
 	}
 }
+
+func TestMemory_triggerConfHistory(t *testing.T) {
+	c := NewMemory(time.Second)
+	c.PutTriggerConf("etl", `{"x": 1}`)
+	c.PutTriggerConf("etl", `{"y": 2}`)
+	c.PutTriggerConf("etl", `{ "x":1 }`)
+
+	got, ok := c.GetTriggerConfs("etl", 10)
+	if !ok || len(got) != 2 {
+		t.Fatalf("got=%+v ok=%v", got, ok)
+	}
+	if got[0].Conf != `{"x":1}` || got[0].Uses != 2 {
+		t.Fatalf("most recently used conf should come first: %+v", got)
+	}
+	if _, ok := c.GetTriggerConfs("other", 10); ok {
+		t.Fatal("expected miss for a DAG with no history")
+	}
+}
+
+func TestMemory_actions(t *testing.T) {
+	c := NewMemory(time.Second)
+	pauseID, _ := c.RecordAction(ActionRecord{Action: ActionPauseDAG, DagId: "etl"})
+	_, _ = c.RecordAction(ActionRecord{Action: ActionTrigger, DagId: "etl", Error: "boom"})
+	_, _ = c.RecordAction(ActionRecord{Action: ActionUnpauseDAG, DagId: "etl", RevertOf: pauseID})
+
+	got, ok := c.GetActions(2)
+	if !ok || len(got) != 2 || got[0].Action != ActionUnpauseDAG || got[1].Action != ActionTrigger {
+		t.Fatalf("want the two newest, newest first: %+v", got)
+	}
+	if inv, ok := got[0].Inverse(); !ok || inv != ActionPauseDAG {
+		t.Errorf("unpause inverse = %q, %v", inv, ok)
+	}
+	if _, ok := got[1].Inverse(); ok {
+		t.Error("a failed trigger should not be revertible")
+	}
+	all, _ := c.GetActions(0)
+	if reverted := RevertedIDs(all); !reverted[pauseID] || len(reverted) != 1 {
+		t.Errorf("reverted = %v, want only %d", reverted, pauseID)
+	}
+}
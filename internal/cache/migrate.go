@@ -0,0 +1,283 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrSchemaTooNew is returned when cache.db was written by a newer lazyflow.
+// The file is left untouched so the newer version keeps its history.
+var ErrSchemaTooNew = errors.New("cache schema is newer than this lazyflow")
+
+// migration is one forward schema step. Steps run in version order, each in
+// its own transaction together with its schema_version row, so a failed step
+// leaves the database at the previous version.
+//
+// Append new steps; never edit or renumber a released one. Statements should
+// tolerate objects that already exist (IF NOT EXISTS) because early builds
+// created some tables before they had a version of their own.
+type migration struct {
+	version int
+	name    string
+	up      string
+}
+
+var migrations = []migration{
+	{1, "initial history tables", `
+CREATE TABLE IF NOT EXISTS backfills (
+  dag_id TEXT NOT NULL,
+  id INTEGER NOT NULL,
+  state TEXT NOT NULL,
+  from_date TEXT,
+  to_date TEXT,
+  completed_runs INTEGER NOT NULL DEFAULT 0,
+  failed_runs INTEGER NOT NULL DEFAULT 0,
+  running_runs INTEGER NOT NULL DEFAULT 0,
+  total_runs INTEGER NOT NULL DEFAULT 0,
+  updated_at TEXT NOT NULL,
+  raw_json TEXT NOT NULL,
+  PRIMARY KEY (dag_id, id)
+);
+
+CREATE TABLE IF NOT EXISTS dag_runs (
+  dag_id TEXT NOT NULL,
+  run_id TEXT NOT NULL,
+  state TEXT NOT NULL,
+  logical_date TEXT,
+  run_after TEXT,
+  start_date TEXT,
+  end_date TEXT,
+  duration_ms INTEGER NOT NULL DEFAULT 0,
+  run_type TEXT,
+  note TEXT,
+  updated_at TEXT NOT NULL,
+  raw_json TEXT NOT NULL,
+  PRIMARY KEY (dag_id, run_id)
+);
+
+CREATE TABLE IF NOT EXISTS task_instances (
+  dag_id TEXT NOT NULL,
+  run_id TEXT NOT NULL,
+  task_id TEXT NOT NULL,
+  state TEXT NOT NULL,
+  start_date TEXT,
+  end_date TEXT,
+  queued_at TEXT,
+  duration_ms INTEGER NOT NULL DEFAULT 0,
+  queue_ms INTEGER NOT NULL DEFAULT 0,
+  try_number INTEGER NOT NULL DEFAULT 0,
+  operator TEXT,
+  pool TEXT,
+  queue TEXT,
+  hostname TEXT,
+  updated_at TEXT NOT NULL,
+  raw_json TEXT NOT NULL,
+  PRIMARY KEY (dag_id, run_id, task_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_dag_runs_dag_time
+  ON dag_runs (dag_id, run_after DESC);
+
+CREATE INDEX IF NOT EXISTS idx_dag_runs_time
+  ON dag_runs (run_after DESC);
+
+CREATE INDEX IF NOT EXISTS idx_task_instances_dag_time
+  ON task_instances (dag_id, start_date DESC);
+
+CREATE INDEX IF NOT EXISTS idx_task_instances_run
+  ON task_instances (dag_id, run_id);
+`},
+	{2, "trigger conf history", `
+CREATE TABLE IF NOT EXISTS trigger_confs (
+  dag_id TEXT NOT NULL,
+  conf TEXT NOT NULL,
+  use_count INTEGER NOT NULL DEFAULT 1,
+  last_used_at TEXT NOT NULL,
+  PRIMARY KEY (dag_id, conf)
+);
+`},
+	{3, "dag metadata for offline browsing", `
+CREATE TABLE IF NOT EXISTS dags (
+  dag_id TEXT PRIMARY KEY,
+  updated_at TEXT NOT NULL,
+  raw_json TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS dag_tasks (
+  dag_id TEXT PRIMARY KEY,
+  updated_at TEXT NOT NULL,
+  raw_json TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS dag_sources (
+  dag_id TEXT PRIMARY KEY,
+  source TEXT NOT NULL,
+  updated_at TEXT NOT NULL
+);
+`},
+	{4, "action audit trail", `
+CREATE TABLE IF NOT EXISTS actions (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  at TEXT NOT NULL,
+  user TEXT NOT NULL,
+  server TEXT NOT NULL,
+  action TEXT NOT NULL,
+  dag_id TEXT NOT NULL,
+  target TEXT NOT NULL DEFAULT '',
+  request TEXT NOT NULL DEFAULT '',
+  error TEXT NOT NULL DEFAULT '',
+  revert_of INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_actions_at
+  ON actions (at DESC);
+`},
+	// SQLite cannot change a primary key in place, so the table is rebuilt
+	// with map_index in the key; earlier rows are unmapped (-1).
+	{5, "mapped task instances", `
+CREATE TABLE task_instances_v5 (
+  dag_id TEXT NOT NULL,
+  run_id TEXT NOT NULL,
+  task_id TEXT NOT NULL,
+  map_index INTEGER NOT NULL DEFAULT -1,
+  rendered_map_index TEXT,
+  state TEXT NOT NULL,
+  start_date TEXT,
+  end_date TEXT,
+  queued_at TEXT,
+  duration_ms INTEGER NOT NULL DEFAULT 0,
+  queue_ms INTEGER NOT NULL DEFAULT 0,
+  try_number INTEGER NOT NULL DEFAULT 0,
+  operator TEXT,
+  pool TEXT,
+  queue TEXT,
+  hostname TEXT,
+  updated_at TEXT NOT NULL,
+  raw_json TEXT NOT NULL,
+  PRIMARY KEY (dag_id, run_id, task_id, map_index)
+);
+
+INSERT INTO task_instances_v5 (
+  dag_id, run_id, task_id, state, start_date, end_date, queued_at, duration_ms, queue_ms,
+  try_number, operator, pool, queue, hostname, updated_at, raw_json
+)
+SELECT dag_id, run_id, task_id, state, start_date, end_date, queued_at, duration_ms, queue_ms,
+       try_number, operator, pool, queue, hostname, updated_at, raw_json
+FROM task_instances;
+
+DROP TABLE task_instances;
+ALTER TABLE task_instances_v5 RENAME TO task_instances;
+
+CREATE INDEX idx_task_instances_dag_time
+  ON task_instances (dag_id, start_date DESC);
+
+CREATE INDEX idx_task_instances_run
+  ON task_instances (dag_id, run_id);
+`},
+}
+
+// latestSchemaVersion is the version a fully migrated cache.db reports.
+func latestSchemaVersion() int { return migrations[len(migrations)-1].version }
+
+// schemaVersion returns the highest applied version, 0 for a fresh file.
+func schemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	if _, err := db.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS schema_version (
+  version INTEGER PRIMARY KEY,
+  applied_at TEXT NOT NULL
+)`); err != nil {
+		return 0, fmt.Errorf("create schema_version: %w", err)
+	}
+	var v int
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&v); err != nil {
+		return 0, fmt.Errorf("read schema_version: %w", err)
+	}
+	return v, nil
+}
+
+// migrate brings db up to latestSchemaVersion, refusing a newer database.
+func migrate(ctx context.Context, db *sql.DB) error {
+	current, err := schemaVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+	if latest := latestSchemaVersion(); current > latest {
+		return fmt.Errorf("%w: cache.db is v%d, this build supports up to v%d", ErrSchemaTooNew, current, latest)
+	}
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := applyMigration(ctx, db, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, m migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("migration %d (%s): begin: %w", m.version, m.name, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.ExecContext(ctx, m.up); err != nil {
+		return fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO schema_version (version, applied_at) VALUES (?, ?)`,
+		m.version, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("migration %d (%s): record version: %w", m.version, m.name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("migration %d (%s): commit: %w", m.version, m.name, err)
+	}
+	return nil
+}
+
+// checkIntegrity runs SQLite's quick_check; anything but "ok" is corruption.
+func checkIntegrity(ctx context.Context, db *sql.DB) error {
+	var result string
+	if err := db.QueryRowContext(ctx, `PRAGMA quick_check`).Scan(&result); err != nil {
+		return err
+	}
+	if result != "ok" {
+		return fmt.Errorf("quick_check: %s", result)
+	}
+	return nil
+}
+
+// isCorrupt reports whether err means the file itself is unusable, as
+// opposed to a transient or configuration problem.
+func isCorrupt(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"malformed", "not a database", "quick_check", "file is encrypted"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// quarantine moves a corrupt cache.db (and its WAL/SHM side files) aside so a
+// fresh one can be created; the old file is kept for inspection.
+func quarantine(path string, now time.Time) (string, error) {
+	dst := fmt.Sprintf("%s.corrupt-%s", path, now.UTC().Format("20060102T150405"))
+	if err := os.Rename(path, dst); err != nil {
+		return "", err
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Rename(path+suffix, dst+suffix); err != nil && !os.IsNotExist(err) {
+			return dst, err
+		}
+	}
+	return dst, nil
+}
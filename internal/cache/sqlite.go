@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sync"
@@ -16,84 +18,16 @@ import (
 	"github.com/yjinheon/lazyflow/pkg/airflow/models"
 )
 
-const sqliteSchema = `
-CREATE TABLE IF NOT EXISTS schema_version (
-  version INTEGER PRIMARY KEY,
-  applied_at TEXT NOT NULL
-);
-
-CREATE TABLE IF NOT EXISTS backfills (
-  dag_id TEXT NOT NULL,
-  id INTEGER NOT NULL,
-  state TEXT NOT NULL,
-  from_date TEXT,
-  to_date TEXT,
-  completed_runs INTEGER NOT NULL DEFAULT 0,
-  failed_runs INTEGER NOT NULL DEFAULT 0,
-  running_runs INTEGER NOT NULL DEFAULT 0,
-  total_runs INTEGER NOT NULL DEFAULT 0,
-  updated_at TEXT NOT NULL,
-  raw_json TEXT NOT NULL,
-  PRIMARY KEY (dag_id, id)
-);
-
-CREATE TABLE IF NOT EXISTS dag_runs (
-  dag_id TEXT NOT NULL,
-  run_id TEXT NOT NULL,
-  state TEXT NOT NULL,
-  logical_date TEXT,
-  run_after TEXT,
-  start_date TEXT,
-  end_date TEXT,
-  duration_ms INTEGER NOT NULL DEFAULT 0,
-  run_type TEXT,
-  note TEXT,
-  updated_at TEXT NOT NULL,
-  raw_json TEXT NOT NULL,
-  PRIMARY KEY (dag_id, run_id)
-);
-
-CREATE TABLE IF NOT EXISTS task_instances (
-  dag_id TEXT NOT NULL,
-  run_id TEXT NOT NULL,
-  task_id TEXT NOT NULL,
-  state TEXT NOT NULL,
-  start_date TEXT,
-  end_date TEXT,
-  queued_at TEXT,
-  duration_ms INTEGER NOT NULL DEFAULT 0,
-  queue_ms INTEGER NOT NULL DEFAULT 0,
-  try_number INTEGER NOT NULL DEFAULT 0,
-  operator TEXT,
-  pool TEXT,
-  queue TEXT,
-  hostname TEXT,
-  updated_at TEXT NOT NULL,
-  raw_json TEXT NOT NULL,
-  PRIMARY KEY (dag_id, run_id, task_id)
-);
-
-CREATE INDEX IF NOT EXISTS idx_dag_runs_dag_time
-  ON dag_runs (dag_id, run_after DESC);
-
-CREATE INDEX IF NOT EXISTS idx_dag_runs_time
-  ON dag_runs (run_after DESC);
-
-CREATE INDEX IF NOT EXISTS idx_task_instances_dag_time
-  ON task_instances (dag_id, start_date DESC);
-
-CREATE INDEX IF NOT EXISTS idx_task_instances_run
-  ON task_instances (dag_id, run_id);
-
-INSERT OR IGNORE INTO schema_version (version, applied_at) VALUES (1, datetime('now'));
-`
-
 type writeKind int
 
 const (
 	writeBackfills writeKind = iota
 	writeDAGRuns
 	writeTaskInstances
+	writeTriggerConf
+	writeDAGs
+	writeTasks
+	writeDAGSource
 )
 
 type writeOp struct {
@@ -103,6 +37,10 @@ type writeOp struct {
 	backfills []models.Backfill
 	dagRuns   []models.DAGRun
 	tasks     []models.TaskInstance
+	conf      string
+	dags      []models.DAG
+	dagTasks  []models.Task
+	source    string
 }
 
 type sqliteCache struct {
@@ -131,7 +69,42 @@ func NewSQLite(path string, opts Options) (Cache, error) {
 		return nil, fmt.Errorf("create cache dir: %w", err)
 	}
 
-	db, err := sql.Open("sqlite", expanded)
+	c, err := openSQLite(expanded, opts)
+	if isCorrupt(err) {
+		// The cache is rebuildable from the API, so a damaged file is moved
+		// aside rather than blocking startup or silently falling back to memory.
+		moved, qerr := quarantine(expanded, time.Now())
+		if qerr != nil {
+			return nil, fmt.Errorf("sqlite cache corrupt (%v); move aside: %w", err, qerr)
+		}
+		log.Printf("[ERROR] sqlite cache corrupt, rebuilding: %v (old file kept at %s)", err, moved)
+		c, err = openSQLite(expanded, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	go c.writeLoop()
+	return c, nil
+}
+
+// sqliteDSN turns a file path into a file: URI, so "?", "#" and "%" in
+// the name are not read as query, fragment or escape. The path is made
+// absolute, or its first element would parse as the URI's host.
+func sqliteDSN(path, query string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(abs), RawQuery: query}
+	return u.String(), nil
+}
+
+func openSQLite(path string, opts Options) (*sqliteCache, error) {
+	dsn, err := sqliteDSN(path, "")
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite cache: %w", err)
+	}
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("open sqlite cache: %w", err)
 	}
@@ -149,11 +122,11 @@ func NewSQLite(path string, opts Options) (Cache, error) {
 		_ = db.Close()
 		return nil, err
 	}
-	go c.writeLoop()
 	return c, nil
 }
 
 func (c *sqliteCache) init() error {
+	ctx := context.Background()
 	pragmas := []string{
 		"PRAGMA journal_mode=WAL",
 		"PRAGMA busy_timeout=5000",
@@ -165,10 +138,13 @@ func (c *sqliteCache) init() error {
 			return fmt.Errorf("sqlite pragma %q: %w", stmt, err)
 		}
 	}
-	if _, err := c.db.Exec(sqliteSchema); err != nil {
+	if err := checkIntegrity(ctx, c.db); err != nil {
+		return fmt.Errorf("sqlite integrity: %w", err)
+	}
+	if err := migrate(ctx, c.db); err != nil {
 		return fmt.Errorf("sqlite schema: %w", err)
 	}
-	return c.cleanup(context.Background(), time.Now())
+	return c.cleanup(ctx, time.Now())
 }
 
 func (c *sqliteCache) GetBackfills(dagId string) ([]models.Backfill, bool) {
@@ -264,7 +240,7 @@ func (c *sqliteCache) PutTaskInstances(dagId, runId string, tasks []models.TaskI
 func (c *sqliteCache) GetTaskInstancesHistory(dagId string, since time.Time, limit int) ([]models.TaskInstance, bool) {
 	args := []any{dagId, formatTime(since)}
 	q := `
-SELECT dag_id, run_id, task_id, state, start_date, end_date, queued_at, duration_ms, try_number, operator, pool, queue, hostname
+SELECT dag_id, run_id, task_id, map_index, rendered_map_index, state, start_date, end_date, queued_at, duration_ms, try_number, operator, pool, queue, hostname
 FROM task_instances
 WHERE dag_id = ? AND COALESCE(start_date, updated_at) >= ?
 ORDER BY COALESCE(start_date, updated_at) DESC`
@@ -282,13 +258,15 @@ ORDER BY COALESCE(start_date, updated_at) DESC`
 	for rows.Next() {
 		var (
 			ti                              models.TaskInstance
+			rendered                        sql.NullString
 			start, end, queued              sql.NullString
 			durationMS                      int64
 			operator, pool, queue, hostname sql.NullString
 		)
-		if err := rows.Scan(&ti.DagId, &ti.RunId, &ti.TaskId, &ti.State, &start, &end, &queued, &durationMS, &ti.TryNumber, &operator, &pool, &queue, &hostname); err != nil {
+		if err := rows.Scan(&ti.DagId, &ti.RunId, &ti.TaskId, &ti.MapIndex, &rendered, &ti.State, &start, &end, &queued, &durationMS, &ti.TryNumber, &operator, &pool, &queue, &hostname); err != nil {
 			return nil, false
 		}
+		ti.RenderedMapIndex = rendered.String
 		ti.StartDate = parseTimePtr(start)
 		ti.EndDate = parseTimePtr(end)
 		ti.QueuedDttm = parseTimePtr(queued)
@@ -376,6 +354,155 @@ ORDER BY r.failed DESC, r.dag_id ASC`
 	return out, true
 }
 
+func (c *sqliteCache) PutTriggerConf(dagId, conf string) {
+	canon, ok := canonicalConf(conf)
+	if !ok {
+		return
+	}
+	c.enqueue(writeOp{kind: writeTriggerConf, dagId: dagId, conf: canon})
+}
+
+func (c *sqliteCache) GetTriggerConfs(dagId string, limit int) ([]TriggerConf, bool) {
+	args := []any{dagId}
+	q := `
+SELECT conf, use_count, last_used_at
+FROM trigger_confs
+WHERE dag_id = ?
+ORDER BY last_used_at DESC`
+	if limit > 0 {
+		q += " LIMIT ?"
+		args = append(args, limit)
+	}
+	rows, err := c.db.Query(q, args...)
+	if err != nil {
+		return nil, false
+	}
+	defer rows.Close()
+
+	out := make([]TriggerConf, 0)
+	for rows.Next() {
+		var (
+			tc       TriggerConf
+			lastUsed sql.NullString
+		)
+		if err := rows.Scan(&tc.Conf, &tc.Uses, &lastUsed); err != nil {
+			return nil, false
+		}
+		tc.LastUsed = parseTime(lastUsed)
+		out = append(out, tc)
+	}
+	if len(out) == 0 || rows.Err() != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+func (c *sqliteCache) PutDAGs(dags []models.DAG) {
+	dup := make([]models.DAG, len(dags))
+	copy(dup, dags)
+	c.enqueue(writeOp{kind: writeDAGs, dags: dup})
+}
+
+func (c *sqliteCache) GetDAGs() ([]models.DAG, bool) {
+	rows, err := c.db.Query(`SELECT raw_json FROM dags ORDER BY dag_id`)
+	if err != nil {
+		return nil, false
+	}
+	defer rows.Close()
+
+	out := make([]models.DAG, 0)
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&raw); err != nil {
+			return nil, false
+		}
+		var d models.DAG
+		if err := json.Unmarshal([]byte(raw), &d); err != nil {
+			continue
+		}
+		out = append(out, d)
+	}
+	if len(out) == 0 || rows.Err() != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+func (c *sqliteCache) PutTasks(dagId string, tasks []models.Task) {
+	dup := make([]models.Task, len(tasks))
+	copy(dup, tasks)
+	c.enqueue(writeOp{kind: writeTasks, dagId: dagId, dagTasks: dup})
+}
+
+func (c *sqliteCache) GetTasks(dagId string) ([]models.Task, bool) {
+	var raw string
+	if err := c.db.QueryRow(`SELECT raw_json FROM dag_tasks WHERE dag_id = ?`, dagId).Scan(&raw); err != nil {
+		return nil, false
+	}
+	var tasks []models.Task
+	if err := json.Unmarshal([]byte(raw), &tasks); err != nil || len(tasks) == 0 {
+		return nil, false
+	}
+	return tasks, true
+}
+
+func (c *sqliteCache) PutDAGSource(dagId, source string) {
+	c.enqueue(writeOp{kind: writeDAGSource, dagId: dagId, source: source})
+}
+
+func (c *sqliteCache) GetDAGSource(dagId string) (string, bool) {
+	var source string
+	if err := c.db.QueryRow(`SELECT source FROM dag_sources WHERE dag_id = ?`, dagId).Scan(&source); err != nil {
+		return "", false
+	}
+	return source, true
+}
+
+func (c *sqliteCache) RecordAction(a ActionRecord) (int64, error) {
+	res, err := c.db.Exec(`
+INSERT INTO actions (at, user, server, action, dag_id, target, request, error, revert_of)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		formatTime(a.At), a.User, a.Server, a.Action, a.DagId, a.Target, a.Request, a.Error, a.RevertOf)
+	if err != nil {
+		return 0, fmt.Errorf("record action: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+func (c *sqliteCache) GetActions(limit int) ([]ActionRecord, bool) {
+	q := `
+SELECT id, at, user, server, action, dag_id, target, request, error, revert_of
+FROM actions
+ORDER BY id DESC`
+	var args []any
+	if limit > 0 {
+		q += " LIMIT ?"
+		args = append(args, limit)
+	}
+	rows, err := c.db.Query(q, args...)
+	if err != nil {
+		return nil, false
+	}
+	defer rows.Close()
+
+	out := make([]ActionRecord, 0)
+	for rows.Next() {
+		var (
+			a  ActionRecord
+			at sql.NullString
+		)
+		if err := rows.Scan(&a.ID, &at, &a.User, &a.Server, &a.Action, &a.DagId, &a.Target, &a.Request, &a.Error, &a.RevertOf); err != nil {
+			return nil, false
+		}
+		a.At = parseTime(at)
+		out = append(out, a)
+	}
+	if len(out) == 0 || rows.Err() != nil {
+		return nil, false
+	}
+	return out, true
+}
+
 func (c *sqliteCache) Close() error {
 	c.closeMu.Lock()
 	if c.closed {
@@ -434,6 +561,17 @@ func (c *sqliteCache) applyWrite(ctx context.Context, op writeOp) error {
 		applyErr = insertDAGRuns(ctx, tx, op.dagId, op.dagRuns)
 	case writeTaskInstances:
 		applyErr = insertTaskInstances(ctx, tx, op.dagId, op.runId, op.tasks)
+	case writeTriggerConf:
+		applyErr = upsertTriggerConf(ctx, tx, op.dagId, op.conf)
+	case writeDAGs:
+		applyErr = replaceDAGs(ctx, tx, op.dags)
+	case writeTasks:
+		applyErr = upsertDAGTasks(ctx, tx, op.dagId, op.dagTasks)
+	case writeDAGSource:
+		_, applyErr = tx.ExecContext(ctx, `
+INSERT INTO dag_sources (dag_id, source, updated_at) VALUES (?, ?, ?)
+ON CONFLICT(dag_id) DO UPDATE SET source=excluded.source, updated_at=excluded.updated_at`,
+			op.dagId, op.source, formatTime(time.Now()))
 	default:
 		applyErr = errors.New("unknown cache write op")
 	}
@@ -444,6 +582,39 @@ func (c *sqliteCache) applyWrite(ctx context.Context, op writeOp) error {
 	return tx.Commit()
 }
 
+// replaceDAGs swaps in the latest DAG list, so DAGs deleted upstream do not
+// linger in the offline view.
+func replaceDAGs(ctx context.Context, tx *sql.Tx, dags []models.DAG) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM dags`); err != nil {
+		return err
+	}
+	stmt, err := tx.PrepareContext(ctx, `INSERT OR REPLACE INTO dags (dag_id, updated_at, raw_json) VALUES (?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	now := formatTime(time.Now())
+	for _, d := range dags {
+		raw, _ := json.Marshal(d)
+		if _, err := stmt.ExecContext(ctx, d.DagId, now, string(raw)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func upsertDAGTasks(ctx context.Context, tx *sql.Tx, dagId string, tasks []models.Task) error {
+	raw, err := json.Marshal(tasks)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+INSERT INTO dag_tasks (dag_id, updated_at, raw_json) VALUES (?, ?, ?)
+ON CONFLICT(dag_id) DO UPDATE SET updated_at=excluded.updated_at, raw_json=excluded.raw_json`,
+		dagId, formatTime(time.Now()), string(raw))
+	return err
+}
+
 func insertBackfills(ctx context.Context, tx *sql.Tx, dagId string, bfs []models.Backfill) error {
 	stmt, err := tx.PrepareContext(ctx, `
 INSERT INTO backfills (
@@ -501,10 +672,11 @@ ON CONFLICT(dag_id, run_id) DO UPDATE SET
 func insertTaskInstances(ctx context.Context, tx *sql.Tx, dagId, runId string, tasks []models.TaskInstance) error {
 	stmt, err := tx.PrepareContext(ctx, `
 INSERT INTO task_instances (
-  dag_id, run_id, task_id, state, start_date, end_date, queued_at, duration_ms, queue_ms,
+  dag_id, run_id, task_id, map_index, rendered_map_index, state, start_date, end_date, queued_at, duration_ms, queue_ms,
   try_number, operator, pool, queue, hostname, updated_at, raw_json
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-ON CONFLICT(dag_id, run_id, task_id) DO UPDATE SET
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(dag_id, run_id, task_id, map_index) DO UPDATE SET
+  rendered_map_index=excluded.rendered_map_index,
   state=excluded.state, start_date=excluded.start_date, end_date=excluded.end_date,
   queued_at=excluded.queued_at, duration_ms=excluded.duration_ms, queue_ms=excluded.queue_ms,
   try_number=excluded.try_number, operator=excluded.operator, pool=excluded.pool,
@@ -524,13 +696,22 @@ ON CONFLICT(dag_id, run_id, task_id) DO UPDATE SET
 			rid = ti.RunId
 		}
 		raw, _ := json.Marshal(ti)
-		if _, err := stmt.ExecContext(ctx, did, rid, ti.TaskId, ti.State, formatTimePtr(ti.StartDate), formatTimePtr(ti.EndDate), formatTimePtr(ti.QueuedDttm), taskDuration(ti).Milliseconds(), queueDuration(ti).Milliseconds(), ti.TryNumber, ti.Operator, ti.Pool, ti.Queue, ti.Hostname, formatTime(time.Now()), string(raw)); err != nil {
+		if _, err := stmt.ExecContext(ctx, did, rid, ti.TaskId, ti.MapIndex, ti.RenderedMapIndex, ti.State, formatTimePtr(ti.StartDate), formatTimePtr(ti.EndDate), formatTimePtr(ti.QueuedDttm), taskDuration(ti).Milliseconds(), queueDuration(ti).Milliseconds(), ti.TryNumber, ti.Operator, ti.Pool, ti.Queue, ti.Hostname, formatTime(time.Now()), string(raw)); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+func upsertTriggerConf(ctx context.Context, tx *sql.Tx, dagId, conf string) error {
+	_, err := tx.ExecContext(ctx, `
+INSERT INTO trigger_confs (dag_id, conf, use_count, last_used_at) VALUES (?, ?, 1, ?)
+ON CONFLICT(dag_id, conf) DO UPDATE SET
+  use_count=trigger_confs.use_count + 1, last_used_at=excluded.last_used_at`,
+		dagId, conf, formatTime(time.Now()))
+	return err
+}
+
 func (c *sqliteCache) queryDAGRuns(q string, args ...any) ([]models.DAGRun, bool) {
 	rows, err := c.db.Query(q, args...)
 	if err != nil {
@@ -558,12 +739,17 @@ func (c *sqliteCache) queryDAGRuns(q string, args ...any) ([]models.DAGRun, bool
 	return out, true
 }
 
+// cleanup drops history older than the retention. The actions table is an
+// audit trail and is kept.
 func (c *sqliteCache) cleanup(ctx context.Context, now time.Time) error {
 	cutoff := formatTime(now.Add(-c.retention))
 	for _, stmt := range []string{
 		"DELETE FROM dag_runs WHERE run_after < ?",
 		"DELETE FROM task_instances WHERE COALESCE(start_date, updated_at) < ?",
 		"DELETE FROM backfills WHERE updated_at < ?",
+		"DELETE FROM trigger_confs WHERE last_used_at < ?",
+		"DELETE FROM dag_tasks WHERE updated_at < ?",
+		"DELETE FROM dag_sources WHERE updated_at < ?",
 	} {
 		if _, err := c.db.ExecContext(ctx, stmt, cutoff); err != nil {
 			return err
@@ -1,6 +1,9 @@
 package cache
 
 import (
+	"database/sql"
+	"errors"
+	"os"
 	"path/filepath"
 	"sync"
 	"testing"
@@ -35,6 +38,105 @@ func TestSQLite_schemaMigration(t *testing.T) {
 	}
 }
 
+func TestSQLite_upgradesOlderSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := migrations
+	migrations = saved[:1]
+	err = migrate(t.Context(), db)
+	migrations = saved
+	_ = db.Close()
+	if err != nil {
+		t.Fatalf("migrate v1: %v", err)
+	}
+
+	c, err := NewSQLite(path, Options{})
+	if err != nil {
+		t.Fatalf("NewSQLite: %v", err)
+	}
+	defer c.Close()
+	sc := c.(*sqliteCache)
+	v, err := schemaVersion(t.Context(), sc.db)
+	if err != nil || v != latestSchemaVersion() {
+		t.Fatalf("version = %d, %v; want %d", v, err, latestSchemaVersion())
+	}
+	var name string
+	if err := sc.db.QueryRow("SELECT name FROM sqlite_master WHERE type='table' AND name='trigger_confs'").Scan(&name); err != nil {
+		t.Fatalf("trigger_confs not created by upgrade: %v", err)
+	}
+}
+
+func TestSQLite_refusesNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	c, err := NewSQLite(path, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.(*sqliteCache).db.Exec(`INSERT INTO schema_version (version, applied_at) VALUES (99, 'later')`); err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+
+	if _, err := NewSQLite(path, Options{}); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("err = %v, want ErrSchemaTooNew", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("newer cache.db must be left in place: %v", err)
+	}
+}
+
+func TestSQLite_failedMigrationRollsBack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	saved := migrations
+	defer func() { migrations = saved }()
+	migrations = append(saved[:len(saved):len(saved)], migration{
+		version: latestSchemaVersion() + 1,
+		name:    "broken",
+		up:      `CREATE TABLE half_done (id INTEGER); INSERT INTO no_such_table VALUES (1);`,
+	})
+
+	if err := migrate(t.Context(), db); err == nil {
+		t.Fatal("expected broken migration to fail")
+	}
+	v, err := schemaVersion(t.Context(), db)
+	if err != nil || v != saved[len(saved)-1].version {
+		t.Fatalf("version = %d, %v; want %d", v, err, saved[len(saved)-1].version)
+	}
+	var n int
+	_ = db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name='half_done'").Scan(&n)
+	if n != 0 {
+		t.Fatal("failed migration left half_done behind")
+	}
+}
+
+func TestSQLite_rebuildsCorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cache.db")
+	if err := os.WriteFile(path, []byte("this is not a sqlite database, just garbage bytes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewSQLite(path, Options{})
+	if err != nil {
+		t.Fatalf("NewSQLite on corrupt file: %v", err)
+	}
+	defer c.Close()
+	c.PutDAGRuns("d", []models.DAGRun{{DagId: "d", RunId: "r", State: "success"}})
+
+	moved, _ := filepath.Glob(path + ".corrupt-*")
+	if len(moved) == 0 {
+		t.Fatal("corrupt file was not kept aside")
+	}
+}
+
 func TestSQLite_putDAGRunsHistoryUpsert(t *testing.T) {
 	now := time.Now().UTC()
 	path := filepath.Join(t.TempDir(), "cache.db")
@@ -159,3 +261,158 @@ func TestSQLite_concurrentPutCloseDrains(t *testing.T) {
 		t.Fatalf("got len=%d ok=%v", len(got), ok)
 	}
 }
+
+func TestSQLite_triggerConfHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	c, err := NewSQLite(path, Options{Retention: 24 * time.Hour, WriteBuffer: 16})
+	if err != nil {
+		t.Fatalf("NewSQLite: %v", err)
+	}
+	c.PutTriggerConf("etl", `{"b": 2, "a": 1}`)
+	c.PutTriggerConf("etl", "{}")
+	c.PutTriggerConf("etl", "not json")
+	c.PutTriggerConf("etl", `{"a":1,"b":2}`)
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reopened, err := NewSQLite(path, Options{Retention: 24 * time.Hour, WriteBuffer: 16})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+	got, ok := reopened.GetTriggerConfs("etl", 10)
+	if !ok || len(got) != 1 {
+		t.Fatalf("got=%+v ok=%v", got, ok)
+	}
+	if got[0].Conf != `{"a":1,"b":2}` || got[0].Uses != 2 {
+		t.Fatalf("equivalent confs not merged: %+v", got[0])
+	}
+}
+
+func TestSQLite_dagMetadataSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	c, err := NewSQLite(path, Options{Retention: 24 * time.Hour, WriteBuffer: 16})
+	if err != nil {
+		t.Fatalf("NewSQLite: %v", err)
+	}
+	c.PutDAGs([]models.DAG{{DagId: "old"}, {DagId: "etl"}})
+	c.PutDAGs([]models.DAG{{DagId: "etl", IsPaused: true}, {DagId: "report"}})
+	c.PutTasks("etl", []models.Task{{TaskId: "extract", DownstreamTaskIds: []string{"load"}}, {TaskId: "load"}})
+	c.PutDAGSource("etl", "with DAG('etl'):\n    pass\n")
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reopened, err := NewSQLite(path, Options{Retention: 24 * time.Hour, WriteBuffer: 16})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+	dags, ok := reopened.GetDAGs()
+	if !ok || len(dags) != 2 || dags[0].DagId != "etl" || !dags[0].IsPaused || dags[1].DagId != "report" {
+		t.Fatalf("dags = %+v ok=%v; the latest list should replace the old one", dags, ok)
+	}
+	tasks, ok := reopened.GetTasks("etl")
+	if !ok || len(tasks) != 2 || tasks[0].DownstreamTaskIds[0] != "load" {
+		t.Fatalf("tasks = %+v ok=%v", tasks, ok)
+	}
+	if src, ok := reopened.GetDAGSource("etl"); !ok || src != "with DAG('etl'):\n    pass\n" {
+		t.Fatalf("source = %q ok=%v", src, ok)
+	}
+	if _, ok := reopened.GetDAGSource("missing"); ok {
+		t.Fatal("unexpected source for an unknown DAG")
+	}
+}
+
+func TestSQLite_actionsSurviveReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	c, err := NewSQLite(path, Options{Retention: 24 * time.Hour, WriteBuffer: 16})
+	if err != nil {
+		t.Fatalf("NewSQLite: %v", err)
+	}
+	// Older than the retention: the audit trail outlives the run history.
+	at := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	id, err := c.RecordAction(ActionRecord{
+		At: at, User: "alice", Server: "http://airflow:8080", Action: ActionPauseBackfill,
+		DagId: "etl", Target: "7",
+	})
+	if err != nil || id == 0 {
+		t.Fatalf("RecordAction = %d, %v", id, err)
+	}
+	if _, err := c.RecordAction(ActionRecord{
+		At: at, User: "alice", Action: ActionUnpauseBackfill, DagId: "etl", Target: "7", RevertOf: id,
+	}); err != nil {
+		t.Fatalf("RecordAction: %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reopened, err := NewSQLite(path, Options{Retention: 24 * time.Hour, WriteBuffer: 16})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+	got, ok := reopened.GetActions(10)
+	if !ok || len(got) != 2 {
+		t.Fatalf("got=%+v ok=%v", got, ok)
+	}
+	if got[0].RevertOf != id || got[1].ID != id {
+		t.Fatalf("want newest first with the revert link kept: %+v", got)
+	}
+	if a := got[1]; a.User != "alice" || a.Server != "http://airflow:8080" || a.Target != "7" || !a.At.Equal(at) {
+		t.Fatalf("fields not round-tripped: %+v", a)
+	}
+}
+
+func TestSQLite_mappedTaskInstancesKeepEachIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := migrations
+	migrations = saved[:4]
+	err = migrate(t.Context(), db)
+	migrations = saved
+	if err != nil {
+		t.Fatalf("migrate v4: %v", err)
+	}
+	// A row cached before map_index was part of the key.
+	if _, err := db.Exec(`INSERT INTO task_instances (dag_id, run_id, task_id, state, updated_at, raw_json)
+VALUES ('etl', 'r0', 'extract', 'success', ?, '{}')`, formatTime(time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Close()
+
+	c, err := NewSQLite(path, Options{Retention: 24 * time.Hour, WriteBuffer: 16})
+	if err != nil {
+		t.Fatalf("NewSQLite: %v", err)
+	}
+	start := time.Now().Add(-time.Minute)
+	c.PutTaskInstances("etl", "r1", []models.TaskInstance{
+		{DagId: "etl", RunId: "r1", TaskId: "load", MapIndex: 0, RenderedMapIndex: "eu", State: "success", StartDate: &start},
+		{DagId: "etl", RunId: "r1", TaskId: "load", MapIndex: 1, RenderedMapIndex: "us", State: "failed", StartDate: &start},
+	})
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reopened, err := NewSQLite(path, Options{Retention: 24 * time.Hour, WriteBuffer: 16})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+	got, _ := reopened.GetTaskInstancesHistory("etl", time.Time{}, 0)
+	byLabel := map[string]models.TaskInstance{}
+	for _, ti := range got {
+		byLabel[ti.TaskId+"/"+ti.MapLabel()] = ti
+	}
+	if len(got) != 3 || byLabel["load/eu"].State != "success" || byLabel["load/us"].State != "failed" {
+		t.Fatalf("mapped instances = %+v", got)
+	}
+	if ti, ok := byLabel["extract/-1"]; !ok || ti.Mapped() {
+		t.Fatalf("the upgraded row should be unmapped: %+v", got)
+	}
+}
@@ -0,0 +1,627 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+// Format is an export/import file format.
+type Format string
+
+const (
+	// FormatCSV writes a directory holding dag_runs.csv and task_instances.csv.
+	FormatCSV Format = "csv"
+	// FormatJSONL writes one JSON object per line, tagged by "type".
+	FormatJSONL Format = "jsonl"
+	// FormatSQLite writes a standalone database with the cache schema.
+	FormatSQLite Format = "sqlite"
+)
+
+// Formats lists the supported formats in display order.
+var Formats = []Format{FormatCSV, FormatJSONL, FormatSQLite}
+
+// ParseFormat accepts a format name, case-insensitively.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case FormatCSV, FormatJSONL, FormatSQLite:
+		return f, nil
+	case "json", "ndjson":
+		return FormatJSONL, nil
+	case "db", "sqlite3":
+		return FormatSQLite, nil
+	}
+	return "", fmt.Errorf("unknown format %q (want csv, jsonl or sqlite)", s)
+}
+
+// FormatForPath guesses the format from a path: a directory or .csv is CSV,
+// .jsonl/.ndjson/.json is JSON Lines, .db/.sqlite/.sqlite3 is SQLite.
+func FormatForPath(path string) (Format, error) {
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		return FormatCSV, nil
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".jsonl", ".ndjson", ".json":
+		return FormatJSONL, nil
+	case ".db", ".sqlite", ".sqlite3":
+		return FormatSQLite, nil
+	}
+	return "", fmt.Errorf("cannot tell the format of %s; pass one explicitly", path)
+}
+
+// CSV file names inside a CSV export directory.
+const (
+	csvRunsFile  = "dag_runs.csv"
+	csvTasksFile = "task_instances.csv"
+)
+
+// ExportFilter selects what Export writes. An empty DagIds means every DAG
+// with runs in the range; zero Since/Until leave that end open.
+type ExportFilter struct {
+	DagIds []string
+	Since  time.Time
+	Until  time.Time
+}
+
+// TransferStats counts the records an export or import moved.
+type TransferStats struct {
+	DAGs  int
+	Runs  int
+	Tasks int
+}
+
+// Export writes the cached runs and task instances matching f to path.
+// It refuses to overwrite an existing file or directory. A leading ~/ in
+// path is expanded.
+func Export(c Cache, path string, format Format, f ExportFilter) (TransferStats, error) {
+	path, err := expandPath(path)
+	if err != nil {
+		return TransferStats{}, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return TransferStats{}, fmt.Errorf("%s already exists", path)
+	}
+	runs, tasks := collectHistory(c, f)
+	stats := TransferStats{Runs: len(runs), Tasks: len(tasks)}
+	seen := make(map[string]bool)
+	for _, r := range runs {
+		seen[r.DagId] = true
+	}
+	stats.DAGs = len(seen)
+
+	switch format {
+	case FormatCSV:
+		err = writeCSV(path, runs, tasks)
+	case FormatJSONL:
+		err = writeFileAtomic(path, func(w io.Writer) error { return writeJSONL(w, runs, tasks) })
+	case FormatSQLite:
+		err = writeSnapshot(path, runs, tasks)
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return TransferStats{}, err
+	}
+	return stats, nil
+}
+
+// Import merges the runs and task instances in path into c. Records already
+// in the cache are overwritten by the imported copy. Rows older than the
+// cache retention are accepted but dropped by the next cleanup.
+func Import(c Cache, path string, format Format) (TransferStats, error) {
+	path, err := expandPath(path)
+	if err != nil {
+		return TransferStats{}, err
+	}
+	var (
+		runs  []models.DAGRun
+		tasks []models.TaskInstance
+	)
+	switch format {
+	case FormatCSV:
+		runs, tasks, err = readCSV(path)
+	case FormatJSONL:
+		runs, tasks, err = readJSONL(path)
+	case FormatSQLite:
+		runs, tasks, err = readSnapshot(path)
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return TransferStats{}, err
+	}
+	return putHistory(c, runs, tasks)
+}
+
+// collectHistory reads f's runs and tasks through the regular history
+// queries, newest first.
+func collectHistory(c Cache, f ExportFilter) ([]models.DAGRun, []models.TaskInstance) {
+	dagIds := f.DagIds
+	if len(dagIds) == 0 {
+		all, _ := c.GetAllDAGRunsHistory(f.Since, 0)
+		seen := make(map[string]bool)
+		for _, r := range all {
+			if !seen[r.DagId] {
+				seen[r.DagId] = true
+				dagIds = append(dagIds, r.DagId)
+			}
+		}
+		sort.Strings(dagIds)
+	}
+	var (
+		runs  []models.DAGRun
+		tasks []models.TaskInstance
+	)
+	for _, dagId := range dagIds {
+		rs, _ := c.GetDAGRunsHistory(dagId, f.Since, 0)
+		for _, r := range rs {
+			if f.Until.IsZero() || !runTime(r).After(f.Until) {
+				runs = append(runs, r)
+			}
+		}
+		ts, _ := c.GetTaskInstancesHistory(dagId, f.Since, 0)
+		for _, ti := range ts {
+			if f.Until.IsZero() || !taskTime(ti).After(f.Until) {
+				tasks = append(tasks, ti)
+			}
+		}
+	}
+	return runs, tasks
+}
+
+// syncWriter is implemented by caches whose Put is asynchronous. Import
+// writes through it so a large file is neither reordered nor subject to the
+// writer's drop-oldest buffer.
+type syncWriter interface {
+	applyWrite(ctx context.Context, op writeOp) error
+}
+
+func putHistory(c Cache, runs []models.DAGRun, tasks []models.TaskInstance) (TransferStats, error) {
+	byDAG := make(map[string][]models.DAGRun)
+	for _, r := range runs {
+		byDAG[r.DagId] = append(byDAG[r.DagId], r)
+	}
+	byRun := make(map[[2]string][]models.TaskInstance)
+	for _, ti := range tasks {
+		k := [2]string{ti.DagId, ti.RunId}
+		byRun[k] = append(byRun[k], ti)
+	}
+
+	ops := make([]writeOp, 0, len(byDAG)+len(byRun))
+	dags := make(map[string]bool)
+	for dagId, rs := range byDAG {
+		dags[dagId] = true
+		ops = append(ops, writeOp{kind: writeDAGRuns, dagId: dagId, dagRuns: rs})
+	}
+	for k, ts := range byRun {
+		dags[k[0]] = true
+		ops = append(ops, writeOp{kind: writeTaskInstances, dagId: k[0], runId: k[1], tasks: ts})
+	}
+
+	sw, direct := c.(syncWriter)
+	for _, op := range ops {
+		if !direct {
+			if op.kind == writeDAGRuns {
+				c.PutDAGRuns(op.dagId, op.dagRuns)
+			} else {
+				c.PutTaskInstances(op.dagId, op.runId, op.tasks)
+			}
+			continue
+		}
+		if err := sw.applyWrite(context.Background(), op); err != nil {
+			return TransferStats{}, fmt.Errorf("import %s: %w", op.dagId, err)
+		}
+	}
+	return TransferStats{DAGs: len(dags), Runs: len(runs), Tasks: len(tasks)}, nil
+}
+
+// writeFileAtomic writes through a temp file so a failed export leaves
+// nothing half-written at path.
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	bw := bufio.NewWriter(tmp)
+	if err := write(bw); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// jsonlRecord is one line of a JSON Lines export.
+type jsonlRecord struct {
+	Type         string               `json:"type"`
+	DAGRun       *models.DAGRun       `json:"dag_run,omitempty"`
+	TaskInstance *models.TaskInstance `json:"task_instance,omitempty"`
+}
+
+const (
+	jsonlDAGRun       = "dag_run"
+	jsonlTaskInstance = "task_instance"
+)
+
+func writeJSONL(w io.Writer, runs []models.DAGRun, tasks []models.TaskInstance) error {
+	enc := json.NewEncoder(w)
+	for i := range runs {
+		if err := enc.Encode(jsonlRecord{Type: jsonlDAGRun, DAGRun: &runs[i]}); err != nil {
+			return err
+		}
+	}
+	for i := range tasks {
+		if err := enc.Encode(jsonlRecord{Type: jsonlTaskInstance, TaskInstance: &tasks[i]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readJSONL(path string) ([]models.DAGRun, []models.TaskInstance, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	var (
+		runs  []models.DAGRun
+		tasks []models.TaskInstance
+	)
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if strings.TrimSpace(sc.Text()) == "" {
+			continue
+		}
+		// Exports from before map_index was recorded hold unmapped tasks.
+		rec := jsonlRecord{TaskInstance: &models.TaskInstance{MapIndex: models.NotMapped}}
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return nil, nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		switch {
+		case rec.Type == jsonlDAGRun && rec.DAGRun != nil:
+			runs = append(runs, *rec.DAGRun)
+		case rec.Type == jsonlTaskInstance && rec.TaskInstance != nil:
+			tasks = append(tasks, *rec.TaskInstance)
+		default:
+			return nil, nil, fmt.Errorf("%s:%d: unknown record type %q", path, line, rec.Type)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, err
+	}
+	return runs, tasks, nil
+}
+
+var (
+	csvRunHeader = []string{
+		"dag_id", "run_id", "state", "run_type", "logical_date", "run_after",
+		"start_date", "end_date", "duration_seconds", "note",
+	}
+	csvTaskHeader = []string{
+		"dag_id", "run_id", "task_id", "map_index", "rendered_map_index", "state", "try_number",
+		"operator", "pool", "queue", "hostname", "queued_at", "start_date", "end_date",
+		"duration_seconds", "queue_seconds",
+	}
+)
+
+func writeCSV(dir string, runs []models.DAGRun, tasks []models.TaskInstance) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	err := writeFileAtomic(filepath.Join(dir, csvRunsFile), func(w io.Writer) error {
+		cw := csv.NewWriter(w)
+		_ = cw.Write(csvRunHeader)
+		for _, r := range runs {
+			duration := ""
+			if !r.StartDate.IsZero() && !r.EndDate.IsZero() {
+				duration = formatSeconds(r.EndDate.Sub(r.StartDate))
+			}
+			_ = cw.Write([]string{
+				r.DagId, r.RunId, r.State, r.RunType, formatTime(r.LogicalDate), formatTime(r.RunAfter),
+				formatTime(r.StartDate), formatTime(r.EndDate), duration, r.Note,
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, csvTasksFile), func(w io.Writer) error {
+		cw := csv.NewWriter(w)
+		_ = cw.Write(csvTaskHeader)
+		for _, ti := range tasks {
+			duration, queue := "", ""
+			if d := taskDuration(ti); d > 0 {
+				duration = formatSeconds(d)
+			}
+			if q := queueDuration(ti); q > 0 {
+				queue = formatSeconds(q)
+			}
+			_ = cw.Write([]string{
+				ti.DagId, ti.RunId, ti.TaskId, strconv.Itoa(ti.MapIndex), ti.RenderedMapIndex, ti.State, strconv.Itoa(ti.TryNumber),
+				ti.Operator, ti.Pool, ti.Queue, ti.Hostname, formatTimePtr(ti.QueuedDttm), formatTimePtr(ti.StartDate), formatTimePtr(ti.EndDate),
+				duration, queue,
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	})
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+// readCSV reads an export directory (or either of its files). Columns are
+// matched by header name, so spreadsheets that reorder or add columns still
+// import; derived columns (durations) are ignored.
+func readCSV(path string) ([]models.DAGRun, []models.TaskInstance, error) {
+	dir := path
+	if fi, err := os.Stat(path); err != nil {
+		return nil, nil, err
+	} else if !fi.IsDir() {
+		dir = filepath.Dir(path)
+	}
+	var (
+		runs  []models.DAGRun
+		tasks []models.TaskInstance
+	)
+	err := readCSVFile(filepath.Join(dir, csvRunsFile), func(get func(string) string) error {
+		runs = append(runs, models.DAGRun{
+			DagId:       get("dag_id"),
+			RunId:       get("run_id"),
+			State:       get("state"),
+			RunType:     get("run_type"),
+			LogicalDate: parseCSVTime(get("logical_date")),
+			RunAfter:    parseCSVTime(get("run_after")),
+			StartDate:   parseCSVTime(get("start_date")),
+			EndDate:     parseCSVTime(get("end_date")),
+			Note:        get("note"),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	err = readCSVFile(filepath.Join(dir, csvTasksFile), func(get func(string) string) error {
+		try, _ := strconv.Atoi(get("try_number"))
+		mapIndex, err := strconv.Atoi(get("map_index"))
+		if err != nil {
+			mapIndex = models.NotMapped // an export from before map_index was recorded
+		}
+		ti := models.TaskInstance{
+			DagId:            get("dag_id"),
+			RunId:            get("run_id"),
+			TaskId:           get("task_id"),
+			MapIndex:         mapIndex,
+			RenderedMapIndex: get("rendered_map_index"),
+			State:            get("state"),
+			TryNumber:        try,
+			Operator:         get("operator"),
+			Pool:             get("pool"),
+			Queue:            get("queue"),
+			Hostname:         get("hostname"),
+			QueuedDttm:       parseCSVTimePtr(get("queued_at")),
+			StartDate:        parseCSVTimePtr(get("start_date")),
+			EndDate:          parseCSVTimePtr(get("end_date")),
+		}
+		if d, err := strconv.ParseFloat(get("duration_seconds"), 64); err == nil {
+			ti.Duration = d
+		}
+		tasks = append(tasks, ti)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return runs, tasks, nil
+}
+
+// readCSVFile calls row for each record of a headed CSV file. A missing file
+// is not an error: an export may hold only one of the two tables.
+func readCSVFile(path string, row func(get func(string) string) error) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cr := csv.NewReader(bufio.NewReader(f))
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[strings.TrimSpace(h)] = i
+	}
+	for _, required := range []string{"dag_id", "run_id"} {
+		if _, ok := cols[required]; !ok {
+			return fmt.Errorf("%s: missing %s column", path, required)
+		}
+	}
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		get := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(rec) {
+				return rec[i]
+			}
+			return ""
+		}
+		if err := row(get); err != nil {
+			return err
+		}
+	}
+}
+
+func parseCSVTime(s string) time.Time {
+	return parseTime(sql.NullString{String: strings.TrimSpace(s), Valid: true})
+}
+
+func parseCSVTimePtr(s string) *time.Time {
+	return parseTimePtr(sql.NullString{String: strings.TrimSpace(s), Valid: true})
+}
+
+// writeSnapshot creates a fresh cache-schema database at path holding runs
+// and tasks; it can be opened with sqlite3 or imported by another lazyflow.
+func writeSnapshot(path string, runs []models.DAGRun, tasks []models.TaskInstance) error {
+	tmp := path + ".tmp"
+	_ = os.Remove(tmp)
+	snap, err := openSQLite(tmp, Options{})
+	if err != nil {
+		return err
+	}
+	_, err = putHistory(snap, runs, tasks)
+	if cerr := snap.db.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readSnapshot reads every run and task from a cache-schema database without
+// modifying it, so a teammate's file is safe to import in place.
+func readSnapshot(path string) ([]models.DAGRun, []models.TaskInstance, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil, err
+	}
+	dsn, err := sqliteDSN(path, "mode=ro")
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer db.Close()
+
+	var version int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version); err != nil {
+		return nil, nil, fmt.Errorf("%s is not a lazyflow cache: %w", path, err)
+	}
+	if latest := latestSchemaVersion(); version > latest {
+		return nil, nil, fmt.Errorf("%w: %s is v%d, this build supports up to v%d", ErrSchemaTooNew, path, version, latest)
+	} else if version < latest {
+		// An older lazyflow wrote it; the queries below expect the current
+		// schema, so read a migrated copy rather than touch the file.
+		_ = db.Close()
+		return readOldSnapshot(path)
+	}
+
+	snap := &sqliteCache{db: db}
+	runs, _ := snap.GetAllDAGRunsHistory(time.Time{}, 0)
+	dags := make(map[string]bool)
+	for _, r := range runs {
+		dags[r.DagId] = true
+	}
+	rows, err := db.Query(`SELECT DISTINCT dag_id FROM task_instances`)
+	if err != nil {
+		return nil, nil, err
+	}
+	for rows.Next() {
+		var dagId string
+		if err := rows.Scan(&dagId); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		dags[dagId] = true
+	}
+	rows.Close()
+
+	var tasks []models.TaskInstance
+	for _, dagId := range sortedDagIds(dags) {
+		ts, _ := snap.GetTaskInstancesHistory(dagId, time.Time{}, 0)
+		tasks = append(tasks, ts...)
+	}
+	return runs, tasks, nil
+}
+
+// readOldSnapshot migrates a temporary copy of an older snapshot to the
+// current schema and reads that.
+func readOldSnapshot(path string) ([]models.DAGRun, []models.TaskInstance, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer src.Close()
+	tmp, err := os.CreateTemp("", "lazyflow-snapshot-*.db")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.Copy(tmp, src)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("copy %s: %w", path, err)
+	}
+
+	dsn, err := sqliteDSN(tmp.Name(), "")
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	err = migrate(context.Background(), db)
+	if cerr := db.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("upgrade %s: %w", path, err)
+	}
+	return readSnapshot(tmp.Name())
+}
+
+func sortedDagIds(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
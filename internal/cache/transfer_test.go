@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+func seedTransferCache(now time.Time) Cache {
+	c := NewMemory(time.Minute)
+	start, end := now.Add(-50*time.Minute), now.Add(-40*time.Minute)
+	c.PutDAGRuns("etl", []models.DAGRun{
+		{DagId: "etl", RunId: "r1", State: "success", RunAfter: now.Add(-time.Hour), StartDate: start, EndDate: end, Note: "a, \"quoted\" note"},
+		{DagId: "etl", RunId: "r2", State: "failed", RunAfter: now.Add(-3 * 24 * time.Hour)},
+	})
+	c.PutDAGRuns("report", []models.DAGRun{
+		{DagId: "report", RunId: "r1", State: "running", RunAfter: now.Add(-time.Hour)},
+	})
+	c.PutTaskInstances("etl", "r1", []models.TaskInstance{
+		{DagId: "etl", RunId: "r1", TaskId: "extract", State: "success", TryNumber: 2, StartDate: &start, EndDate: &end, Pool: "default_pool"},
+	})
+	return c
+}
+
+func TestTransfer_roundTripEachFormat(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	src := seedTransferCache(now)
+	names := map[Format]string{FormatCSV: "out", FormatJSONL: "out.jsonl", FormatSQLite: "out.db"}
+
+	for _, format := range Formats {
+		t.Run(string(format), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), names[format])
+			stats, err := Export(src, path, format, ExportFilter{Since: now.Add(-24 * time.Hour)})
+			if err != nil {
+				t.Fatalf("Export: %v", err)
+			}
+			if stats != (TransferStats{DAGs: 2, Runs: 2, Tasks: 1}) {
+				t.Fatalf("export stats = %+v", stats)
+			}
+			if _, err := Export(src, path, format, ExportFilter{}); err == nil {
+				t.Fatal("export must not overwrite an existing file")
+			}
+
+			dst := newTestSQLite(t, 30*24*time.Hour)
+			defer dst.Close()
+			stats, err = Import(dst, path, format)
+			if err != nil {
+				t.Fatalf("Import: %v", err)
+			}
+			if stats.Runs != 2 || stats.Tasks != 1 {
+				t.Fatalf("import stats = %+v", stats)
+			}
+			runs, _ := dst.GetDAGRunsHistory("etl", time.Time{}, 0)
+			if len(runs) != 1 || runs[0].RunId != "r1" || !runs[0].EndDate.Equal(now.Add(-40*time.Minute)) || runs[0].Note != "a, \"quoted\" note" {
+				t.Fatalf("etl runs = %+v", runs)
+			}
+			tasks, _ := dst.GetTaskInstancesHistory("etl", time.Time{}, 0)
+			if len(tasks) != 1 || tasks[0].TryNumber != 2 || tasks[0].Pool != "default_pool" || taskDuration(tasks[0]) != 10*time.Minute {
+				t.Fatalf("etl tasks = %+v", tasks)
+			}
+		})
+	}
+}
+
+func TestTransfer_exportFilter(t *testing.T) {
+	now := time.Now().UTC()
+	src := seedTransferCache(now)
+	runs, tasks := collectHistory(src, ExportFilter{DagIds: []string{"etl"}, Until: now.Add(-2 * time.Hour)})
+	if len(runs) != 1 || runs[0].RunId != "r2" {
+		t.Fatalf("runs = %+v", runs)
+	}
+	if len(tasks) != 0 {
+		t.Fatalf("tasks after until = %+v", tasks)
+	}
+}
+
+func TestFormatForPath(t *testing.T) {
+	for path, want := range map[string]Format{
+		"h.jsonl": FormatJSONL, "h.db": FormatSQLite, "h.csv": FormatCSV, t.TempDir(): FormatCSV,
+	} {
+		if got, err := FormatForPath(path); err != nil || got != want {
+			t.Errorf("FormatForPath(%q) = %q, %v; want %q", path, got, err, want)
+		}
+	}
+	if _, err := FormatForPath("history.txt"); err == nil {
+		t.Error("expected an error for an unknown extension")
+	}
+}
+
+func TestTransfer_snapshotPathWithURIChars(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	dir := filepath.Join(t.TempDir(), "week?1#a%20b")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "snap.db")
+	if _, err := Export(seedTransferCache(now), path, FormatSQLite, ExportFilter{}); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	dst := newTestSQLite(t, 30*24*time.Hour)
+	defer dst.Close()
+	stats, err := Import(dst, path, FormatSQLite)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if stats.Runs != 3 || stats.Tasks != 1 {
+		t.Fatalf("import stats = %+v", stats)
+	}
+}
+
+func TestTransfer_importsSnapshotFromOlderSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := migrations
+	migrations = saved[:4]
+	err = migrate(t.Context(), db)
+	migrations = saved
+	if err != nil {
+		t.Fatalf("migrate v4: %v", err)
+	}
+	start := formatTime(time.Now().Add(-time.Hour))
+	if _, err := db.Exec(`INSERT INTO task_instances (dag_id, run_id, task_id, state, start_date, updated_at, raw_json)
+VALUES ('etl', 'r1', 'extract', 'success', ?, ?, '{}')`, start, start); err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Close()
+
+	dst := newTestSQLite(t, 30*24*time.Hour)
+	defer dst.Close()
+	stats, err := Import(dst, path, FormatSQLite)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if stats.Tasks != 1 {
+		t.Fatalf("import stats = %+v", stats)
+	}
+	tasks, _ := dst.GetTaskInstancesHistory("etl", time.Time{}, 0)
+	if len(tasks) != 1 || tasks[0].MapIndex != models.NotMapped {
+		t.Fatalf("etl tasks = %+v", tasks)
+	}
+	var version int
+	check, _ := sql.Open("sqlite", path)
+	defer check.Close()
+	if err := check.QueryRow(`SELECT MAX(version) FROM schema_version`).Scan(&version); err != nil || version != 4 {
+		t.Fatalf("the imported file was changed: v%d, %v", version, err)
+	}
+}
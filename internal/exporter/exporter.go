@@ -0,0 +1,340 @@
+// Package exporter serves lazyflow's cluster rollups as Prometheus metrics for
+// the headless `lazyflow exporter` mode.
+package exporter
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yjinheon/lazyflow/internal/api"
+	"github.com/yjinheon/lazyflow/internal/app"
+	"github.com/yjinheon/lazyflow/internal/cache"
+	"github.com/yjinheon/lazyflow/internal/metrics"
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+// Poll sources, used as the "source" label on the exporter's own metrics.
+const (
+	sourceRuns   = "dag_runs"
+	sourceTasks  = "task_instances"
+	sourcePools  = "pools"
+	sourceHealth = "health"
+)
+
+// pageLimit bounds each list call; a truncated page is logged, not paged.
+const pageLimit = 1000
+
+// Intervals sets how often each source is polled.
+type Intervals struct {
+	Runs   time.Duration
+	Pools  time.Duration
+	Health time.Duration
+}
+
+// Exporter polls Airflow and keeps the latest snapshot of each source. Metrics
+// are computed from the snapshot on scrape, so a failing poll keeps serving the
+// last good data while lazyflow_exporter_poll_errors_total climbs.
+type Exporter struct {
+	client *api.Client
+	cache  cache.Cache
+	window time.Duration
+	now    func() time.Time
+
+	mu          sync.RWMutex
+	runs        []models.DAGRun       // window runs, cache history merged with the last poll
+	tasks       []models.TaskInstance // window task instances from the last poll
+	pools       []models.Pool
+	health      *models.HealthInfo
+	lastSuccess map[string]time.Time
+	pollErrors  map[string]int
+}
+
+// New builds an exporter over window (the lookback for run and task stats).
+func New(client *api.Client, c cache.Cache, window time.Duration) *Exporter {
+	return &Exporter{
+		client:      client,
+		cache:       c,
+		window:      window,
+		now:         time.Now,
+		lastSuccess: make(map[string]time.Time),
+		pollErrors:  make(map[string]int),
+	}
+}
+
+// Start registers the poll loops on p. Each loop runs immediately.
+func (e *Exporter) Start(p *app.Poller, iv Intervals) {
+	p.Fixed(iv.Runs, true, e.pollRuns)
+	p.Fixed(iv.Runs, true, e.pollTasks)
+	p.Fixed(iv.Pools, true, e.pollPools)
+	p.Fixed(iv.Health, true, e.pollHealth)
+}
+
+func (e *Exporter) pollRuns(ctx context.Context) error {
+	since := e.now().Add(-e.window)
+	col, err := e.client.GetAllDAGRuns(ctx, &api.ListOptions{
+		Limit:          pageLimit,
+		OrderBy:        "-run_after",
+		LogicalDateGte: since,
+	})
+	if err != nil {
+		e.fail(sourceRuns, err)
+		return err
+	}
+	if col.TotalEntries > len(col.DAGRuns) {
+		log.Printf("[ERROR] exporter: dag runs truncated: total=%d fetched=%d", col.TotalEntries, len(col.DAGRuns))
+	}
+	byDAG := make(map[string][]models.DAGRun)
+	for _, r := range col.DAGRuns {
+		byDAG[r.DagId] = append(byDAG[r.DagId], r)
+	}
+	for dagId, runs := range byDAG {
+		e.cache.PutDAGRuns(dagId, runs)
+	}
+	// The cache holds runs an earlier, truncated poll never saw; the fresh
+	// poll wins for runs present in both.
+	history, _ := e.cache.GetAllDAGRunsHistory(since, 20*pageLimit)
+	runs := mergeRuns(history, col.DAGRuns)
+
+	e.mu.Lock()
+	e.runs = runs
+	e.mu.Unlock()
+	e.succeed(sourceRuns)
+	return nil
+}
+
+func (e *Exporter) pollTasks(ctx context.Context) error {
+	col, err := e.client.GetTaskInstances(ctx, "~", "~", &api.ListOptions{
+		Limit:          pageLimit,
+		OrderBy:        "-start_date",
+		LogicalDateGte: e.now().Add(-e.window),
+	})
+	if err != nil {
+		e.fail(sourceTasks, err)
+		return err
+	}
+	byRun := make(map[[2]string][]models.TaskInstance)
+	for _, ti := range col.TaskInstances {
+		k := [2]string{ti.DagId, ti.RunId}
+		byRun[k] = append(byRun[k], ti)
+	}
+	for k, tis := range byRun {
+		e.cache.PutTaskInstances(k[0], k[1], tis)
+	}
+
+	e.mu.Lock()
+	e.tasks = col.TaskInstances
+	e.mu.Unlock()
+	e.succeed(sourceTasks)
+	return nil
+}
+
+func (e *Exporter) pollPools(ctx context.Context) error {
+	col, err := e.client.ListPools(ctx, &api.ListOptions{Limit: 100})
+	if err != nil {
+		e.fail(sourcePools, err)
+		return err
+	}
+	e.mu.Lock()
+	e.pools = col.Pools
+	e.mu.Unlock()
+	e.succeed(sourcePools)
+	return nil
+}
+
+func (e *Exporter) pollHealth(ctx context.Context) error {
+	h, err := e.client.GetHealth(ctx)
+	if err != nil {
+		e.fail(sourceHealth, err)
+		return err
+	}
+	e.mu.Lock()
+	e.health = h
+	e.mu.Unlock()
+	e.succeed(sourceHealth)
+	return nil
+}
+
+func (e *Exporter) fail(source string, err error) {
+	log.Printf("[ERROR] exporter: poll %s: %v", source, err)
+	e.mu.Lock()
+	e.pollErrors[source]++
+	e.mu.Unlock()
+}
+
+func (e *Exporter) succeed(source string) {
+	e.mu.Lock()
+	e.lastSuccess[source] = e.now()
+	e.mu.Unlock()
+}
+
+// ServeHTTP writes the current metrics in the Prometheus text format.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	e.write(&textWriter{w: w})
+}
+
+func (e *Exporter) write(tw *textWriter) {
+	now := e.now()
+	byDAG := make(map[string][]models.DAGRun)
+	for _, r := range e.runs {
+		byDAG[r.DagId] = append(byDAG[r.DagId], r)
+	}
+	dagIds := make([]string, 0, len(byDAG))
+	for dagId := range byDAG {
+		dagIds = append(dagIds, dagId)
+	}
+	sort.Strings(dagIds)
+
+	tw.family("lazyflow_dag_runs", "DAG runs in the window by state.", "gauge")
+	for _, dagId := range dagIds {
+		counts := map[string]int{}
+		for _, r := range byDAG[dagId] {
+			counts[r.State]++
+		}
+		for _, state := range sortedKeys(counts) {
+			tw.sample("lazyflow_dag_runs", labels{"dag_id", dagId, "state", state}, float64(counts[state]))
+		}
+	}
+
+	latest := metrics.RollupLatestState(e.runs)
+	tw.family("lazyflow_dag_latest_run_state", "1 for the state of each DAG's most recent run.", "gauge")
+	for _, dagId := range dagIds {
+		if state := latest[dagId]; state != "" {
+			tw.sample("lazyflow_dag_latest_run_state", labels{"dag_id", dagId, "state", state}, 1)
+		}
+	}
+
+	tw.family("lazyflow_dag_failure_streak", "Consecutive failed runs, most recent first.", "gauge")
+	for _, dagId := range dagIds {
+		tw.sample("lazyflow_dag_failure_streak", labels{"dag_id", dagId}, float64(metrics.FailureStreak(byDAG[dagId])))
+	}
+
+	tw.family("lazyflow_dag_run_duration_seconds", "Nearest-rank duration percentiles of finished runs in the window.", "gauge")
+	for _, dagId := range dagIds {
+		p50, p90, p99 := metrics.Percentiles(byDAG[dagId])
+		if p50 == 0 {
+			continue
+		}
+		for _, q := range []struct {
+			quantile string
+			d        time.Duration
+		}{{"0.5", p50}, {"0.9", p90}, {"0.99", p99}} {
+			tw.sample("lazyflow_dag_run_duration_seconds", labels{"dag_id", dagId, "quantile", q.quantile}, q.d.Seconds())
+		}
+	}
+
+	tasksByDAG := make(map[string][]models.TaskInstance)
+	for _, ti := range e.tasks {
+		tasksByDAG[ti.DagId] = append(tasksByDAG[ti.DagId], ti)
+	}
+	tw.family("lazyflow_dag_task_queue_seconds", "Mean task queue wait (queued to start) in the window.", "gauge")
+	for _, dagId := range sortedKeys(tasksByDAG) {
+		if q := metrics.AvgQueueTime(tasksByDAG[dagId]); q > 0 {
+			tw.sample("lazyflow_dag_task_queue_seconds", labels{"dag_id", dagId}, q.Seconds())
+		}
+	}
+
+	tw.family("lazyflow_pool_slots", "Pool slots by use.", "gauge")
+	for _, p := range e.pools {
+		for _, s := range []struct {
+			state string
+			n     int
+		}{
+			{"total", p.Slots}, {"occupied", p.OccupiedSlots}, {"running", p.RunningSlots},
+			{"queued", p.QueuedSlots}, {"scheduled", p.ScheduledSlots}, {"deferred", p.DeferredSlots},
+			{"open", p.OpenSlots},
+		} {
+			tw.sample("lazyflow_pool_slots", labels{"pool", p.Name, "state", s.state}, float64(s.n))
+		}
+	}
+
+	components := healthComponents(e.health)
+	tw.family("lazyflow_component_healthy", "1 when the component reports healthy.", "gauge")
+	for _, c := range components {
+		healthy := 0.0
+		if c.status.Status == "healthy" {
+			healthy = 1
+		}
+		tw.sample("lazyflow_component_healthy", labels{"component", c.name}, healthy)
+	}
+	tw.family("lazyflow_component_heartbeat_lag_seconds", "Seconds since the component's latest heartbeat.", "gauge")
+	for _, c := range components {
+		hb, err := time.Parse(time.RFC3339Nano, c.heartbeat)
+		if err != nil {
+			continue
+		}
+		tw.sample("lazyflow_component_heartbeat_lag_seconds", labels{"component", c.name}, now.Sub(hb).Seconds())
+	}
+
+	sources := []string{sourceRuns, sourceTasks, sourcePools, sourceHealth}
+	tw.family("lazyflow_exporter_last_success_timestamp_seconds", "Unix time of the last successful poll.", "gauge")
+	for _, s := range sources {
+		if t, ok := e.lastSuccess[s]; ok {
+			tw.sample("lazyflow_exporter_last_success_timestamp_seconds", labels{"source", s}, float64(t.UnixNano())/1e9)
+		}
+	}
+	tw.family("lazyflow_exporter_poll_errors_total", "Failed polls since start.", "counter")
+	for _, s := range sources {
+		tw.sample("lazyflow_exporter_poll_errors_total", labels{"source", s}, float64(e.pollErrors[s]))
+	}
+}
+
+type component struct {
+	name      string
+	status    *models.HealthStatus
+	heartbeat string
+}
+
+// healthComponents flattens the health response; absent components are skipped.
+func healthComponents(h *models.HealthInfo) []component {
+	if h == nil {
+		return nil
+	}
+	var out []component
+	if h.Metadatabase != nil {
+		out = append(out, component{"metadatabase", h.Metadatabase, ""})
+	}
+	if h.Scheduler != nil {
+		out = append(out, component{"scheduler", h.Scheduler, h.Scheduler.LatestSchedulerHeartbeat})
+	}
+	if h.Triggerer != nil {
+		out = append(out, component{"triggerer", h.Triggerer, h.Triggerer.LatestTriggererHeartbeat})
+	}
+	if h.DagProcessor != nil {
+		out = append(out, component{"dag_processor", h.DagProcessor, h.DagProcessor.LatestDagProcessorHeartbeat})
+	}
+	return out
+}
+
+// mergeRuns unions run slices by (dag, run) id; later slices win.
+func mergeRuns(sets ...[]models.DAGRun) []models.DAGRun {
+	idx := make(map[[2]string]int)
+	var out []models.DAGRun
+	for _, set := range sets {
+		for _, r := range set {
+			k := [2]string{r.DagId, r.RunId}
+			if i, ok := idx[k]; ok {
+				out[i] = r
+				continue
+			}
+			idx[k] = len(out)
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,96 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yjinheon/lazyflow/internal/api"
+	"github.com/yjinheon/lazyflow/internal/cache"
+)
+
+func TestExporterServesMetrics(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	ts := func(d time.Duration) string { return now.Add(d).Format(time.RFC3339) }
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body any
+		switch r.URL.Path {
+		case "/api/v2/dags/~/dagRuns":
+			body = map[string]any{"total_entries": 3, "dag_runs": []map[string]any{
+				{"dag_id": "etl", "dag_run_id": "r3", "state": "failed", "run_after": ts(-time.Hour), "start_date": ts(-time.Hour), "end_date": ts(-50 * time.Minute)},
+				{"dag_id": "etl", "dag_run_id": "r2", "state": "failed", "run_after": ts(-2 * time.Hour), "start_date": ts(-2 * time.Hour), "end_date": ts(-110 * time.Minute)},
+				{"dag_id": "etl", "dag_run_id": "r1", "state": "success", "run_after": ts(-3 * time.Hour), "start_date": ts(-3 * time.Hour), "end_date": ts(-170 * time.Minute)},
+			}}
+		case "/api/v2/dags/~/dagRuns/~/taskInstances":
+			body = map[string]any{"total_entries": 1, "task_instances": []map[string]any{
+				{"dag_id": "etl", "dag_run_id": "r1", "task_id": "load", "state": "success", "queued_when": ts(-3 * time.Hour), "start_date": ts(-3*time.Hour + 30*time.Second)},
+			}}
+		case "/api/v2/pools":
+			body = map[string]any{"total_entries": 1, "pools": []map[string]any{
+				{"name": "default_pool", "slots": 128, "running_slots": 3, "open_slots": 125},
+			}}
+		case "/api/v2/monitor/health":
+			body = map[string]any{"scheduler": map[string]any{"status": "healthy", "latest_scheduler_heartbeat": ts(-5 * time.Second)}}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	defer srv.Close()
+
+	e := New(api.NewClient(api.ClientConfig{BaseURL: srv.URL, Token: "test"}), cache.NewMemory(time.Minute), 24*time.Hour)
+	e.now = func() time.Time { return now }
+	ctx := context.Background()
+	e.pollRuns(ctx)
+	e.pollTasks(ctx)
+	e.pollPools(ctx)
+	e.pollHealth(ctx)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	out := rec.Body.String()
+	for _, want := range []string{
+		`lazyflow_dag_runs{dag_id="etl",state="failed"} 2`,
+		`lazyflow_dag_runs{dag_id="etl",state="success"} 1`,
+		`lazyflow_dag_latest_run_state{dag_id="etl",state="failed"} 1`,
+		`lazyflow_dag_failure_streak{dag_id="etl"} 2`,
+		`lazyflow_dag_run_duration_seconds{dag_id="etl",quantile="0.5"} 600`,
+		`lazyflow_dag_task_queue_seconds{dag_id="etl"} 30`,
+		`lazyflow_pool_slots{pool="default_pool",state="total"} 128`,
+		`lazyflow_pool_slots{pool="default_pool",state="running"} 3`,
+		`lazyflow_component_healthy{component="scheduler"} 1`,
+		`lazyflow_component_heartbeat_lag_seconds{component="scheduler"} 5`,
+		`lazyflow_exporter_poll_errors_total{source="dag_runs"} 0`,
+		"# TYPE lazyflow_exporter_poll_errors_total counter",
+	} {
+		if !strings.Contains(out, want+"\n") {
+			t.Errorf("metrics missing %q\n%s", want, out)
+		}
+	}
+}
+
+func TestExporterKeepsLastSnapshotOnError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	e := New(api.NewClient(api.ClientConfig{BaseURL: srv.URL, Token: "test"}), cache.NewMemory(time.Minute), time.Hour)
+	e.pollHealth(context.Background())
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), `lazyflow_exporter_poll_errors_total{source="health"} 1`) {
+		t.Fatalf("error not counted:\n%s", rec.Body.String())
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	if got := escapeLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Fatalf("escapeLabel = %q", got)
+	}
+}
@@ -0,0 +1,63 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// labels is a flat name, value, name, value... list, kept in the given order.
+type labels []string
+
+// textWriter emits the Prometheus text exposition format (version 0.0.4).
+// Write errors are sticky: after the first, further output is dropped, which
+// is all an HTTP handler can do once the client has gone away.
+type textWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (t *textWriter) family(name, help, typ string) {
+	t.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func (t *textWriter) sample(name string, l labels, v float64) {
+	var b strings.Builder
+	b.WriteString(name)
+	if len(l) > 0 {
+		b.WriteByte('{')
+		for i := 0; i+1 < len(l); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(&b, "%s=\"%s\"", l[i], escapeLabel(l[i+1]))
+		}
+		b.WriteByte('}')
+	}
+	t.printf("%s %s\n", b.String(), formatValue(v))
+}
+
+func (t *textWriter) printf(format string, args ...any) {
+	if t.err != nil {
+		return
+	}
+	_, t.err = fmt.Fprintf(t.w, format, args...)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string { return labelEscaper.Replace(v) }
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,167 @@
+// Package fakeairflow is an in-process stand-in for the Airflow 3 REST API:
+// DAGs whose scheduled runs progress through queued, running and a final
+// state, task instances with retries and logs, pools, backfills and health,
+// all driven by a scenario file. It backs `lazyflow --demo` and end-to-end
+// tests; it covers the endpoints lazyflow calls, not the whole API.
+package fakeairflow
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario describes the simulated cluster. Durations are Go durations
+// ("30s", "5m").
+type Scenario struct {
+	// Speed runs the simulated clock faster than the wall clock; 0 means 1.
+	Speed float64 `yaml:"speed"`
+	// QueueDelay is how long a run sits queued before it starts; 0 means 2s.
+	QueueDelay time.Duration `yaml:"queue_delay"`
+	// RetryDelay is the wait between a failed try and the next; 0 means 10s.
+	RetryDelay time.Duration `yaml:"retry_delay"`
+
+	DAGs        []DAGSpec         `yaml:"dags"`
+	Pools       []PoolSpec        `yaml:"pools"`
+	Connections []ConnectionSpec  `yaml:"connections"`
+	Variables   map[string]string `yaml:"variables"`
+	// Health is the initial status per component (metadatabase, scheduler,
+	// triggerer, dag_processor); unlisted components are healthy.
+	Health map[string]string `yaml:"health"`
+	// Events are scripted changes, applied when the simulated clock passes
+	// their After offset from the start.
+	Events []Event `yaml:"events"`
+}
+
+// DAGSpec is one simulated DAG. A DAG with no Every only runs when
+// triggered or backfilled.
+type DAGSpec struct {
+	ID          string        `yaml:"id"`
+	Description string        `yaml:"description"`
+	Owners      []string      `yaml:"owners"`
+	Tags        []string      `yaml:"tags"`
+	Every       time.Duration `yaml:"every"`
+	Paused      bool          `yaml:"paused"`
+	// History is how many scheduled runs exist at start; 0 means 10.
+	History int        `yaml:"history"`
+	Tasks   []TaskSpec `yaml:"tasks"`
+	// Source is shown in the Code tab; empty generates a stub.
+	Source string `yaml:"source"`
+}
+
+// TaskSpec is one task. It starts once every task in After has succeeded.
+// Dots in the id place it in task groups: "load.marts.sales" is in the
+// "load.marts" group inside "load".
+type TaskSpec struct {
+	ID       string        `yaml:"id"`
+	Operator string        `yaml:"operator"`
+	After    []string      `yaml:"after"`
+	Duration time.Duration `yaml:"duration"` // 0 means 10s; each try varies ±20%
+	// FailRate is the chance each try fails, decided by a hash of the run,
+	// task and try so a scenario replays identically.
+	FailRate float64 `yaml:"fail_rate"`
+	Retries  int     `yaml:"retries"`
+	Pool     string  `yaml:"pool"`
+	// Expand maps the task over these inputs: it runs once per item, each
+	// expansion labelled by its item as a map_index_template would.
+	Expand []string `yaml:"expand"`
+}
+
+type PoolSpec struct {
+	Name  string `yaml:"name"`
+	Slots int    `yaml:"slots"`
+}
+
+type ConnectionSpec struct {
+	ID   string `yaml:"id"`
+	Type string `yaml:"type"`
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+}
+
+// Event is a scripted change. Every field that is set applies.
+type Event struct {
+	After time.Duration `yaml:"after"`
+	// Health sets component statuses ("unhealthy", "healthy").
+	Health map[string]string `yaml:"health"`
+	// Outage answers every request with 503 for this long.
+	Outage  time.Duration `yaml:"outage"`
+	Pause   string        `yaml:"pause"`   // DAG id
+	Unpause string        `yaml:"unpause"` // DAG id
+	Trigger string        `yaml:"trigger"` // DAG id
+}
+
+//go:embed demo.yaml
+var demoScenario []byte
+
+// DefaultScenario is the built-in demo: a handful of DAGs on short
+// schedules with the odd failure and retry, and a scheduler hiccup.
+func DefaultScenario() Scenario {
+	sc, err := ParseScenario(demoScenario)
+	if err != nil {
+		panic(fmt.Sprintf("fakeairflow: built-in scenario: %v", err))
+	}
+	return sc
+}
+
+// LoadScenario reads a scenario file.
+func LoadScenario(path string) (Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Scenario{}, err
+	}
+	sc, err := ParseScenario(data)
+	if err != nil {
+		return Scenario{}, fmt.Errorf("scenario %s: %w", path, err)
+	}
+	return sc, nil
+}
+
+// ParseScenario decodes and checks a scenario.
+func ParseScenario(data []byte) (Scenario, error) {
+	var sc Scenario
+	if err := yaml.Unmarshal(data, &sc); err != nil {
+		return Scenario{}, err
+	}
+	return sc, sc.validate()
+}
+
+func (sc Scenario) validate() error {
+	dags := make(map[string]bool, len(sc.DAGs))
+	for _, d := range sc.DAGs {
+		if d.ID == "" {
+			return fmt.Errorf("dag without an id")
+		}
+		if dags[d.ID] {
+			return fmt.Errorf("dag %s: declared twice", d.ID)
+		}
+		dags[d.ID] = true
+		if len(d.Tasks) == 0 {
+			return fmt.Errorf("dag %s: no tasks", d.ID)
+		}
+		tasks := make(map[string]bool, len(d.Tasks))
+		for _, t := range d.Tasks {
+			if t.ID == "" || tasks[t.ID] {
+				return fmt.Errorf("dag %s: task ids must be set and unique", d.ID)
+			}
+			// Upstream tasks must come first, which also rules out cycles.
+			for _, up := range t.After {
+				if !tasks[up] {
+					return fmt.Errorf("dag %s: task %s runs after %q, which is not declared before it", d.ID, t.ID, up)
+				}
+			}
+			tasks[t.ID] = true
+		}
+	}
+	for _, e := range sc.Events {
+		for _, id := range []string{e.Pause, e.Unpause, e.Trigger} {
+			if id != "" && !dags[id] {
+				return fmt.Errorf("event at %s: unknown dag %s", e.After, id)
+			}
+		}
+	}
+	return nil
+}
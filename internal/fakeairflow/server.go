@@ -0,0 +1,767 @@
+package fakeairflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+// Token is the bearer token /auth/token hands out; every API request must
+// carry it.
+const Token = "fake-airflow-token"
+
+// Server serves the simulated API. It is an http.Handler, so tests can wrap
+// it in httptest.NewServer; Start listens on its own for --demo.
+type Server struct {
+	mu  sync.Mutex
+	sim *sim
+	now func() time.Time
+	mux *http.ServeMux
+
+	http *http.Server
+}
+
+// New builds a server for sc. now is the simulated clock; nil runs the
+// wall clock at the scenario's speed from this moment.
+func New(sc Scenario, now func() time.Time) *Server {
+	if now == nil {
+		speed := sc.Speed
+		if speed <= 0 {
+			speed = 1
+		}
+		start := time.Now()
+		now = func() time.Time {
+			return start.Add(time.Duration(float64(time.Since(start)) * speed))
+		}
+	}
+	s := &Server{now: now, sim: newSim(sc, now()), mux: http.NewServeMux()}
+	s.routes()
+	return s
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("POST /auth/token", s.handleToken)
+
+	handle := func(pattern string, h func(http.ResponseWriter, *http.Request, time.Time) (any, error)) {
+		s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer "+Token {
+				writeError(w, http.StatusUnauthorized, "Not authenticated")
+				return
+			}
+			s.mu.Lock()
+			now := s.now()
+			s.sim.advance(now)
+			out, err := h(w, r, now)
+			s.mu.Unlock()
+			if err != nil {
+				var he *httpError
+				if errors.As(err, &he) {
+					writeError(w, he.status, he.msg)
+				} else {
+					writeError(w, http.StatusInternalServerError, err.Error())
+				}
+				return
+			}
+			if text, ok := out.(plainText); ok {
+				w.Header().Set("Content-Type", "text/plain")
+				_, _ = w.Write([]byte(text))
+				return
+			}
+			writeJSON(w, http.StatusOK, out)
+		})
+	}
+	api := func(pattern string, h func(http.ResponseWriter, *http.Request, time.Time) (any, error)) {
+		method, path, _ := strings.Cut(pattern, " ")
+		handle(method+" /api/v2"+path, h)
+	}
+	handle("GET /ui/structure/structure_data", s.structure)
+	api("GET /dags", s.listDAGs)
+	api("PATCH /dags/{dag_id}", s.patchDAG)
+	api("GET /dags/{dag_id}/tasks", s.listTasks)
+	api("GET /dags/{dag_id}/dagRuns", s.listRuns)
+	api("POST /dags/{dag_id}/dagRuns", s.triggerRun)
+	api("PATCH /dags/{dag_id}/dagRuns/{run_id}", s.patchRun)
+	api("GET /dags/{dag_id}/dagRuns/{run_id}/taskInstances", s.listTaskInstances)
+	api("PATCH /dags/{dag_id}/dagRuns/{run_id}/taskInstances/{task_id}", s.patchTaskInstance)
+	api("GET /dags/{dag_id}/dagRuns/{run_id}/taskInstances/{task_id}/logs/{try}", s.taskLogs)
+	api("GET /dagSources/{dag_id}", s.dagSource)
+	api("GET /monitor/health", s.health)
+	api("GET /pools", s.listPools)
+	api("GET /config", s.config)
+	api("GET /connections", s.listConnections)
+	api("GET /variables", s.listVariables)
+	api("GET /backfills", s.listBackfills)
+	api("POST /backfills", s.createBackfill)
+	api("POST /backfills/dryRun", s.dryRunBackfill)
+	api("PATCH /backfills/{id}", s.patchBackfill)
+	api("DELETE /backfills/{id}", s.cancelBackfill)
+}
+
+// ServeHTTP answers 503 for everything during a scripted outage.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	now := s.now()
+	s.sim.advance(now) // applies a scripted outage that is due
+	down := now.Before(s.sim.outage)
+	s.mu.Unlock()
+	if down {
+		writeError(w, http.StatusServiceUnavailable, "Service Unavailable (scripted outage)")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// Start listens on addr ("127.0.0.1:0" picks a free port) and serves in the
+// background, returning the base URL.
+func (s *Server) Start(addr string) (string, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	s.http = &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = s.http.Serve(ln) }()
+	return "http://" + ln.Addr().String(), nil
+}
+
+// Close stops a server started with Start.
+func (s *Server) Close() error {
+	if s.http == nil {
+		return nil
+	}
+	return s.http.Close()
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	var creds struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil || creds.Username == "" {
+		writeError(w, http.StatusUnauthorized, "Invalid credentials")
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"access_token": Token})
+}
+
+// ---------- DAGs ----------
+
+func (s *Server) listDAGs(_ http.ResponseWriter, r *http.Request, _ time.Time) (any, error) {
+	q := r.URL.Query()
+	out := make([]models.DAG, 0, len(s.sim.dags))
+	for _, d := range s.sim.dags {
+		if p := q.Get("paused"); p != "" && strconv.FormatBool(d.paused) != p {
+			continue
+		}
+		out = append(out, s.dagModel(d))
+	}
+	total := len(out)
+	return models.DAGCollection{DAGs: page(out, q), TotalEntries: total}, nil
+}
+
+func (s *Server) patchDAG(_ http.ResponseWriter, r *http.Request, now time.Time) (any, error) {
+	d, err := s.findDAG(r)
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		IsPaused *bool `json:"is_paused"`
+	}
+	if err := decode(r, &body); err != nil {
+		return nil, err
+	}
+	if body.IsPaused != nil {
+		s.sim.setPaused(d, *body.IsPaused, now)
+	}
+	return s.dagModel(d), nil
+}
+
+func (s *Server) dagModel(d *dag) models.DAG {
+	m := models.DAG{
+		DagId:           d.spec.ID,
+		IsPaused:        d.paused,
+		Fileloc:         "/opt/airflow/dags/" + d.spec.ID + ".py",
+		RelativeFileloc: d.spec.ID + ".py",
+		Owners:          d.spec.Owners,
+		LastParsedTime:  s.sim.started,
+		MaxActiveTasks:  16,
+		MaxActiveRuns:   16,
+		BundleName:      "dags-folder",
+	}
+	if d.spec.Description != "" {
+		desc := d.spec.Description
+		m.Description = &desc
+	}
+	if d.spec.Every > 0 {
+		m.TimetableDescription = "Every " + d.spec.Every.String()
+	} else {
+		m.TimetableDescription = "Never, external triggers only"
+	}
+	for _, t := range d.spec.Tags {
+		m.Tags = append(m.Tags, models.DagTag{Name: t})
+	}
+	return m
+}
+
+func (s *Server) listTasks(_ http.ResponseWriter, r *http.Request, _ time.Time) (any, error) {
+	d, err := s.findDAG(r)
+	if err != nil {
+		return nil, err
+	}
+	downstream := map[string][]string{}
+	for _, t := range d.spec.Tasks {
+		for _, up := range t.After {
+			downstream[up] = append(downstream[up], t.ID)
+		}
+	}
+	out := make([]models.Task, 0, len(d.spec.Tasks))
+	for _, t := range d.spec.Tasks {
+		out = append(out, models.Task{
+			TaskId:            t.ID,
+			Owner:             strings.Join(d.spec.Owners, ","),
+			Operator:          operator(t),
+			Pool:              pool(t),
+			Queue:             "default",
+			DownstreamTaskIds: append([]string{}, downstream[t.ID]...),
+			UpstreamTaskIds:   append([]string{}, t.After...),
+			TriggerRule:       "all_success",
+			Retries:           float64(t.Retries),
+			IsMapped:          len(t.Expand) > 0,
+		})
+	}
+	return models.TaskCollection{Tasks: out, TotalEntries: len(out)}, nil
+}
+
+// structure answers the UI graph endpoint. Task ids with dots are placed in
+// task groups named by their prefixes, the way Airflow prefixes the ids of a
+// group's tasks: "extract.api.users" sits in "extract.api" inside "extract".
+func (s *Server) structure(_ http.ResponseWriter, r *http.Request, _ time.Time) (any, error) {
+	id := r.URL.Query().Get("dag_id")
+	d := s.sim.dag(id)
+	if d == nil {
+		return nil, notFound("DAG with dag_id: '%s' not found", id)
+	}
+	var level func(prefix string) []models.StructureNode
+	level = func(prefix string) []models.StructureNode {
+		var nodes []models.StructureNode
+		seen := map[string]bool{}
+		for _, t := range d.spec.Tasks {
+			rest, ok := strings.CutPrefix(t.ID, prefix)
+			if !ok {
+				continue
+			}
+			label, _, nested := strings.Cut(rest, ".")
+			if !nested {
+				nodes = append(nodes, models.StructureNode{Id: t.ID, Label: label, Type: "task"})
+			} else if sub := prefix + label; !seen[sub] {
+				seen[sub] = true
+				nodes = append(nodes, models.StructureNode{Id: sub, Label: label, Type: "task", Children: level(sub + ".")})
+			}
+		}
+		return nodes
+	}
+	return models.DAGStructure{Nodes: level("")}, nil
+}
+
+func (s *Server) dagSource(_ http.ResponseWriter, r *http.Request, _ time.Time) (any, error) {
+	d, err := s.findDAG(r)
+	if err != nil {
+		return nil, err
+	}
+	if d.spec.Source != "" {
+		return plainText(d.spec.Source), nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "from airflow.sdk import DAG, task\n\nwith DAG(%q):\n", d.spec.ID)
+	for _, t := range d.spec.Tasks {
+		fmt.Fprintf(&b, "    %s = %s(task_id=%q)\n", strings.ReplaceAll(t.ID, ".", "_"), operator(t), t.ID)
+	}
+	for _, t := range d.spec.Tasks {
+		for _, up := range t.After {
+			fmt.Fprintf(&b, "    %s >> %s\n", up, t.ID)
+		}
+	}
+	return plainText(b.String()), nil
+}
+
+// ---------- DAG runs ----------
+
+func (s *Server) listRuns(_ http.ResponseWriter, r *http.Request, now time.Time) (any, error) {
+	var dags []*dag
+	if r.PathValue("dag_id") == "~" {
+		dags = s.sim.dags
+	} else {
+		d, err := s.findDAG(r)
+		if err != nil {
+			return nil, err
+		}
+		dags = []*dag{d}
+	}
+	q := r.URL.Query()
+	states := q["state"]
+	var since time.Time
+	if v := q.Get("logical_date_gte"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, badRequest("logical_date_gte: %v", err)
+		}
+		since = t
+	}
+	out := make([]models.DAGRun, 0)
+	for _, d := range dags {
+		for _, run := range d.runs {
+			m := run.at(now)
+			if len(states) > 0 && !contains(states, m.State) {
+				continue
+			}
+			if !since.IsZero() && (m.LogicalDate.IsZero() || m.LogicalDate.Before(since)) {
+				continue
+			}
+			out = append(out, m)
+		}
+	}
+	if err := sortRuns(out, q.Get("order_by")); err != nil {
+		return nil, err
+	}
+	total := len(out)
+	return models.DAGRunCollection{DAGRuns: page(out, q), TotalEntries: total}, nil
+}
+
+func sortRuns(runs []models.DAGRun, orderBy string) error {
+	field := strings.TrimPrefix(orderBy, "-")
+	var key func(models.DAGRun) time.Time
+	switch field {
+	case "", "id":
+		return nil // creation order
+	case "run_after":
+		key = func(r models.DAGRun) time.Time { return r.RunAfter }
+	case "logical_date":
+		key = func(r models.DAGRun) time.Time { return r.LogicalDate }
+	case "start_date":
+		key = func(r models.DAGRun) time.Time { return r.StartDate }
+	case "end_date":
+		key = func(r models.DAGRun) time.Time { return r.EndDate }
+	default:
+		return badRequest("order_by %q is not supported", orderBy)
+	}
+	desc := strings.HasPrefix(orderBy, "-")
+	sort.SliceStable(runs, func(i, j int) bool {
+		if desc {
+			return key(runs[j]).Before(key(runs[i]))
+		}
+		return key(runs[i]).Before(key(runs[j]))
+	})
+	return nil
+}
+
+func (s *Server) triggerRun(w http.ResponseWriter, r *http.Request, now time.Time) (any, error) {
+	d, err := s.findDAG(r)
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		RunId       string  `json:"dag_run_id"`
+		LogicalDate *string `json:"logical_date"`
+		Conf        any     `json:"conf"`
+		Note        string  `json:"note"`
+	}
+	if err := decode(r, &body); err != nil {
+		return nil, err
+	}
+	var logical time.Time
+	if body.LogicalDate != nil && *body.LogicalDate != "" {
+		if logical, err = time.Parse(time.RFC3339, *body.LogicalDate); err != nil {
+			return nil, badRequest("logical_date: %v", err)
+		}
+	}
+	run, err := s.sim.trigger(d, body.RunId, logical, body.Conf, body.Note, now)
+	if err != nil {
+		return nil, &httpError{http.StatusConflict, err.Error()}
+	}
+	s.sim.advance(now)
+	return run.at(now), nil
+}
+
+func (s *Server) patchRun(_ http.ResponseWriter, r *http.Request, now time.Time) (any, error) {
+	run, err := s.findRun(r)
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		Note *string `json:"note"`
+	}
+	if err := decode(r, &body); err != nil {
+		return nil, err
+	}
+	if body.Note != nil {
+		run.note = *body.Note
+	}
+	return run.at(now), nil
+}
+
+// ---------- task instances ----------
+
+func (s *Server) listTaskInstances(_ http.ResponseWriter, r *http.Request, now time.Time) (any, error) {
+	run, err := s.findRun(r)
+	if err != nil {
+		return nil, err
+	}
+	out := run.taskInstances(now)
+	total := len(out)
+	return models.TaskInstanceCollection{TaskInstances: page(out, r.URL.Query()), TotalEntries: total}, nil
+}
+
+func (s *Server) patchTaskInstance(_ http.ResponseWriter, r *http.Request, now time.Time) (any, error) {
+	run, p, err := s.findTask(r)
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		Note *string `json:"note"`
+	}
+	if err := decode(r, &body); err != nil {
+		return nil, err
+	}
+	if body.Note != nil {
+		p.note = *body.Note
+	}
+	return p.at(run, now), nil
+}
+
+func (s *Server) taskLogs(_ http.ResponseWriter, r *http.Request, now time.Time) (any, error) {
+	run, p, err := s.findTask(r)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(r.PathValue("try"))
+	if err != nil {
+		return nil, badRequest("try number: %v", err)
+	}
+	lines, ok := p.logLines(run, n, now)
+	if !ok {
+		return nil, notFound("try %d of %s has not started", n, p.spec.ID)
+	}
+	return map[string]any{"content": lines, "continuation_token": nil}, nil
+}
+
+// ---------- cluster ----------
+
+func (s *Server) health(_ http.ResponseWriter, _ *http.Request, now time.Time) (any, error) {
+	status := func(component string) *models.HealthStatus {
+		st := s.sim.health[component]
+		if st == "" {
+			st = "healthy"
+		}
+		return &models.HealthStatus{Status: st}
+	}
+	out := models.HealthInfo{
+		Metadatabase: status("metadatabase"),
+		Scheduler:    status("scheduler"),
+		Triggerer:    status("triggerer"),
+		DagProcessor: status("dag_processor"),
+	}
+	beat := now.UTC().Format(time.RFC3339Nano)
+	if out.Scheduler.Status == "healthy" {
+		out.Scheduler.LatestSchedulerHeartbeat = beat
+	}
+	if out.Triggerer.Status == "healthy" {
+		out.Triggerer.LatestTriggererHeartbeat = beat
+	}
+	if out.DagProcessor.Status == "healthy" {
+		out.DagProcessor.LatestDagProcessorHeartbeat = beat
+	}
+	return out, nil
+}
+
+func (s *Server) listPools(_ http.ResponseWriter, r *http.Request, now time.Time) (any, error) {
+	out := s.sim.pools(now)
+	total := len(out)
+	return models.PoolCollection{Pools: page(out, r.URL.Query()), TotalEntries: total}, nil
+}
+
+func (s *Server) config(http.ResponseWriter, *http.Request, time.Time) (any, error) {
+	return models.AirflowConfigResponse{Sections: []models.AirflowConfigSection{
+		{Section: "core", Options: []models.AirflowConfigOpt{
+			{Key: "executor", Value: "CeleryExecutor"},
+			{Key: "parallelism", Value: "32"},
+			{Key: "dags_folder", Value: "/opt/airflow/dags"},
+		}},
+		{Section: "scheduler", Options: []models.AirflowConfigOpt{
+			{Key: "catchup_by_default", Value: "False"},
+		}},
+	}}, nil
+}
+
+func (s *Server) listConnections(_ http.ResponseWriter, r *http.Request, _ time.Time) (any, error) {
+	out := make([]models.Connection, 0, len(s.sim.sc.Connections))
+	for _, c := range s.sim.sc.Connections {
+		out = append(out, models.Connection{ConnId: c.ID, ConnType: c.Type, Host: c.Host, Port: c.Port})
+	}
+	total := len(out)
+	return models.ConnectionCollection{Connections: page(out, r.URL.Query()), TotalEntries: total}, nil
+}
+
+func (s *Server) listVariables(_ http.ResponseWriter, r *http.Request, _ time.Time) (any, error) {
+	out := make([]models.Variable, 0, len(s.sim.sc.Variables))
+	for k, v := range s.sim.sc.Variables {
+		out = append(out, models.Variable{Key: k, Value: v})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	total := len(out)
+	return models.VariableCollection{Variables: page(out, r.URL.Query()), TotalEntries: total}, nil
+}
+
+// ---------- backfills ----------
+
+func (s *Server) listBackfills(_ http.ResponseWriter, r *http.Request, _ time.Time) (any, error) {
+	dagId := r.URL.Query().Get("dag_id")
+	out := make([]models.Backfill, 0)
+	for _, bf := range s.sim.backfills {
+		if dagId == "" || bf.DagId == dagId {
+			out = append(out, bf.Backfill)
+		}
+	}
+	total := len(out)
+	return models.BackfillCollection{Backfills: page(out, r.URL.Query()), TotalEntries: total}, nil
+}
+
+type backfillRequest struct {
+	DagId         string `json:"dag_id"`
+	FromDate      string `json:"from_date"`
+	ToDate        string `json:"to_date"`
+	MaxActiveRuns int    `json:"max_active_runs"`
+	DagRunConf    any    `json:"dag_run_conf"`
+}
+
+func (s *Server) parseBackfill(r *http.Request) (*dag, backfillRequest, time.Time, time.Time, error) {
+	var body backfillRequest
+	if err := decode(r, &body); err != nil {
+		return nil, body, time.Time{}, time.Time{}, err
+	}
+	d := s.sim.dag(body.DagId)
+	if d == nil {
+		return nil, body, time.Time{}, time.Time{}, notFound("DAG with dag_id: '%s' not found", body.DagId)
+	}
+	from, err := parseDate(body.FromDate)
+	if err != nil {
+		return nil, body, time.Time{}, time.Time{}, badRequest("from_date: %v", err)
+	}
+	to, err := parseDate(body.ToDate)
+	if err != nil {
+		return nil, body, time.Time{}, time.Time{}, badRequest("to_date: %v", err)
+	}
+	return d, body, from, to, nil
+}
+
+func (s *Server) createBackfill(_ http.ResponseWriter, r *http.Request, now time.Time) (any, error) {
+	d, body, from, to, err := s.parseBackfill(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, bf := range s.sim.backfills {
+		if bf.DagId == d.spec.ID && bf.CompletedAt.IsZero() {
+			return nil, &httpError{http.StatusConflict, fmt.Sprintf("There is already a running backfill for dag %s", d.spec.ID)}
+		}
+	}
+	bf, err := s.sim.createBackfill(d, from, to, body.MaxActiveRuns, body.DagRunConf, now)
+	if err != nil {
+		return nil, badRequest("%v", err)
+	}
+	s.sim.advance(now)
+	return bf.Backfill, nil
+}
+
+func (s *Server) dryRunBackfill(_ http.ResponseWriter, r *http.Request, _ time.Time) (any, error) {
+	d, _, from, to, err := s.parseBackfill(r)
+	if err != nil {
+		return nil, err
+	}
+	dates, err := backfillDates(d, from, to)
+	if err != nil {
+		return nil, badRequest("%v", err)
+	}
+	out := models.DryRunResponse{LogicalDates: make([]string, 0, len(dates))}
+	for _, l := range dates {
+		out.LogicalDates = append(out.LogicalDates, l.UTC().Format(time.RFC3339))
+	}
+	return out, nil
+}
+
+func (s *Server) patchBackfill(_ http.ResponseWriter, r *http.Request, now time.Time) (any, error) {
+	bf, err := s.findBackfill(r)
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		IsPaused *bool `json:"is_paused"`
+	}
+	if err := decode(r, &body); err != nil {
+		return nil, err
+	}
+	if !bf.CompletedAt.IsZero() {
+		return nil, &httpError{http.StatusConflict, fmt.Sprintf("Backfill %d is already completed", bf.ID)}
+	}
+	if body.IsPaused != nil {
+		if bf.IsPaused && !*body.IsPaused {
+			bf.resumed = now
+		}
+		bf.IsPaused, bf.UpdatedAt = *body.IsPaused, now
+		s.sim.advance(now)
+	}
+	return bf.Backfill, nil
+}
+
+func (s *Server) cancelBackfill(w http.ResponseWriter, r *http.Request, now time.Time) (any, error) {
+	bf, err := s.findBackfill(r)
+	if err != nil {
+		return nil, err
+	}
+	if !bf.CompletedAt.IsZero() {
+		return nil, &httpError{http.StatusConflict, fmt.Sprintf("Backfill %d is already completed", bf.ID)}
+	}
+	s.sim.cancelBackfill(bf, now)
+	return bf.Backfill, nil
+}
+
+// ---------- lookups and helpers ----------
+
+func (s *Server) findDAG(r *http.Request) (*dag, error) {
+	id := r.PathValue("dag_id")
+	if d := s.sim.dag(id); d != nil {
+		return d, nil
+	}
+	return nil, notFound("DAG with dag_id: '%s' not found", id)
+}
+
+func (s *Server) findRun(r *http.Request) (*run, error) {
+	d, err := s.findDAG(r)
+	if err != nil {
+		return nil, err
+	}
+	id := r.PathValue("run_id")
+	for _, run := range d.runs {
+		if run.runId == id {
+			return run, nil
+		}
+	}
+	return nil, notFound("DagRun with dag_id: '%s' and run_id: '%s' was not found", d.spec.ID, id)
+}
+
+func (s *Server) findTask(r *http.Request) (*run, *taskPlan, error) {
+	run, err := s.findRun(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	id := r.PathValue("task_id")
+	mapIndex := models.NotMapped
+	if v := r.URL.Query().Get("map_index"); v != "" {
+		if mapIndex, err = strconv.Atoi(v); err != nil {
+			return nil, nil, badRequest("map_index: %v", err)
+		}
+	}
+	for _, p := range run.tasks {
+		if p.spec.ID == id && p.mapIndex == mapIndex {
+			return run, p, nil
+		}
+	}
+	return nil, nil, notFound("Task instance %s of %s (map index %d) was not found", id, run.runId, mapIndex)
+}
+
+func (s *Server) findBackfill(r *http.Request) (*backfill, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return nil, badRequest("backfill id: %v", err)
+	}
+	for _, bf := range s.sim.backfills {
+		if bf.ID == id {
+			return bf, nil
+		}
+	}
+	return nil, notFound("Backfill %d not found", id)
+}
+
+// plainText is returned by handlers that answer text/plain.
+type plainText string
+
+type httpError struct {
+	status int
+	msg    string
+}
+
+func (e *httpError) Error() string { return e.msg }
+
+func badRequest(format string, args ...any) error {
+	return &httpError{http.StatusBadRequest, fmt.Sprintf(format, args...)}
+}
+
+func notFound(format string, args ...any) error {
+	return &httpError{http.StatusNotFound, fmt.Sprintf(format, args...)}
+}
+
+func decode(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return badRequest("invalid request body: %v", err)
+	}
+	return nil
+}
+
+// writeError answers in FastAPI's {"detail": ...} shape.
+func writeError(w http.ResponseWriter, status int, detail string) {
+	writeJSON(w, status, map[string]string{"detail": detail})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// page applies the limit and offset query parameters (Airflow's default
+// limit is 50).
+func page[T any](items []T, q map[string][]string) []T {
+	get := func(k string) string {
+		if v := q[k]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	limit, offset := 50, 0
+	if n, err := strconv.Atoi(get("limit")); err == nil && n > 0 {
+		limit = n
+	}
+	if n, err := strconv.Atoi(get("offset")); err == nil && n > 0 {
+		offset = n
+	}
+	if offset >= len(items) {
+		return items[:0]
+	}
+	items = items[offset:]
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+func parseDate(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, v)
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,403 @@
+package fakeairflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yjinheon/lazyflow/internal/api"
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+const testScenario = `
+queue_delay: 2s
+retry_delay: 5s
+dags:
+  - id: etl
+    every: 1m
+    history: 3
+    tasks:
+      - {id: extract, duration: 10s}
+      - {id: load, after: [extract], duration: 10s}
+  - id: flaky
+    tasks:
+      - {id: step, duration: 5s, fail_rate: 1, retries: 1}
+events:
+  - after: 5m
+    outage: 30s
+`
+
+// clock is a settable simulated clock.
+type clock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *clock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *clock) set(t time.Time) {
+	c.mu.Lock()
+	c.t = t
+	c.mu.Unlock()
+}
+
+var start = time.Date(2026, 1, 1, 0, 0, 30, 0, time.UTC)
+
+func newTestServer(t *testing.T) (*api.Client, *clock) {
+	t.Helper()
+	sc, err := ParseScenario([]byte(testScenario))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk := &clock{t: start}
+	srv := httptest.NewServer(New(sc, clk.now))
+	t.Cleanup(srv.Close)
+	return api.NewClient(api.ClientConfig{BaseURL: srv.URL, Username: "demo", Password: "demo"}), clk
+}
+
+func TestServer_scheduledRunsProgress(t *testing.T) {
+	c, clk := newTestServer(t)
+	ctx := context.Background()
+
+	newest := func() (string, string) {
+		t.Helper()
+		runs, err := c.GetDAGRuns(ctx, "etl", &api.ListOptions{OrderBy: "-run_after", Limit: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(runs.DAGRuns) != 1 {
+			t.Fatalf("got %d runs", len(runs.DAGRuns))
+		}
+		return runs.DAGRuns[0].RunId, runs.DAGRuns[0].State
+	}
+
+	runs, err := c.GetDAGRuns(ctx, "etl", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs.TotalEntries != 3 {
+		t.Fatalf("history: %d runs, want 3", runs.TotalEntries)
+	}
+
+	clk.set(start.Add(31 * time.Second)) // 00:01:01, the 00:01 run is queued
+	runId, state := newest()
+	if runId != "scheduled__2026-01-01T00:00:00Z" || state != "queued" {
+		t.Fatalf("newest = %s %s", runId, state)
+	}
+
+	clk.set(start.Add(35 * time.Second))
+	if _, state := newest(); state != "running" {
+		t.Fatalf("state after queue delay = %s", state)
+	}
+	tis, err := c.GetTaskInstances(ctx, "etl", runId, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tis.TaskInstances) != 2 || tis.TaskInstances[0].State != "running" || tis.TaskInstances[1].State != "" {
+		t.Fatalf("task instances = %+v", tis.TaskInstances)
+	}
+
+	clk.set(start.Add(60 * time.Second)) // 00:01:30, the 00:01 run has finished
+	runs, err = c.GetDAGRuns(ctx, "etl", &api.ListOptions{State: "success"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs.TotalEntries != 4 {
+		t.Fatalf("%d successful runs, want 4", runs.TotalEntries)
+	}
+	logs, err := c.GetTaskLogs(ctx, "etl", runId, "load", models.NotMapped, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logs, "Task exited with return code 0") {
+		t.Fatalf("logs:\n%s", logs)
+	}
+}
+
+func TestServer_triggerRetriesAndFails(t *testing.T) {
+	c, clk := newTestServer(t)
+	ctx := context.Background()
+
+	run, err := c.TriggerDAGRun(ctx, "flaky", map[string]any{"dag_run_id": "try_it", "logical_date": nil, "note": "by hand"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.State != "queued" || run.Note != "by hand" {
+		t.Fatalf("triggered run = %+v", run)
+	}
+	_, err = c.TriggerDAGRun(ctx, "flaky", map[string]any{"dag_run_id": "try_it", "logical_date": nil})
+	if !errors.Is(err, api.ErrConflict) {
+		t.Fatalf("duplicate run id: err = %v, want conflict", err)
+	}
+
+	clk.set(start.Add(time.Minute))
+	runs, err := c.GetDAGRuns(ctx, "flaky", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs.DAGRuns[0].State != "failed" {
+		t.Fatalf("state = %s, want failed", runs.DAGRuns[0].State)
+	}
+	tis, err := c.GetTaskInstances(ctx, "flaky", "try_it", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ti := tis.TaskInstances[0]; ti.State != "failed" || ti.TryNumber != 2 {
+		t.Fatalf("task = %s try %d", ti.State, ti.TryNumber)
+	}
+	logs, err := c.GetTaskLogs(ctx, "flaky", "try_it", "step", models.NotMapped, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logs, "simulated failure") {
+		t.Fatalf("logs:\n%s", logs)
+	}
+	if _, err := c.GetTaskLogs(ctx, "flaky", "try_it", "step", models.NotMapped, 3); !errors.Is(err, api.ErrNotFound) {
+		t.Fatalf("try 3: err = %v, want not found", err)
+	}
+}
+
+func TestServer_pauseSkipsMissedIntervals(t *testing.T) {
+	c, clk := newTestServer(t)
+	ctx := context.Background()
+
+	if err := c.PauseDAG(ctx, "etl"); err != nil {
+		t.Fatal(err)
+	}
+	clk.set(start.Add(4 * time.Minute))
+	runs, err := c.GetDAGRuns(ctx, "etl", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs.TotalEntries != 3 {
+		t.Fatalf("paused DAG got runs: %d", runs.TotalEntries)
+	}
+	if err := c.UnpauseDAG(ctx, "etl"); err != nil {
+		t.Fatal(err)
+	}
+	runs, err = c.GetDAGRuns(ctx, "etl", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs.TotalEntries != 4 {
+		t.Fatalf("after unpause: %d runs, want 4 (no catchup)", runs.TotalEntries)
+	}
+	dags, err := c.GetDAGs(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dags.DAGs[0].IsPaused {
+		t.Fatal("etl still paused")
+	}
+}
+
+func TestServer_backfill(t *testing.T) {
+	c, clk := newTestServer(t)
+	ctx := context.Background()
+	body := map[string]any{
+		"dag_id":          "etl",
+		"from_date":       "2025-12-31T23:00:30Z",
+		"to_date":         "2025-12-31T23:10:30Z",
+		"max_active_runs": 2,
+	}
+
+	dry, err := c.DryRunBackfill(ctx, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dry.LogicalDates) != 10 || dry.LogicalDates[0] != "2025-12-31T23:01:00Z" {
+		t.Fatalf("dry run = %v", dry.LogicalDates)
+	}
+
+	bf, err := c.CreateBackfill(ctx, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateBackfill(ctx, body); !errors.Is(err, api.ErrConflict) {
+		t.Fatalf("second backfill: err = %v, want conflict", err)
+	}
+	if err := c.PauseBackfill(ctx, bf.ID); err != nil {
+		t.Fatal(err)
+	}
+	clk.set(start.Add(10 * time.Minute))
+	list, err := c.ListBackfills(ctx, "etl", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Backfills) != 1 || !list.Backfills[0].IsPaused || !list.Backfills[0].CompletedAt.IsZero() {
+		t.Fatalf("paused backfill = %+v", list.Backfills)
+	}
+
+	if err := c.UnpauseBackfill(ctx, bf.ID); err != nil {
+		t.Fatal(err)
+	}
+	clk.set(start.Add(20 * time.Minute))
+	list, err = c.ListBackfills(ctx, "etl", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Backfills[0].CompletedAt.IsZero() {
+		t.Fatal("backfill never completed")
+	}
+	runs, err := c.GetDAGRuns(ctx, "etl", &api.ListOptions{LogicalDateGte: time.Date(2025, 12, 31, 23, 0, 0, 0, time.UTC), Limit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	backfilled := 0
+	for _, r := range runs.DAGRuns {
+		if r.RunType == "backfill" {
+			backfilled++
+			if r.State != "success" {
+				t.Errorf("%s: %s", r.RunId, r.State)
+			}
+		}
+	}
+	if backfilled != 10 {
+		t.Fatalf("%d backfill runs, want 10", backfilled)
+	}
+}
+
+func TestServer_outageAndHealth(t *testing.T) {
+	c, clk := newTestServer(t)
+	ctx := context.Background()
+
+	h, err := c.GetHealth(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Scheduler.Status != "healthy" {
+		t.Fatalf("scheduler = %s", h.Scheduler.Status)
+	}
+
+	clk.set(start.Add(5*time.Minute + time.Second))
+	var apiErr *api.APIError
+	if _, err := c.GetHealth(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != 503 {
+		t.Fatalf("during outage: err = %v, want 503", err)
+	}
+	clk.set(start.Add(6 * time.Minute))
+	if _, err := c.GetHealth(ctx); err != nil {
+		t.Fatalf("after outage: %v", err)
+	}
+}
+
+func TestServer_structureGroupsDottedIds(t *testing.T) {
+	sc, err := ParseScenario([]byte(`
+dags:
+  - id: grouped
+    tasks:
+      - {id: start}
+      - {id: load.warehouse, after: [start]}
+      - {id: load.marts.sales, after: [load.warehouse]}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(New(sc, func() time.Time { return start }))
+	defer srv.Close()
+	c := api.NewClient(api.ClientConfig{BaseURL: srv.URL, Username: "demo", Password: "demo"})
+
+	s, err := c.GetDAGStructure(context.Background(), "grouped")
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups := s.TaskGroups()
+	if len(groups) != 3 || groups["start"] != nil {
+		t.Fatalf("groups = %v", groups)
+	}
+	if got := strings.Join(groups["load.marts.sales"], ","); got != "load,load.marts" {
+		t.Fatalf("load.marts.sales is in %s", got)
+	}
+}
+
+func TestServer_mappedTaskExpands(t *testing.T) {
+	sc, err := ParseScenario([]byte(`
+dags:
+  - id: mapped
+    tasks:
+      - {id: fetch, duration: 5s}
+      - {id: render, after: [fetch], duration: 5s, expand: [emea, amer]}
+      - {id: send, after: [render], duration: 5s}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk := &clock{t: start}
+	srv := httptest.NewServer(New(sc, clk.now))
+	defer srv.Close()
+	c := api.NewClient(api.ClientConfig{BaseURL: srv.URL, Username: "demo", Password: "demo"})
+	ctx := context.Background()
+
+	if _, err := c.TriggerDAGRun(ctx, "mapped", map[string]any{"dag_run_id": "m1", "logical_date": nil}); err != nil {
+		t.Fatal(err)
+	}
+	clk.set(start.Add(time.Minute))
+	tis, err := c.GetTaskInstances(ctx, "mapped", "m1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, ti := range tis.TaskInstances {
+		got = append(got, fmt.Sprintf("%s/%d/%s/%s", ti.TaskId, ti.MapIndex, ti.RenderedMapIndex, ti.State))
+	}
+	if strings.Join(got, " ") != "fetch/-1//success render/0/emea/success render/1/amer/success send/-1//success" {
+		t.Fatalf("task instances = %v", got)
+	}
+	tasks, err := c.GetTasks(ctx, "mapped")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tasks.Tasks[0].IsMapped || !tasks.Tasks[1].IsMapped {
+		t.Fatalf("is_mapped = %v, %v", tasks.Tasks[0].IsMapped, tasks.Tasks[1].IsMapped)
+	}
+
+	logs, err := c.GetTaskLogs(ctx, "mapped", "m1", "render", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logs, "render[1]") {
+		t.Fatalf("logs of map index 1:\n%s", logs)
+	}
+	if _, err := c.GetTaskLogs(ctx, "mapped", "m1", "render", models.NotMapped, 1); !errors.Is(err, api.ErrNotFound) {
+		t.Fatalf("a mapped task has no unmapped instance: err = %v", err)
+	}
+
+	if _, err := c.SetTaskInstanceNote(ctx, "mapped", "m1", "render", 1, "amer rerun"); err != nil {
+		t.Fatal(err)
+	}
+	tis, err = c.GetTaskInstances(ctx, "mapped", "m1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var notes []string
+	for _, ti := range tis.TaskInstances {
+		notes = append(notes, fmt.Sprintf("%s/%d=%q", ti.TaskId, ti.MapIndex, ti.Note))
+	}
+	if strings.Join(notes, " ") != `fetch/-1="" render/0="" render/1="amer rerun" send/-1=""` {
+		t.Fatalf("a note on one expansion lands on it alone: %v", notes)
+	}
+}
+
+func TestParseScenario_rejectsForwardDependency(t *testing.T) {
+	_, err := ParseScenario([]byte(`
+dags:
+  - id: d
+    tasks:
+      - {id: b, after: [a]}
+      - {id: a}
+`))
+	if err == nil || !strings.Contains(err.Error(), "not declared before it") {
+		t.Fatalf("err = %v", err)
+	}
+	if sc := DefaultScenario(); len(sc.DAGs) == 0 {
+		t.Fatal("built-in scenario has no DAGs")
+	}
+}
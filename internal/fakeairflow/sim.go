@@ -0,0 +1,594 @@
+package fakeairflow
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+// The simulation keeps runs and backfills and moves them forward on each
+// request (advance). A run's whole task plan is fixed when it starts, so the
+// state at any instant is derived from the clock and polling rate does not
+// change what happens.
+
+type dag struct {
+	spec    DAGSpec
+	paused  bool
+	resumed time.Time // last unpause; queued manual runs wait for it
+	next    time.Time // run_after of the next scheduled run
+	runs    []*run    // creation order
+}
+
+type run struct {
+	dagId    string
+	runId    string
+	runType  string
+	logical  time.Time
+	queuedAt time.Time
+	start    time.Time // zero until the run leaves the queue
+	end      time.Time // known as soon as start is
+	state    string    // final state, valid once start (or cancelled) is set
+	conf     any
+	note     string
+	backfill int
+	tasks    []*taskPlan
+}
+
+// taskPlan is one task's tries, decided when the run starts. ready is zero
+// for a task that never runs because an upstream failed. A mapped task has
+// one plan per expansion.
+type taskPlan struct {
+	spec     TaskSpec
+	mapIndex int
+	ready    time.Time
+	tries    []try
+	note     string
+	final    string
+}
+
+type try struct {
+	queued, start, end time.Time
+	failed             bool
+}
+
+type backfill struct {
+	models.Backfill
+	resumed time.Time // last unpause, no run starts before it
+}
+
+type sim struct {
+	sc        Scenario
+	started   time.Time
+	dags      []*dag
+	backfills []*backfill
+	health    map[string]string
+	outage    time.Time // requests fail until then
+	events    int       // scripted events applied so far
+}
+
+func newSim(sc Scenario, now time.Time) *sim {
+	if sc.QueueDelay <= 0 {
+		sc.QueueDelay = 2 * time.Second
+	}
+	if sc.RetryDelay <= 0 {
+		sc.RetryDelay = 10 * time.Second
+	}
+	s := &sim{sc: sc, started: now, health: map[string]string{}}
+	for k, v := range sc.Health {
+		s.health[k] = v
+	}
+	for _, spec := range sc.DAGs {
+		d := &dag{spec: spec, paused: spec.Paused}
+		if spec.Every > 0 {
+			history := spec.History
+			if history <= 0 {
+				history = 10
+			}
+			d.next = now.Truncate(spec.Every).Add(-time.Duration(history-1) * spec.Every)
+			// Seed history even for a paused DAG, as if it ran before.
+			for !d.next.After(now) {
+				s.schedule(d)
+			}
+		}
+		s.dags = append(s.dags, d)
+	}
+	s.sc.Events = append([]Event(nil), sc.Events...)
+	sort.SliceStable(s.sc.Events, func(i, j int) bool { return s.sc.Events[i].After < s.sc.Events[j].After })
+	s.advance(now)
+	return s
+}
+
+func (s *sim) dag(id string) *dag {
+	for _, d := range s.dags {
+		if d.spec.ID == id {
+			return d
+		}
+	}
+	return nil
+}
+
+// advance applies due events, creates scheduled runs and starts queued ones.
+func (s *sim) advance(now time.Time) {
+	for s.events < len(s.sc.Events) {
+		e := s.sc.Events[s.events]
+		at := s.started.Add(e.After)
+		if at.After(now) {
+			break
+		}
+		s.apply(e, at)
+		s.events++
+	}
+	for _, d := range s.dags {
+		if d.spec.Every > 0 && !d.paused {
+			for !d.next.After(now) {
+				s.schedule(d)
+			}
+		}
+		for _, r := range d.runs {
+			if r.start.IsZero() && r.state == "" && r.backfill == 0 && !d.paused {
+				s.startRun(d, r, maxTime(r.queuedAt, d.resumed).Add(s.sc.QueueDelay))
+			}
+		}
+	}
+	for _, bf := range s.backfills {
+		s.advanceBackfill(bf, now)
+	}
+}
+
+func (s *sim) apply(e Event, at time.Time) {
+	for k, v := range e.Health {
+		s.health[k] = v
+	}
+	if e.Outage > 0 {
+		s.outage = at.Add(e.Outage)
+	}
+	if d := s.dag(e.Pause); d != nil {
+		d.paused = true
+	}
+	if d := s.dag(e.Unpause); d != nil {
+		s.setPaused(d, false, at)
+	}
+	if d := s.dag(e.Trigger); d != nil {
+		_, _ = s.trigger(d, "", time.Time{}, nil, "", at)
+	}
+}
+
+// schedule creates the run whose data interval ends at d.next.
+func (s *sim) schedule(d *dag) {
+	logical := d.next.Add(-d.spec.Every)
+	r := &run{
+		dagId:    d.spec.ID,
+		runId:    "scheduled__" + logical.UTC().Format(time.RFC3339),
+		runType:  "scheduled",
+		logical:  logical,
+		queuedAt: d.next,
+	}
+	d.runs = append(d.runs, r)
+	s.startRun(d, r, d.next.Add(s.sc.QueueDelay))
+	d.next = d.next.Add(d.spec.Every)
+}
+
+// startRun fixes the run's plan: each task becomes ready when its upstream
+// tasks (every expansion of a mapped one) have succeeded, then runs its tries.
+func (s *sim) startRun(d *dag, r *run, at time.Time) {
+	if at.Before(r.queuedAt) {
+		at = r.queuedAt
+	}
+	r.start, r.end, r.state = at, at, "success"
+	byId := make(map[string][]*taskPlan, len(d.spec.Tasks))
+	r.tasks = r.tasks[:0]
+	for _, spec := range d.spec.Tasks {
+		ready := at
+	upstream:
+		for _, up := range spec.After {
+			for _, u := range byId[up] {
+				if u.final != "success" {
+					ready = time.Time{}
+					break upstream
+				}
+				ready = maxTime(ready, u.tries[len(u.tries)-1].end)
+			}
+		}
+		indexes := []int{models.NotMapped}
+		if len(spec.Expand) > 0 {
+			indexes = indexes[:0]
+			for i := range spec.Expand {
+				indexes = append(indexes, i)
+			}
+		}
+		for _, i := range indexes {
+			p := &taskPlan{spec: spec, mapIndex: i, ready: ready}
+			if p.ready.IsZero() {
+				p.final = "upstream_failed"
+			} else {
+				s.planTries(r, p)
+				r.end = maxTime(r.end, p.tries[len(p.tries)-1].end)
+			}
+			if p.final != "success" {
+				r.state = "failed"
+			}
+			byId[spec.ID] = append(byId[spec.ID], p)
+			r.tasks = append(r.tasks, p)
+		}
+	}
+}
+
+func (s *sim) planTries(r *run, p *taskPlan) {
+	base := p.spec.Duration
+	if base <= 0 {
+		base = 10 * time.Second
+	}
+	at := p.ready
+	for n := 1; n <= p.spec.Retries+1; n++ {
+		key := fmt.Sprintf("%s/%s/%s/%d", r.dagId, r.runId, p.key(), n)
+		t := try{queued: at, start: at.Add(time.Second)}
+		t.end = t.start.Add(time.Duration(float64(base) * (0.8 + 0.4*unit(key+"/duration"))))
+		t.failed = unit(key) < p.spec.FailRate
+		p.tries = append(p.tries, t)
+		if !t.failed {
+			p.final = "success"
+			return
+		}
+		at = t.end.Add(s.sc.RetryDelay)
+	}
+	p.final = "failed"
+}
+
+// trigger queues a manual run; it starts on the next advance unless the DAG
+// is paused.
+func (s *sim) trigger(d *dag, runId string, logical time.Time, conf any, note string, now time.Time) (*run, error) {
+	if runId == "" {
+		runId = "manual__" + now.UTC().Format(time.RFC3339Nano)
+	}
+	for _, r := range d.runs {
+		if r.runId == runId {
+			return nil, fmt.Errorf("dag run %s already exists", runId)
+		}
+	}
+	r := &run{dagId: d.spec.ID, runId: runId, runType: "manual", logical: logical, queuedAt: now, conf: conf, note: note}
+	d.runs = append(d.runs, r)
+	return r, nil
+}
+
+func (s *sim) setPaused(d *dag, paused bool, now time.Time) {
+	if d.paused && !paused {
+		d.resumed = now
+		if d.spec.Every > 0 && d.next.Before(now) {
+			// No catchup: one run for the interval that just ended, then on schedule.
+			d.next = now
+		}
+	}
+	d.paused = paused
+}
+
+// createBackfill queues one run per schedule interval in [from, to] that has
+// no run yet.
+func (s *sim) createBackfill(d *dag, from, to time.Time, maxActive int, conf any, now time.Time) (*backfill, error) {
+	dates, err := backfillDates(d, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if maxActive <= 0 {
+		maxActive = 10
+	}
+	bf := &backfill{Backfill: models.Backfill{
+		ID:                len(s.backfills) + 1,
+		DagId:             d.spec.ID,
+		FromDate:          from,
+		ToDate:            to,
+		DagRunConf:        conf,
+		ReprocessBehavior: "none",
+		MaxActiveRuns:     maxActive,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}, resumed: now}
+	existing := make(map[int64]bool, len(d.runs))
+	for _, r := range d.runs {
+		existing[r.logical.UnixNano()] = true
+	}
+	for _, l := range dates {
+		if existing[l.UnixNano()] {
+			continue
+		}
+		d.runs = append(d.runs, &run{
+			dagId:    d.spec.ID,
+			runId:    "backfill__" + l.UTC().Format(time.RFC3339),
+			runType:  "backfill",
+			logical:  l,
+			queuedAt: now,
+			conf:     conf,
+			backfill: bf.ID,
+		})
+	}
+	s.backfills = append(s.backfills, bf)
+	return bf, nil
+}
+
+func backfillDates(d *dag, from, to time.Time) ([]time.Time, error) {
+	if d.spec.Every <= 0 {
+		return nil, fmt.Errorf("dag %s has no schedule to backfill", d.spec.ID)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("to_date is before from_date")
+	}
+	var out []time.Time
+	for l := from.Truncate(d.spec.Every); !l.After(to); l = l.Add(d.spec.Every) {
+		if !l.Before(from) {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+// advanceBackfill starts queued runs in logical date order, at most
+// MaxActiveRuns at a time.
+func (s *sim) advanceBackfill(bf *backfill, now time.Time) {
+	if bf.IsPaused || !bf.CompletedAt.IsZero() {
+		return
+	}
+	d := s.dag(bf.DagId)
+	runs := s.backfillRuns(bf)
+	var ends []time.Time // of started runs, in start order
+	done := true
+	for _, r := range runs {
+		if r.state != "" && r.start.IsZero() {
+			continue // cancelled
+		}
+		if r.start.IsZero() {
+			at := maxTime(r.queuedAt.Add(s.sc.QueueDelay), bf.resumed)
+			if n := len(ends); n >= bf.MaxActiveRuns {
+				at = maxTime(at, ends[n-bf.MaxActiveRuns])
+			}
+			if at.After(now) {
+				done = false
+				break
+			}
+			s.startRun(d, r, at)
+		}
+		ends = append(ends, r.end)
+		if r.end.After(now) {
+			done = false
+		}
+	}
+	if done {
+		end := bf.resumed
+		for _, e := range ends {
+			end = maxTime(end, e)
+		}
+		bf.CompletedAt, bf.UpdatedAt = end, end
+	}
+}
+
+func (s *sim) backfillRuns(bf *backfill) []*run {
+	var out []*run
+	if d := s.dag(bf.DagId); d != nil {
+		for _, r := range d.runs {
+			if r.backfill == bf.ID {
+				out = append(out, r)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].logical.Before(out[j].logical) })
+	return out
+}
+
+// cancelBackfill fails the runs that have not started and closes it.
+func (s *sim) cancelBackfill(bf *backfill, now time.Time) {
+	for _, r := range s.backfillRuns(bf) {
+		if r.start.IsZero() {
+			r.state, r.end = "failed", now
+		}
+	}
+	bf.CompletedAt, bf.UpdatedAt = now, now
+}
+
+// ---------- state at an instant ----------
+
+func (r *run) at(now time.Time) models.DAGRun {
+	out := models.DAGRun{
+		DagId:       r.dagId,
+		RunId:       r.runId,
+		State:       "queued",
+		LogicalDate: r.logical,
+		RunAfter:    r.queuedAt,
+		RunType:     r.runType,
+		Conf:        r.conf,
+		Note:        r.note,
+	}
+	if out.Conf == nil {
+		out.Conf = map[string]any{}
+	}
+	switch {
+	case r.start.IsZero() && r.state != "":
+		out.State, out.EndDate = r.state, r.end // cancelled before it started
+	case r.start.IsZero() || now.Before(r.start):
+	case now.Before(r.end):
+		out.State, out.StartDate = "running", r.start
+	default:
+		out.State, out.StartDate, out.EndDate = r.state, r.start, r.end
+	}
+	return out
+}
+
+func (r *run) taskInstances(now time.Time) []models.TaskInstance {
+	out := make([]models.TaskInstance, 0, len(r.tasks))
+	for _, p := range r.tasks {
+		if r.start.IsZero() || now.Before(r.start) {
+			break
+		}
+		out = append(out, p.at(r, now))
+	}
+	return out
+}
+
+// key names the plan in the hashes that vary its tries; an unmapped task
+// keeps its bare id so existing scenarios replay as before.
+func (p *taskPlan) key() string {
+	if p.mapIndex == models.NotMapped {
+		return p.spec.ID
+	}
+	return fmt.Sprintf("%s[%d]", p.spec.ID, p.mapIndex)
+}
+
+func (p *taskPlan) at(r *run, now time.Time) models.TaskInstance {
+	ti := models.TaskInstance{
+		TaskId:          p.spec.ID,
+		TaskDisplayName: p.spec.ID,
+		DagId:           r.dagId,
+		RunId:           r.runId,
+		Operator:        operator(p.spec),
+		Pool:            pool(p.spec),
+		Queue:           "default",
+		Note:            p.note,
+		MapIndex:        p.mapIndex,
+	}
+	if p.mapIndex != models.NotMapped {
+		ti.RenderedMapIndex = p.spec.Expand[p.mapIndex]
+	}
+	if p.ready.IsZero() || now.Before(p.ready) {
+		if p.final == "upstream_failed" && !now.Before(r.end) {
+			ti.State = "upstream_failed"
+		}
+		return ti
+	}
+	ti.State = "scheduled"
+	for i, t := range p.tries {
+		if now.Before(t.queued) {
+			ti.State = "up_for_retry"
+			break
+		}
+		ti.TryNumber = i + 1
+		ti.QueuedDttm = timePtr(t.queued)
+		ti.Hostname = fmt.Sprintf("worker-%d", 1+int(unit(r.runId+p.key())*3))
+		switch {
+		case now.Before(t.start):
+			ti.State = "queued"
+			return ti
+		case now.Before(t.end):
+			ti.State, ti.StartDate = "running", timePtr(t.start)
+			ti.Duration = now.Sub(t.start).Seconds()
+			return ti
+		}
+		ti.StartDate, ti.EndDate = timePtr(t.start), timePtr(t.end)
+		ti.Duration = t.end.Sub(t.start).Seconds()
+		ti.State = "failed"
+		if !t.failed {
+			ti.State = "success"
+		}
+	}
+	return ti
+}
+
+// logLines is the log of one try up to now; ok is false if the try has not
+// started.
+func (p *taskPlan) logLines(r *run, tryNumber int, now time.Time) (lines []logLine, ok bool) {
+	if tryNumber < 1 || tryNumber > len(p.tries) {
+		return nil, false
+	}
+	t := p.tries[tryNumber-1]
+	if now.Before(t.start) {
+		return nil, false
+	}
+	add := func(at time.Time, level, logger, event string) {
+		lines = append(lines, logLine{Timestamp: at.UTC().Format(time.RFC3339Nano), Level: level, Logger: logger, Event: event})
+	}
+	add(t.start, "info", "airflow.task", fmt.Sprintf("Starting attempt %d of %d", tryNumber, p.spec.Retries+1))
+	add(t.start, "info", "airflow.task", fmt.Sprintf("Executing <Task(%s): %s> on %s", operator(p.spec), p.key(), r.logical.UTC().Format(time.RFC3339)))
+	step := (t.end.Sub(t.start) / 5).Truncate(time.Millisecond)
+	for i, at := 1, t.start.Add(step); step > 0 && at.Before(t.end) && !at.After(now); i, at = i+1, at.Add(step) {
+		add(at, "info", "task.stdout", fmt.Sprintf("Processed batch %d (%d rows)", i, 1000+int(unit(fmt.Sprint(r.runId, p.key(), i))*9000)))
+	}
+	if now.Before(t.end) {
+		return lines, true
+	}
+	if t.failed {
+		add(t.end, "error", "airflow.task", "Task failed with exception")
+		add(t.end, "error", "task.stderr", "RuntimeError: simulated failure")
+		add(t.end, "info", "airflow.task", "Task exited with return code 1")
+		return lines, true
+	}
+	add(t.end, "info", "airflow.task", "Done. Returned value was: None")
+	add(t.end, "info", "airflow.task", "Task exited with return code 0")
+	return lines, true
+}
+
+type logLine struct {
+	Event     string `json:"event"`
+	Timestamp string `json:"timestamp"`
+	Level     string `json:"level"`
+	Logger    string `json:"logger"`
+}
+
+// pools reports occupancy from the task instances running or queued now.
+func (s *sim) pools(now time.Time) []models.Pool {
+	slots := map[string]int{"default_pool": 128}
+	names := []string{"default_pool"}
+	for _, p := range s.sc.Pools {
+		if _, ok := slots[p.Name]; !ok {
+			names = append(names, p.Name)
+		}
+		slots[p.Name] = p.Slots
+	}
+	running, queued := map[string]int{}, map[string]int{}
+	for _, d := range s.dags {
+		for _, r := range d.runs {
+			if r.start.IsZero() || now.Before(r.start) || !now.Before(r.end) {
+				continue
+			}
+			for _, ti := range r.taskInstances(now) {
+				switch ti.State {
+				case "running":
+					running[ti.Pool]++
+				case "queued":
+					queued[ti.Pool]++
+				}
+			}
+		}
+	}
+	out := make([]models.Pool, 0, len(names))
+	for _, name := range names {
+		p := models.Pool{
+			Name:          name,
+			Slots:         slots[name],
+			RunningSlots:  running[name],
+			QueuedSlots:   queued[name],
+			OccupiedSlots: running[name] + queued[name],
+		}
+		p.OpenSlots = max(p.Slots-p.OccupiedSlots, 0)
+		out = append(out, p)
+	}
+	return out
+}
+
+func operator(t TaskSpec) string {
+	if t.Operator == "" {
+		return "PythonOperator"
+	}
+	return t.Operator
+}
+
+func pool(t TaskSpec) string {
+	if t.Pool == "" {
+		return "default_pool"
+	}
+	return t.Pool
+}
+
+// unit hashes key to [0, 1).
+func unit(key string) float64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return float64(h.Sum64()>>11) / (1 << 53)
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+func timePtr(t time.Time) *time.Time { return &t }
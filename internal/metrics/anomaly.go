@@ -0,0 +1,199 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+const (
+	// MinBaselineSamples is how many successful instances a task needs before
+	// its durations are judged; with fewer, every run looks unusual.
+	MinBaselineSamples = 5
+
+	// AnomalyZ is the robust z-score (Iglewicz & Hoaglin) above which a
+	// duration counts as anomalous.
+	AnomalyZ = 3.5
+
+	// minAnomalyExcess ignores deviations too small to matter in absolute
+	// terms, such as a 2s task taking 4s.
+	minAnomalyExcess = 5 * time.Second
+)
+
+// TaskBaseline summarises one task's historical durations.
+type TaskBaseline struct {
+	Samples int
+	Median  time.Duration
+	P95     time.Duration
+	MAD     time.Duration // median absolute deviation from Median
+}
+
+// Anomaly is a task instance whose duration sits far above its baseline.
+type Anomaly struct {
+	TaskId   string
+	RunId    string
+	Duration time.Duration
+	Baseline TaskBaseline
+	Z        float64 // robust z-score; +Inf when the history has no spread
+}
+
+// TaskDuration is a task instance's run time: the API's duration once it has
+// finished, elapsed wall time while running, else 0.
+func TaskDuration(ti models.TaskInstance, now time.Time) time.Duration {
+	if ti.Duration > 0 {
+		return time.Duration(ti.Duration * float64(time.Second))
+	}
+	if ti.State == "running" && ti.StartDate != nil {
+		return now.Sub(*ti.StartDate)
+	}
+	return 0
+}
+
+// TaskBaselines builds a baseline per task id from successful instances.
+// Instances of excludeRunId are left out so a run is never judged against
+// itself. Tasks with fewer than MinBaselineSamples samples are omitted.
+func TaskBaselines(history []models.TaskInstance, excludeRunId string) map[string]TaskBaseline {
+	samples := map[string][]time.Duration{}
+	for _, ti := range history {
+		if ti.State != "success" || (excludeRunId != "" && ti.RunId == excludeRunId) {
+			continue
+		}
+		if d := TaskDuration(ti, time.Time{}); d > 0 {
+			samples[ti.TaskId] = append(samples[ti.TaskId], d)
+		}
+	}
+	out := make(map[string]TaskBaseline, len(samples))
+	for taskId, durs := range samples {
+		if len(durs) < MinBaselineSamples {
+			continue
+		}
+		out[taskId] = baselineOf(durs)
+	}
+	return out
+}
+
+func baselineOf(durs []time.Duration) TaskBaseline {
+	sorted := append([]time.Duration(nil), durs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	median := medianOf(sorted)
+	devs := make([]time.Duration, len(sorted))
+	for i, d := range sorted {
+		dev := d - median
+		if dev < 0 {
+			dev = -dev
+		}
+		devs[i] = dev
+	}
+	sort.Slice(devs, func(i, j int) bool { return devs[i] < devs[j] })
+	return TaskBaseline{
+		Samples: len(sorted),
+		Median:  median,
+		P95:     nearestRank(sorted, 95),
+		MAD:     medianOf(devs),
+	}
+}
+
+func medianOf(sorted []time.Duration) time.Duration {
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// CheckDuration scores d against b. It is anomalous when it is above the p95,
+// at least minAnomalyExcess over the median, and its robust z-score reaches
+// AnomalyZ (any excess counts when the history has no spread). Only slow
+// outliers are flagged; a fast run is not an incident.
+func CheckDuration(d time.Duration, b TaskBaseline) (z float64, anomalous bool) {
+	if b.Samples < MinBaselineSamples || d <= b.Median {
+		return 0, false
+	}
+	if b.MAD > 0 {
+		z = 0.6745 * float64(d-b.Median) / float64(b.MAD)
+	} else {
+		z = math.Inf(1)
+	}
+	return z, d > b.P95 && d-b.Median >= minAnomalyExcess && z >= AnomalyZ
+}
+
+// TopAnomalies scans a window of task instances for slow outliers. Each
+// instance is judged against the rest of its task's successful instances in
+// the window (leaving it out, so an outlier cannot raise its own p95). Each
+// task contributes only its worst instance; results are ordered by z-score,
+// then duration, and capped at limit (0 = no cap).
+func TopAnomalies(tasks []models.TaskInstance, now time.Time, limit int) []Anomaly {
+	byTask := map[string][]models.TaskInstance{}
+	for _, ti := range tasks {
+		byTask[ti.TaskId] = append(byTask[ti.TaskId], ti)
+	}
+	worst := map[string]Anomaly{}
+	for taskId, group := range byTask {
+		var all []time.Duration
+		for _, o := range group {
+			if od := TaskDuration(o, now); od > 0 && o.State == "success" {
+				all = append(all, od)
+			}
+		}
+		if len(all) < MinBaselineSamples {
+			continue
+		}
+		full := baselineOf(all)
+		for i, ti := range group {
+			d := TaskDuration(ti, now)
+			// Median and MAD barely move when one sample is dropped, so the
+			// whole-group z-score screens out the bulk before the exact check.
+			if z, _ := CheckDuration(d, full); z < AnomalyZ {
+				continue
+			}
+			var others []time.Duration
+			for j, o := range group {
+				if j == i || o.State != "success" {
+					continue
+				}
+				if od := TaskDuration(o, now); od > 0 {
+					others = append(others, od)
+				}
+			}
+			if len(others) < MinBaselineSamples {
+				continue
+			}
+			b := baselineOf(others)
+			z, bad := CheckDuration(d, b)
+			if !bad {
+				continue
+			}
+			a := Anomaly{TaskId: taskId, RunId: ti.RunId, Duration: d, Baseline: b, Z: z}
+			if cur, seen := worst[taskId]; !seen || anomalyLess(cur, a) {
+				worst[taskId] = a
+			}
+		}
+	}
+	out := make([]Anomaly, 0, len(worst))
+	for _, a := range worst {
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Z != out[j].Z || out[i].Duration != out[j].Duration {
+			return anomalyLess(out[j], out[i])
+		}
+		return out[i].TaskId < out[j].TaskId
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// anomalyLess orders by z-score, breaking ties (e.g. two +Inf) by duration.
+func anomalyLess(a, b Anomaly) bool {
+	if a.Z != b.Z {
+		return a.Z < b.Z
+	}
+	return a.Duration < b.Duration
+}
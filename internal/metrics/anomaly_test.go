@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+func taskRun(taskId, runId, state string, secs float64) models.TaskInstance {
+	return models.TaskInstance{TaskId: taskId, RunId: runId, State: state, Duration: secs}
+}
+
+func TestTaskBaselines(t *testing.T) {
+	history := []models.TaskInstance{
+		taskRun("load", "r1", "success", 10),
+		taskRun("load", "r2", "success", 12),
+		taskRun("load", "r3", "success", 11),
+		taskRun("load", "r4", "success", 13),
+		taskRun("load", "r5", "success", 10),
+		taskRun("load", "r6", "failed", 300),  // failures are not the baseline
+		taskRun("load", "cur", "success", 90), // the run being judged
+		taskRun("notify", "r1", "success", 1), // too few samples
+	}
+	got := TaskBaselines(history, "cur")
+	if _, ok := got["notify"]; ok {
+		t.Fatalf("notify has %d samples, below MinBaselineSamples; should be omitted", 1)
+	}
+	b, ok := got["load"]
+	if !ok {
+		t.Fatal("missing baseline for load")
+	}
+	if b.Samples != 5 || b.Median != 11*time.Second || b.P95 != 13*time.Second || b.MAD != time.Second {
+		t.Fatalf("baseline = %+v, want 5 samples, median 11s, p95 13s, MAD 1s", b)
+	}
+}
+
+func TestCheckDuration(t *testing.T) {
+	b := TaskBaseline{Samples: 10, Median: 60 * time.Second, P95: 70 * time.Second, MAD: 5 * time.Second}
+	cases := []struct {
+		d    time.Duration
+		want bool
+	}{
+		{50 * time.Second, false}, // fast is never anomalous
+		{68 * time.Second, false}, // under p95
+		{80 * time.Second, false}, // above p95 but z = 2.7
+		{120 * time.Second, true}, // z = 8.1
+		{10 * time.Minute, true},
+	}
+	for _, c := range cases {
+		if _, got := CheckDuration(c.d, b); got != c.want {
+			t.Errorf("CheckDuration(%s) = %v, want %v", c.d, got, c.want)
+		}
+	}
+
+	flat := TaskBaseline{Samples: 6, Median: 30 * time.Second, P95: 30 * time.Second}
+	if z, bad := CheckDuration(40*time.Second, flat); !bad || !math.IsInf(z, 1) {
+		t.Fatalf("no-spread history: z=%v bad=%v, want +Inf true", z, bad)
+	}
+	if _, bad := CheckDuration(32*time.Second, flat); bad {
+		t.Fatal("2s over a flat median is below minAnomalyExcess")
+	}
+	if _, bad := CheckDuration(time.Hour, TaskBaseline{Samples: 2}); bad {
+		t.Fatal("thin history must not flag")
+	}
+}
+
+func TestTopAnomaliesWorstPerTask(t *testing.T) {
+	var tasks []models.TaskInstance
+	for i, secs := range []float64{60, 62, 58, 61, 59, 60, 63, 600, 300} {
+		tasks = append(tasks, taskRun("extract", string(rune('a'+i)), "success", secs))
+	}
+	for i, secs := range []float64{10, 11, 10, 12, 10, 95} {
+		tasks = append(tasks, taskRun("load", string(rune('a'+i)), "success", secs))
+	}
+	for i := range 6 {
+		tasks = append(tasks, taskRun("notify", string(rune('a'+i)), "success", 5))
+	}
+
+	got := TopAnomalies(tasks, time.Now(), 0)
+	if len(got) != 2 {
+		t.Fatalf("anomalies = %+v, want extract and load", got)
+	}
+	if got[0].TaskId != "load" || got[1].TaskId != "extract" {
+		t.Fatalf("order = %s, %s; want load (higher z) first", got[0].TaskId, got[1].TaskId)
+	}
+	if got[1].Duration != 600*time.Second || got[1].RunId != "h" {
+		t.Fatalf("extract should report its worst instance, got %+v", got[1])
+	}
+	if capped := TopAnomalies(tasks, time.Now(), 1); len(capped) != 1 {
+		t.Fatalf("limit 1 returned %d", len(capped))
+	}
+}
+
+func TestTaskDurationRunning(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	start := now.Add(-90 * time.Second)
+	ti := models.TaskInstance{State: "running", StartDate: &start}
+	if got := TaskDuration(ti, now); got != 90*time.Second {
+		t.Fatalf("running duration = %s, want 1m30s", got)
+	}
+}
@@ -0,0 +1,244 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+// SLA kinds, one per expectation a DAG can declare.
+const (
+	SLAFinishBy      = "finish_by"
+	SLAMaxDuration   = "max_duration"
+	SLASuccessWithin = "success_within"
+)
+
+// SLA is the parsed set of expectations for one DAG. Zero fields are unset.
+type SLA struct {
+	DagId string
+
+	// FinishBy is the offset from local midnight in Location by which a
+	// successful run must have ended each day; HasFinishBy marks it set.
+	HasFinishBy bool
+	FinishBy    time.Duration
+	Location    *time.Location
+
+	MaxDuration   time.Duration
+	SuccessWithin time.Duration
+}
+
+// Lookback is how much run history EvaluateSLA needs to judge s.
+func (s SLA) Lookback() time.Duration {
+	d := 48 * time.Hour
+	if s.SuccessWithin+24*time.Hour > d {
+		d = s.SuccessWithin + 24*time.Hour
+	}
+	return d
+}
+
+// SLABreach is one violated expectation. Late is how far past the
+// expectation the DAG is (or was, for a run that finished late); it is 0 when
+// it cannot be measured, e.g. no success on record at all.
+type SLABreach struct {
+	DagId  string
+	Kind   string
+	RunId  string
+	Late   time.Duration
+	Detail string
+}
+
+// ParseSLA builds an SLA from its config strings; empty strings leave that
+// expectation unset. finishBy is "HH:MM" in tz (an IANA name; empty = UTC).
+func ParseSLA(dagId, finishBy, tz, maxDuration, successWithin string) (SLA, error) {
+	s := SLA{DagId: dagId, Location: time.UTC}
+	if tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return s, fmt.Errorf("sla %s: timezone %q: %w", dagId, tz, err)
+		}
+		s.Location = loc
+	}
+	if finishBy != "" {
+		t, err := time.Parse("15:04", finishBy)
+		if err != nil {
+			return s, fmt.Errorf("sla %s: finish_by %q: want HH:MM", dagId, finishBy)
+		}
+		s.HasFinishBy = true
+		s.FinishBy = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	var err error
+	if s.MaxDuration, err = parsePositive(maxDuration); err != nil {
+		return s, fmt.Errorf("sla %s: max_duration: %w", dagId, err)
+	}
+	if s.SuccessWithin, err = parsePositive(successWithin); err != nil {
+		return s, fmt.Errorf("sla %s: success_within: %w", dagId, err)
+	}
+	if !s.HasFinishBy && s.MaxDuration == 0 && s.SuccessWithin == 0 {
+		return s, fmt.Errorf("sla %s: no expectation set", dagId)
+	}
+	return s, nil
+}
+
+func parsePositive(v string) (time.Duration, error) {
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%q must be positive", v)
+	}
+	return d, nil
+}
+
+// EvaluateSLA checks s against runs (any order) at now.
+//
+//   - finish_by: once today's deadline has passed, a successful run must
+//     have ended in the 24h before it. A missing run is late by now-deadline;
+//     a run that succeeded after the deadline is reported as finished late.
+//   - max_duration: a running run, or the latest finished one, took longer.
+//   - success_within: the last success ended longer ago than allowed.
+func EvaluateSLA(s SLA, runs []models.DAGRun, now time.Time) []SLABreach {
+	var out []SLABreach
+	if s.HasFinishBy {
+		if b, ok := checkFinishBy(s, runs, now); ok {
+			out = append(out, b)
+		}
+	}
+	if s.MaxDuration > 0 {
+		out = append(out, checkMaxDuration(s, runs, now)...)
+	}
+	if s.SuccessWithin > 0 {
+		if b, ok := checkSuccessWithin(s, runs, now); ok {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// lastDeadline is the most recent finish-by instant at or before now.
+func lastDeadline(s SLA, now time.Time) time.Time {
+	local := now.In(s.Location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.Location)
+	d := midnight.Add(s.FinishBy)
+	if d.After(now) {
+		prev := midnight.AddDate(0, 0, -1)
+		d = prev.Add(s.FinishBy)
+	}
+	return d
+}
+
+func checkFinishBy(s SLA, runs []models.DAGRun, now time.Time) (SLABreach, bool) {
+	deadline := lastDeadline(s, now)
+	var lateRun *models.DAGRun
+	for i, r := range runs {
+		if r.State != "success" || r.EndDate.IsZero() {
+			continue
+		}
+		if r.EndDate.After(deadline.Add(-24*time.Hour)) && !r.EndDate.After(deadline) {
+			return SLABreach{}, false // on time
+		}
+		if r.EndDate.After(deadline) && (lateRun == nil || r.EndDate.Before(lateRun.EndDate)) {
+			lateRun = &runs[i]
+		}
+	}
+	due := deadline.In(s.Location).Format("15:04 MST")
+	if lateRun != nil {
+		late := lateRun.EndDate.Sub(deadline)
+		return SLABreach{
+			DagId: s.DagId, Kind: SLAFinishBy, RunId: lateRun.RunId, Late: late,
+			Detail: fmt.Sprintf("finished %s after %s", FormatLate(late), due),
+		}, true
+	}
+	late := now.Sub(deadline)
+	return SLABreach{
+		DagId: s.DagId, Kind: SLAFinishBy, Late: late,
+		Detail: fmt.Sprintf("no success by %s", due),
+	}, true
+}
+
+func checkMaxDuration(s SLA, runs []models.DAGRun, now time.Time) []SLABreach {
+	var out []SLABreach
+	var latest *models.DAGRun
+	for i, r := range runs {
+		if r.StartDate.IsZero() {
+			continue
+		}
+		if r.State == "running" {
+			if d := now.Sub(r.StartDate); d > s.MaxDuration {
+				out = append(out, SLABreach{
+					DagId: s.DagId, Kind: SLAMaxDuration, RunId: r.RunId, Late: d - s.MaxDuration,
+					Detail: fmt.Sprintf("running %s, budget %s", FormatLate(d), FormatLate(s.MaxDuration)),
+				})
+			}
+			continue
+		}
+		if isTerminal(r.State) && !r.EndDate.IsZero() && (latest == nil || r.EndDate.After(latest.EndDate)) {
+			latest = &runs[i]
+		}
+	}
+	if latest != nil {
+		if d := latest.EndDate.Sub(latest.StartDate); d > s.MaxDuration {
+			out = append(out, SLABreach{
+				DagId: s.DagId, Kind: SLAMaxDuration, RunId: latest.RunId, Late: d - s.MaxDuration,
+				Detail: fmt.Sprintf("last run took %s, budget %s", FormatLate(d), FormatLate(s.MaxDuration)),
+			})
+		}
+	}
+	return out
+}
+
+func checkSuccessWithin(s SLA, runs []models.DAGRun, now time.Time) (SLABreach, bool) {
+	var last *models.DAGRun
+	for i, r := range runs {
+		if r.State == "success" && !r.EndDate.IsZero() && (last == nil || r.EndDate.After(last.EndDate)) {
+			last = &runs[i]
+		}
+	}
+	if last == nil {
+		return SLABreach{
+			DagId: s.DagId, Kind: SLASuccessWithin,
+			Detail: fmt.Sprintf("no success in the last %s", FormatLate(s.Lookback())),
+		}, true
+	}
+	age := now.Sub(last.EndDate)
+	if age <= s.SuccessWithin {
+		return SLABreach{}, false
+	}
+	return SLABreach{
+		DagId: s.DagId, Kind: SLASuccessWithin, RunId: last.RunId, Late: age - s.SuccessWithin,
+		Detail: fmt.Sprintf("last success %s ago, allowed %s", FormatLate(age), FormatLate(s.SuccessWithin)),
+	}, true
+}
+
+// SortBreaches orders breaches latest-first, then by DAG id and kind.
+func SortBreaches(b []SLABreach) {
+	sort.SliceStable(b, func(i, j int) bool {
+		if b[i].Late != b[j].Late {
+			return b[i].Late > b[j].Late
+		}
+		if b[i].DagId != b[j].DagId {
+			return b[i].DagId < b[j].DagId
+		}
+		return b[i].Kind < b[j].Kind
+	})
+}
+
+// FormatLate renders a lateness compactly: 45s, 12m, 3h05m, 2d4h.
+func FormatLate(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+}
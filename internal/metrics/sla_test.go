@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+func slaRun(runId, state string, start, end time.Time) models.DAGRun {
+	return models.DAGRun{DagId: "etl", RunId: runId, State: state, StartDate: start, EndDate: end}
+}
+
+func TestParseSLA(t *testing.T) {
+	s, err := ParseSLA("etl", "06:30", "UTC", "1h", "26h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.HasFinishBy || s.FinishBy != 6*time.Hour+30*time.Minute || s.MaxDuration != time.Hour || s.SuccessWithin != 26*time.Hour {
+		t.Fatalf("parsed = %+v", s)
+	}
+	for _, bad := range [][4]string{
+		{"6.30", "", "", ""},
+		{"", "Mars/Base", "", ""},
+		{"", "", "-1h", ""},
+		{"", "", "", "soon"},
+		{"", "", "", ""},
+	} {
+		if _, err := ParseSLA("etl", bad[0], bad[1], bad[2], bad[3]); err == nil {
+			t.Errorf("ParseSLA(%q) = nil error, want error", bad)
+		}
+	}
+}
+
+func TestEvaluateSLAFinishBy(t *testing.T) {
+	s, _ := ParseSLA("etl", "06:00", "", "", "")
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	onTime := []models.DAGRun{slaRun("r1", "success", day.Add(5*time.Hour), day.Add(5*time.Hour+30*time.Minute))}
+	if got := EvaluateSLA(s, onTime, day.Add(9*time.Hour)); len(got) != 0 {
+		t.Fatalf("on-time run breached: %+v", got)
+	}
+
+	// Before today's deadline, yesterday's deadline is the one judged.
+	if got := EvaluateSLA(s, onTime, day.Add(29*time.Hour)); len(got) != 0 {
+		t.Fatalf("judged before the deadline: %+v", got)
+	}
+
+	got := EvaluateSLA(s, nil, day.Add(8*time.Hour))
+	if len(got) != 1 || got[0].Late != 2*time.Hour || got[0].Kind != SLAFinishBy {
+		t.Fatalf("missing run = %+v, want one finish_by breach 2h late", got)
+	}
+
+	late := []models.DAGRun{slaRun("r2", "success", day.Add(5*time.Hour), day.Add(6*time.Hour+20*time.Minute))}
+	got = EvaluateSLA(s, late, day.Add(8*time.Hour))
+	if len(got) != 1 || got[0].Late != 20*time.Minute || got[0].RunId != "r2" {
+		t.Fatalf("late run = %+v, want breach 20m late on r2", got)
+	}
+	if !strings.Contains(got[0].Detail, "finished 20m after 06:00") {
+		t.Errorf("detail = %q", got[0].Detail)
+	}
+}
+
+func TestEvaluateSLAFinishByTimezone(t *testing.T) {
+	loc := time.FixedZone("KST", 9*3600)
+	s := SLA{DagId: "etl", HasFinishBy: true, FinishBy: 6 * time.Hour, Location: loc}
+	// 06:00 KST is 21:00 UTC the previous day.
+	now := time.Date(2026, 3, 9, 22, 0, 0, 0, time.UTC)
+	got := EvaluateSLA(s, nil, now)
+	if len(got) != 1 || got[0].Late != time.Hour {
+		t.Fatalf("got %+v, want breach 1h late", got)
+	}
+}
+
+func TestEvaluateSLAMaxDuration(t *testing.T) {
+	s, _ := ParseSLA("etl", "", "", "30m", "")
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	runs := []models.DAGRun{
+		slaRun("old", "success", now.Add(-5*time.Hour), now.Add(-4*time.Hour)), // superseded
+		slaRun("last", "failed", now.Add(-3*time.Hour), now.Add(-3*time.Hour+10*time.Minute)),
+		slaRun("live", "running", now.Add(-45*time.Minute), time.Time{}),
+	}
+	got := EvaluateSLA(s, runs, now)
+	if len(got) != 1 || got[0].RunId != "live" || got[0].Late != 15*time.Minute {
+		t.Fatalf("got %+v, want only the running run, 15m over", got)
+	}
+
+	runs[1].EndDate = now.Add(-2 * time.Hour)
+	if got := EvaluateSLA(s, runs, now); len(got) != 2 || got[1].RunId != "last" {
+		t.Fatalf("got %+v, want the latest finished run over budget too", got)
+	}
+}
+
+func TestEvaluateSLASuccessWithin(t *testing.T) {
+	s, _ := ParseSLA("etl", "", "", "", "24h")
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	fresh := []models.DAGRun{slaRun("r1", "success", now.Add(-11*time.Hour), now.Add(-10*time.Hour))}
+	if got := EvaluateSLA(s, fresh, now); len(got) != 0 {
+		t.Fatalf("fresh success breached: %+v", got)
+	}
+	stale := []models.DAGRun{
+		slaRun("r1", "success", now.Add(-31*time.Hour), now.Add(-30*time.Hour)),
+		slaRun("r2", "failed", now.Add(-2*time.Hour), now.Add(-time.Hour)),
+	}
+	got := EvaluateSLA(s, stale, now)
+	if len(got) != 1 || got[0].Late != 6*time.Hour || got[0].RunId != "r1" {
+		t.Fatalf("got %+v, want breach 6h late on r1", got)
+	}
+	got = EvaluateSLA(s, nil, now)
+	if len(got) != 1 || got[0].Late != 0 || !strings.Contains(got[0].Detail, "no success") {
+		t.Fatalf("no history = %+v, want an unmeasured breach", got)
+	}
+}
+
+func TestFormatLate(t *testing.T) {
+	cases := map[time.Duration]string{
+		45 * time.Second:            "45s",
+		12 * time.Minute:            "12m",
+		3*time.Hour + 5*time.Minute: "3h05m",
+		52 * time.Hour:              "2d4h",
+	}
+	for d, want := range cases {
+		if got := FormatLate(d); got != want {
+			t.Errorf("FormatLate(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
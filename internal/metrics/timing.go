@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+// TaskTiming is where a task usually sits inside its run: the median offsets
+// of its start and end from the moment the run's first task was queued.
+type TaskTiming struct {
+	Samples int
+	Start   time.Duration
+	End     time.Duration
+}
+
+// TaskTimings builds a TaskTiming per task id from successful instances in
+// history, measuring each against its own run's earliest queued time (the
+// Gantt's left edge). Instances of excludeRunId are left out; tasks with
+// fewer than MinBaselineSamples samples are omitted.
+func TaskTimings(history []models.TaskInstance, excludeRunId string) map[string]TaskTiming {
+	origin := map[string]time.Time{}
+	for _, ti := range history {
+		if ti.QueuedDttm == nil || ti.RunId == excludeRunId {
+			continue
+		}
+		if o, ok := origin[ti.RunId]; !ok || ti.QueuedDttm.Before(o) {
+			origin[ti.RunId] = *ti.QueuedDttm
+		}
+	}
+	starts, ends := map[string][]time.Duration{}, map[string][]time.Duration{}
+	for _, ti := range history {
+		o, ok := origin[ti.RunId]
+		if !ok || ti.State != "success" || ti.StartDate == nil || ti.EndDate == nil {
+			continue
+		}
+		starts[ti.TaskId] = append(starts[ti.TaskId], ti.StartDate.Sub(o))
+		ends[ti.TaskId] = append(ends[ti.TaskId], ti.EndDate.Sub(o))
+	}
+	out := make(map[string]TaskTiming, len(starts))
+	for taskId, s := range starts {
+		if len(s) < MinBaselineSamples {
+			continue
+		}
+		e := ends[taskId]
+		sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+		sort.Slice(e, func(i, j int) bool { return e[i] < e[j] })
+		out[taskId] = TaskTiming{Samples: len(s), Start: medianOf(s), End: medianOf(e)}
+	}
+	return out
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+func TestTaskTimings(t *testing.T) {
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time { v := base.Add(d); return &v }
+	var history []models.TaskInstance
+	for i := range 5 {
+		runId := fmt.Sprintf("r%d", i)
+		shift := time.Duration(i) * time.Hour // runs start at different times
+		slack := time.Duration(i) * time.Second
+		history = append(history,
+			models.TaskInstance{TaskId: "extract", RunId: runId, State: "success",
+				QueuedDttm: at(shift), StartDate: at(shift + 5*time.Second), EndDate: at(shift + time.Minute + slack)},
+			models.TaskInstance{TaskId: "load", RunId: runId, State: "success",
+				QueuedDttm: at(shift + time.Minute), StartDate: at(shift + 70*time.Second), EndDate: at(shift + 3*time.Minute)},
+		)
+	}
+	history = append(history,
+		// The run being drawn, and a failed try, stay out of the medians.
+		models.TaskInstance{TaskId: "extract", RunId: "cur", State: "success",
+			QueuedDttm: at(-time.Hour), StartDate: at(-time.Hour), EndDate: at(0)},
+		models.TaskInstance{TaskId: "load", RunId: "r9", State: "failed",
+			QueuedDttm: at(0), StartDate: at(0), EndDate: at(time.Hour)},
+	)
+
+	got := TaskTimings(history, "cur")
+	if e := got["extract"]; e.Samples != 5 || e.Start != 5*time.Second || e.End != time.Minute+2*time.Second {
+		t.Errorf("extract = %+v, want start 5s end 1m2s", e)
+	}
+	if l := got["load"]; l.Samples != 5 || l.Start != 70*time.Second || l.End != 3*time.Minute {
+		t.Errorf("load = %+v, want start 1m10s end 3m", l)
+	}
+
+	if few := TaskTimings(history[:4], ""); len(few) != 0 {
+		t.Errorf("two runs of history should give no timings, got %v", few)
+	}
+}
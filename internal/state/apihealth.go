@@ -0,0 +1,158 @@
+package state
+
+import (
+	"sort"
+	"time"
+)
+
+// EventAPIHealthChanged fires when an API call fails or the derived
+// connection state changes. Plain successes that change nothing stay quiet.
+const EventAPIHealthChanged = "api_health_changed"
+
+// API error kinds, as classified by the caller.
+const (
+	APIErrAuth        = "auth"        // 401 or the token request was refused
+	APIErrUnreachable = "unreachable" // transport failure, timeout, gateway error
+	APIErrOther       = "error"       // the API answered with an error
+)
+
+// Connection states derived from endpoint health, worst first.
+const (
+	ConnAuthFailed   = "auth_failed"
+	ConnDisconnected = "disconnected"
+	ConnDegraded     = "degraded"
+	ConnConnecting   = "connecting" // nothing has answered yet
+	ConnConnected    = "connected"
+)
+
+// maxAPIErrors bounds the error panel's history.
+const maxAPIErrors = 100
+
+// EndpointHealth is the latest outcome of one polled API endpoint.
+type EndpointHealth struct {
+	Endpoint    string
+	LastSuccess time.Time
+	LastError   time.Time
+	Err         string
+	Kind        string
+	Failures    int // consecutive failures since the last success
+}
+
+// APIError is one failed API call, kept for the error panel.
+type APIError struct {
+	At       time.Time
+	Endpoint string
+	Kind     string
+	Message  string
+}
+
+// ConnectionHealth summarises every endpoint for the header and status bar.
+type ConnectionHealth struct {
+	State       string
+	LastSuccess time.Time // most recent success on any endpoint
+	Failing     int       // endpoints whose latest call failed
+	Detail      string    // latest error behind a non-connected state
+}
+
+// RecordAPIResult stores the outcome of a call to endpoint. kind is one of
+// the APIErr* constants and is ignored when err is nil.
+func (s *Store) RecordAPIResult(endpoint string, err error, kind string) {
+	now := time.Now()
+	s.mu.Lock()
+	before := s.connectionHealthLocked()
+	h := s.endpoints[endpoint]
+	h.Endpoint = endpoint
+	if err == nil {
+		h.LastSuccess = now
+		h.Failures = 0
+	} else {
+		h.LastError = now
+		h.Err = err.Error()
+		h.Kind = kind
+		h.Failures++
+		s.apiErrors = append(s.apiErrors, APIError{At: now, Endpoint: endpoint, Kind: kind, Message: h.Err})
+		if len(s.apiErrors) > maxAPIErrors {
+			s.apiErrors = s.apiErrors[len(s.apiErrors)-maxAPIErrors:]
+		}
+	}
+	s.endpoints[endpoint] = h
+	after := s.connectionHealthLocked()
+	s.mu.Unlock()
+
+	if err != nil || before.State != after.State || before.Failing != after.Failing {
+		s.notify(EventAPIHealthChanged, nil)
+	}
+}
+
+// GetEndpointHealth returns every endpoint seen so far, by name.
+func (s *Store) GetEndpointHealth() []EndpointHealth {
+	s.mu.RLock()
+	out := make([]EndpointHealth, 0, len(s.endpoints))
+	for _, h := range s.endpoints {
+		out = append(out, h)
+	}
+	s.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Endpoint < out[j].Endpoint })
+	return out
+}
+
+// GetAPIErrors returns the recent API errors, newest first.
+func (s *Store) GetAPIErrors() []APIError {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]APIError, len(s.apiErrors))
+	for i, e := range s.apiErrors {
+		out[len(out)-1-i] = e
+	}
+	return out
+}
+
+// ConnectionHealth derives the overall connection state.
+func (s *Store) ConnectionHealth() ConnectionHealth {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.connectionHealthLocked()
+}
+
+func (s *Store) connectionHealthLocked() ConnectionHealth {
+	var (
+		out             ConnectionHealth
+		auth, other     *EndpointHealth
+		anySuccess      bool
+		latestErrorTime time.Time
+	)
+	for name := range s.endpoints {
+		h := s.endpoints[name]
+		if h.LastSuccess.After(out.LastSuccess) {
+			out.LastSuccess = h.LastSuccess
+		}
+		anySuccess = anySuccess || !h.LastSuccess.IsZero()
+		if h.Failures == 0 {
+			continue
+		}
+		out.Failing++
+		if h.Kind == APIErrAuth && (auth == nil || h.LastError.After(auth.LastError)) {
+			auth = &h
+		}
+		if h.LastError.After(latestErrorTime) {
+			latestErrorTime = h.LastError
+			other = &h
+		}
+	}
+	switch {
+	case auth != nil:
+		out.State, out.Detail = ConnAuthFailed, auth.Err
+	case s.offline:
+		out.State = ConnDisconnected
+		if other != nil {
+			out.Detail = other.Err
+		}
+	case other != nil:
+		out.State, out.Detail = ConnDegraded, other.Endpoint+": "+other.Err
+	case !anySuccess:
+		out.State = ConnConnecting
+	default:
+		out.State = ConnConnected
+	}
+	return out
+}
@@ -0,0 +1,65 @@
+package state
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestConnectionHealth_states(t *testing.T) {
+	s := NewStore()
+	if got := s.ConnectionHealth().State; got != ConnConnecting {
+		t.Fatalf("initial state = %q, want connecting", got)
+	}
+
+	s.RecordAPIResult("dags", nil, "")
+	s.RecordAPIResult("pools", nil, "")
+	if got := s.ConnectionHealth(); got.State != ConnConnected || got.LastSuccess.IsZero() {
+		t.Fatalf("after successes = %+v", got)
+	}
+
+	s.RecordAPIResult("pools", errors.New("api error 500"), APIErrOther)
+	if got := s.ConnectionHealth(); got.State != ConnDegraded || got.Failing != 1 {
+		t.Fatalf("one failing endpoint = %+v, want degraded", got)
+	}
+
+	s.SetOffline(true)
+	if got := s.ConnectionHealth().State; got != ConnDisconnected {
+		t.Fatalf("offline state = %q", got)
+	}
+
+	s.RecordAPIResult("dags", errors.New("api error 401 Unauthorized"), APIErrAuth)
+	if got := s.ConnectionHealth(); got.State != ConnAuthFailed || got.Detail != "api error 401 Unauthorized" {
+		t.Fatalf("auth failure = %+v, want auth_failed to outrank offline", got)
+	}
+
+	s.SetOffline(false)
+	s.RecordAPIResult("dags", nil, "")
+	s.RecordAPIResult("pools", nil, "")
+	if got := s.ConnectionHealth().State; got != ConnConnected {
+		t.Fatalf("recovered state = %q", got)
+	}
+}
+
+func TestRecordAPIResult_errorLogAndNotify(t *testing.T) {
+	s := NewStore()
+	var events atomic.Int32
+	s.Subscribe(EventAPIHealthChanged, func(_ any) { events.Add(1) })
+
+	s.RecordAPIResult("dags", nil, "") // connecting → connected
+	s.RecordAPIResult("dags", nil, "") // no change: quiet
+	for i := 0; i < maxAPIErrors+5; i++ {
+		s.RecordAPIResult("logs", errors.New("boom"), APIErrOther)
+	}
+	if got := events.Load(); got != 1+maxAPIErrors+5 {
+		t.Fatalf("events = %d", got)
+	}
+	errs := s.GetAPIErrors()
+	if len(errs) != maxAPIErrors || errs[0].Endpoint != "logs" || !errs[0].At.After(errs[len(errs)-1].At) && errs[0].At != errs[len(errs)-1].At {
+		t.Fatalf("error log len=%d first=%+v", len(errs), errs[0])
+	}
+	health := s.GetEndpointHealth()
+	if len(health) != 2 || health[0].Endpoint != "dags" || health[1].Failures != maxAPIErrors+5 {
+		t.Fatalf("endpoint health = %+v", health)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/yjinheon/lazyflow/internal/debugutil"
+	"github.com/yjinheon/lazyflow/internal/metrics"
 	"github.com/yjinheon/lazyflow/pkg/airflow/models"
 )
 
@@ -27,6 +28,8 @@ const (
 	EventCriticalPathChanged  = "critical_path_changed"
 	EventPoolsUpdated         = "pools_updated"
 	EventDAGStateRollupUpdated = "dag_state_rollup_updated"
+	EventSLAUpdated            = "sla_updated"
+	EventConnectivityChanged   = "connectivity_changed"
 )
 
 type Store struct {
@@ -44,6 +47,10 @@ type Store struct {
 	criticalPath     map[string]bool
 	pools            []models.Pool
 	dagStateRollup   map[string]string // dagId -> latest run state (cluster-wide)
+	slaBreaches      []metrics.SLABreach
+	offline          bool // API unreachable; views are served from the history cache
+	endpoints        map[string]EndpointHealth
+	apiErrors        []APIError // oldest first, capped at maxAPIErrors
 
 	// Selection state
 	selectedDAG  string
@@ -72,6 +79,7 @@ func NewStore() *Store {
 		selectedBackfill: -1,
 		criticalPath:     make(map[string]bool),
 		dagStateRollup:   make(map[string]string),
+		endpoints:        make(map[string]EndpointHealth),
 	}
 }
 
@@ -219,6 +227,46 @@ func (s *Store) GetDAGStateRollup() map[string]string {
 	return out
 }
 
+// ---------- SLA ----------
+
+// SetSLABreaches replaces the current SLA breaches (all configured DAGs).
+func (s *Store) SetSLABreaches(breaches []metrics.SLABreach) {
+	s.mu.Lock()
+	s.slaBreaches = breaches
+	s.lastRefresh["sla"] = time.Now()
+	s.mu.Unlock()
+
+	s.notify(EventSLAUpdated, breaches)
+}
+
+// GetSLABreaches returns a defensive copy of the current SLA breaches.
+func (s *Store) GetSLABreaches() []metrics.SLABreach {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]metrics.SLABreach, len(s.slaBreaches))
+	copy(out, s.slaBreaches)
+	return out
+}
+
+// SetOffline records whether the API is reachable, notifying only on change.
+func (s *Store) SetOffline(offline bool) {
+	s.mu.Lock()
+	changed := s.offline != offline
+	s.offline = offline
+	s.mu.Unlock()
+
+	if changed {
+		s.notify(EventConnectivityChanged, offline)
+	}
+}
+
+// Offline reports whether the UI is in offline (cache-backed, read-only) mode.
+func (s *Store) Offline() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.offline
+}
+
 // ---------- Selection ----------
 
 func (s *Store) SelectDAG(dagId string) {
@@ -5,9 +5,11 @@ import (
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+	"github.com/yjinheon/lazyflow/internal/cache"
 	"github.com/yjinheon/lazyflow/internal/debugutil"
 	"github.com/yjinheon/lazyflow/internal/state"
 	"github.com/yjinheon/lazyflow/internal/ui/layout"
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
 )
 
 var tabNames = []struct {
@@ -27,6 +29,10 @@ var tabNames = []struct {
 	{'?', "help"},
 }
 
+// mutatingKeys are the rune keys whose actions change Airflow state. In
+// read-only mode they are not bound at all and fall through to the widget.
+var mutatingKeys = map[rune]bool{'t': true, 'T': true, 'n': true, 'p': true, 'b': true, 'c': true, 'u': true, 'U': true}
+
 // tabForRune resolves a digit key to its tab name.
 func tabForRune(r rune) (string, bool) {
 	for _, t := range tabNames {
@@ -51,21 +57,46 @@ type KeyBindings struct {
 	onBackfillUnpause func(id int)
 	onMonitorWindow   func(delta int)
 	onMonitorRefresh  func()
+	onViewConf        func(dagId, runId string)
+	onRetrigger       func(dagId, runId string)
+	onEditRunNote     func(dagId, runId string)
+	onEditTaskNote    func(dagId, runId, taskId string, mapIndex int)
+	onCompare         func(a, b models.DAGRun)
+	onGrid            func(dagId string)
+	onExport          func(dagId string)
+	onActivity        func()
+	onShowActions     func()
+	onRevert          func(a cache.ActionRecord)
+
+	readOnly bool
 }
 
 func NewKeyBindings(app *tview.Application, l *layout.MainLayout, s *state.Store) *KeyBindings {
 	return &KeyBindings{app: app, layout: l, store: s}
 }
 
-func (kb *KeyBindings) SetOnRefresh(fn func())            { kb.onRefresh = fn }
-func (kb *KeyBindings) SetOnTrigger(fn func(string))      { kb.onTrigger = fn }
-func (kb *KeyBindings) SetOnPause(fn func(string))        { kb.onPause = fn }
-func (kb *KeyBindings) SetOnBackfill(fn func(string))     { kb.onBackfill = fn }
-func (kb *KeyBindings) SetOnBackfillCancel(fn func(int))  { kb.onBackfillCancel = fn }
-func (kb *KeyBindings) SetOnBackfillPause(fn func(int))   { kb.onBackfillPause = fn }
-func (kb *KeyBindings) SetOnBackfillUnpause(fn func(int)) { kb.onBackfillUnpause = fn }
-func (kb *KeyBindings) SetOnMonitorWindow(fn func(int))   { kb.onMonitorWindow = fn }
-func (kb *KeyBindings) SetOnMonitorRefresh(fn func())     { kb.onMonitorRefresh = fn }
+func (kb *KeyBindings) SetOnRefresh(fn func())                   { kb.onRefresh = fn }
+func (kb *KeyBindings) SetOnTrigger(fn func(string))             { kb.onTrigger = fn }
+func (kb *KeyBindings) SetOnPause(fn func(string))               { kb.onPause = fn }
+func (kb *KeyBindings) SetOnBackfill(fn func(string))            { kb.onBackfill = fn }
+func (kb *KeyBindings) SetOnBackfillCancel(fn func(int))         { kb.onBackfillCancel = fn }
+func (kb *KeyBindings) SetOnBackfillPause(fn func(int))          { kb.onBackfillPause = fn }
+func (kb *KeyBindings) SetOnBackfillUnpause(fn func(int))        { kb.onBackfillUnpause = fn }
+func (kb *KeyBindings) SetOnMonitorWindow(fn func(int))          { kb.onMonitorWindow = fn }
+func (kb *KeyBindings) SetOnMonitorRefresh(fn func())            { kb.onMonitorRefresh = fn }
+func (kb *KeyBindings) SetOnViewConf(fn func(string, string))    { kb.onViewConf = fn }
+func (kb *KeyBindings) SetOnRetrigger(fn func(string, string))   { kb.onRetrigger = fn }
+func (kb *KeyBindings) SetOnEditRunNote(fn func(string, string)) { kb.onEditRunNote = fn }
+func (kb *KeyBindings) SetOnEditTaskNote(fn func(string, string, string, int)) {
+	kb.onEditTaskNote = fn
+}
+func (kb *KeyBindings) SetOnCompare(fn func(a, b models.DAGRun)) { kb.onCompare = fn }
+func (kb *KeyBindings) SetOnGrid(fn func(string))                { kb.onGrid = fn }
+func (kb *KeyBindings) SetOnExport(fn func(string))              { kb.onExport = fn }
+func (kb *KeyBindings) SetOnActivity(fn func())                  { kb.onActivity = fn }
+func (kb *KeyBindings) SetOnShowActions(fn func())               { kb.onShowActions = fn }
+func (kb *KeyBindings) SetOnRevert(fn func(cache.ActionRecord))  { kb.onRevert = fn }
+func (kb *KeyBindings) SetReadOnly(on bool)                      { kb.readOnly = on }
 
 // Install registers the global input capture on the tview application.
 func (kb *KeyBindings) Install() {
@@ -81,6 +112,9 @@ func (kb *KeyBindings) handle(event *tcell.EventKey) *tcell.EventKey {
 		}
 	}()
 	debugutil.Tag("FZ-key", "handle key=%v rune=%q", event.Key(), event.Rune())
+	if kb.onActivity != nil {
+		kb.onActivity()
+	}
 
 	if kb.layout.IsSearchVisible() {
 		switch event.Key() {
@@ -129,7 +163,7 @@ func (kb *KeyBindings) handle(event *tcell.EventKey) *tcell.EventKey {
 			case "logs":
 				kb.switchToTab("tasks")
 				return nil
-			case "tasks":
+			case "tasks", "compare", "grid":
 				kb.switchToTab("runs")
 				return nil
 			}
@@ -157,6 +191,10 @@ func (kb *KeyBindings) handle(event *tcell.EventKey) *tcell.EventKey {
 		return event
 	}
 
+	if kb.readOnly && mutatingKeys[event.Rune()] {
+		return event
+	}
+
 	// Rune keys
 	switch event.Rune() {
 	// Tab switching (0-9)
@@ -183,19 +221,24 @@ func (kb *KeyBindings) handle(event *tcell.EventKey) *tcell.EventKey {
 	case 'g':
 		// Consumed only where it toggles a view; elsewhere it stays tview's jump-to-top.
 		switch kb.store.ActiveTab() {
+		case "runs":
+			dagId := kb.store.SelectedDAG()
+			if dagId == "" || kb.onGrid == nil {
+				return event
+			}
+			kb.layout.ShowGrid()
+			kb.store.SetActiveTab("grid")
+			kb.onGrid(dagId)
+		case "grid":
+			kb.switchToTab("runs")
 		case "tasks":
 			kb.store.SetGanttMode(!kb.store.GanttMode())
 		case "lineage":
 			on := !kb.layout.Lineage().IsGraphMode()
 			kb.layout.Lineage().SetGraphMode(on)
 			if on {
-				dagId := kb.store.SelectedDAG()
 				runId := kb.store.SelectedRun()
-				stateByTask := map[string]string{}
-				for _, ti := range kb.store.GetTaskInstances(dagId, runId) {
-					stateByTask[ti.TaskId] = ti.State
-				}
-				kb.layout.Lineage().UpdateGraph(stateByTask)
+				kb.layout.Lineage().UpdateGraph(runId, kb.store.GetTaskInstances(kb.store.SelectedDAG(), runId))
 			}
 		default:
 			return event
@@ -236,6 +279,58 @@ func (kb *KeyBindings) handle(event *tcell.EventKey) *tcell.EventKey {
 			kb.onTrigger(dagId)
 		}
 		return nil
+	case 'v':
+		if kb.store.ActiveTab() == "runs" && kb.onViewConf != nil {
+			if run, ok := kb.layout.Runs().CurrentRun(); ok {
+				kb.onViewConf(run.DagId, run.RunId)
+			}
+			return nil
+		}
+		return event
+	case 'T':
+		if kb.store.ActiveTab() == "runs" && kb.onRetrigger != nil {
+			if run, ok := kb.layout.Runs().CurrentRun(); ok {
+				kb.onRetrigger(run.DagId, run.RunId)
+			}
+			return nil
+		}
+		return event
+	case 'm':
+		if kb.store.ActiveTab() == "runs" {
+			kb.layout.Runs().ToggleMark()
+			return nil
+		}
+		return event
+	case 'C':
+		if kb.store.ActiveTab() != "runs" || kb.onCompare == nil {
+			return event
+		}
+		marked := kb.layout.Runs().MarkedRuns()
+		if len(marked) != 2 {
+			kb.layout.StatusBar().SetStatus("[yellow]Mark two runs with m to compare[-]")
+			return nil
+		}
+		kb.layout.ShowCompare()
+		kb.store.SetActiveTab("compare")
+		kb.onCompare(marked[0], marked[1])
+		return nil
+	case 'n':
+		switch kb.store.ActiveTab() {
+		case "runs":
+			if run, ok := kb.layout.Runs().CurrentRun(); ok && kb.onEditRunNote != nil {
+				kb.onEditRunNote(run.DagId, run.RunId)
+			}
+		case "tasks":
+			if !kb.layout.Tasks().ShowingRun() {
+				return event
+			}
+			if ti, ok := kb.layout.Execution().CurrentTask(); ok && kb.onEditTaskNote != nil {
+				kb.onEditTaskNote(ti.DagId, ti.RunId, ti.TaskId, kb.layout.Execution().CurrentMapIndex())
+			}
+		default:
+			return event
+		}
+		return nil
 	case 'p':
 		if kb.store.ActiveTab() == "backfills" {
 			if id := kb.store.SelectedBackfill(); id > 0 && kb.onBackfillPause != nil {
@@ -284,12 +379,63 @@ func (kb *KeyBindings) handle(event *tcell.EventKey) *tcell.EventKey {
 			return nil
 		}
 		return event
+	case 's':
+		if kb.store.ActiveTab() == "monitor" && kb.layout.Monitor().FleetMode() {
+			kb.layout.Monitor().CycleFleetSort()
+			return nil
+		}
+		return event
+	case 'F':
+		if kb.store.ActiveTab() == "monitor" {
+			kb.layout.Monitor().ToggleFleet()
+			if kb.onMonitorRefresh != nil {
+				kb.onMonitorRefresh()
+			}
+			return nil
+		}
+		return event
+
+	// History export; the selected DAG (if any) pre-fills the form.
+	case 'E':
+		if kb.onExport != nil {
+			kb.onExport(kb.store.SelectedDAG())
+		}
+		return nil
 
 	// Search
 	case '/':
 		kb.layout.ShowSearch()
 		return nil
 
+	// SLA breaches
+	case 'S':
+		kb.layout.ShowSLA()
+		kb.store.SetActiveTab("sla")
+		return nil
+
+	// API health and recent errors
+	case '!':
+		kb.layout.ShowAPIErrors()
+		kb.store.SetActiveTab("errors")
+		return nil
+
+	// Action history; U undoes the selected action.
+	case 'H':
+		kb.layout.ShowActions()
+		kb.store.SetActiveTab("actions")
+		if kb.onShowActions != nil {
+			kb.onShowActions()
+		}
+		return nil
+	case 'U':
+		if kb.store.ActiveTab() != "actions" {
+			return event
+		}
+		if a, ok := kb.layout.Actions().CurrentAction(); ok && kb.onRevert != nil {
+			kb.onRevert(a)
+		}
+		return nil
+
 	// Help
 	case '?':
 		kb.layout.ShowHelp()
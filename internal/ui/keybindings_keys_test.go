@@ -6,6 +6,7 @@ import (
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+	"github.com/yjinheon/lazyflow/internal/cache"
 	"github.com/yjinheon/lazyflow/internal/state"
 	"github.com/yjinheon/lazyflow/internal/ui/layout"
 )
@@ -86,9 +87,11 @@ func TestCycleTabSkipsHelp(t *testing.T) {
 	}
 }
 
-// 'g' toggles a view only on tasks/lineage; elsewhere it stays tview's jump-to-top.
+// 'g' toggles a view only on tasks/lineage (and runs, once a DAG is selected);
+// elsewhere it stays tview's jump-to-top.
 func TestGIsConditionallyConsumed(t *testing.T) {
 	kb, _, s := newKB(t)
+	kb.SetOnGrid(func(string) {})
 
 	for _, tab := range []string{"tasks", "lineage"} {
 		s.SetActiveTab(tab)
@@ -104,6 +107,29 @@ func TestGIsConditionallyConsumed(t *testing.T) {
 	}
 }
 
+// 'g' on Runs opens the selected DAG's grid; 'g' or Esc there goes back.
+func TestGOpensAndClosesGrid(t *testing.T) {
+	kb, l, s := newKB(t)
+	var opened string
+	kb.SetOnGrid(func(dagId string) { opened = dagId })
+	s.SelectDAG("etl")
+
+	for _, back := range []*tcell.EventKey{key(tcell.KeyRune, 'g', tcell.ModNone), key(tcell.KeyEsc, 0, tcell.ModNone)} {
+		s.SetActiveTab("runs")
+		if kb.handle(key(tcell.KeyRune, 'g', tcell.ModNone)) != nil {
+			t.Fatal("'g' on runs with a DAG selected should be consumed")
+		}
+		if s.ActiveTab() != "grid" || opened != "etl" {
+			t.Fatalf("tab %q, grid opened for %q", s.ActiveTab(), opened)
+		}
+		kb.app.SetFocus(l.Grid())
+		kb.handle(back)
+		if s.ActiveTab() != "runs" {
+			t.Fatalf("%v in the grid left tab %q, want runs", back.Name(), s.ActiveTab())
+		}
+	}
+}
+
 // 'r' refreshes only on the monitor tab; elsewhere it reaches the widget.
 func TestRIsConditionallyConsumed(t *testing.T) {
 	kb, _, s := newKB(t)
@@ -240,3 +266,59 @@ func TestEscUnwindsDrillDown(t *testing.T) {
 		t.Error("Esc from DAG info should focus the DAG list")
 	}
 }
+
+// Read-only mode leaves mutating keys unbound so no callback can fire.
+func TestReadOnlyUnbindsMutatingKeys(t *testing.T) {
+	kb, _, s := newKB(t)
+	s.SelectDAG("etl")
+	s.SetActiveTab("runs")
+	var fired []string
+	kb.SetOnTrigger(func(string) { fired = append(fired, "trigger") })
+	kb.SetOnPause(func(string) { fired = append(fired, "pause") })
+	kb.SetOnBackfill(func(string) { fired = append(fired, "backfill") })
+	kb.SetReadOnly(true)
+
+	for _, r := range []rune{'t', 'p', 'b'} {
+		if kb.handle(key(tcell.KeyRune, r, tcell.ModNone)) == nil {
+			t.Errorf("%q was consumed in read-only mode", r)
+		}
+	}
+	if len(fired) > 0 {
+		t.Fatalf("read-only mode fired %v", fired)
+	}
+
+	kb.SetReadOnly(false)
+	kb.handle(key(tcell.KeyRune, 't', tcell.ModNone))
+	if len(fired) != 1 {
+		t.Fatalf("'t' did not trigger once read-only was off: %v", fired)
+	}
+}
+
+func TestActionHistoryUndoKey(t *testing.T) {
+	kb, l, s := newKB(t)
+	shown := 0
+	var reverted []int64
+	kb.SetOnShowActions(func() { shown++ })
+	kb.SetOnRevert(func(a cache.ActionRecord) { reverted = append(reverted, a.ID) })
+
+	s.SetActiveTab("runs")
+	if kb.handle(key(tcell.KeyRune, 'U', tcell.ModNone)) == nil {
+		t.Fatal("U outside the action history should fall through")
+	}
+
+	kb.handle(key(tcell.KeyRune, 'H', tcell.ModNone))
+	if s.ActiveTab() != "actions" || shown != 1 {
+		t.Fatalf("H: tab=%q shown=%d", s.ActiveTab(), shown)
+	}
+	l.Actions().Update([]cache.ActionRecord{{ID: 4, Action: cache.ActionPauseDAG, DagId: "etl"}})
+	l.Actions().Select(1, 0)
+	kb.handle(key(tcell.KeyRune, 'U', tcell.ModNone))
+	if len(reverted) != 1 || reverted[0] != 4 {
+		t.Fatalf("reverted = %v", reverted)
+	}
+
+	kb.SetReadOnly(true)
+	if kb.handle(key(tcell.KeyRune, 'U', tcell.ModNone)) == nil || len(reverted) != 1 {
+		t.Fatal("U must not undo in read-only mode")
+	}
+}
@@ -1,17 +1,25 @@
 package layout
 
 import (
+	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/yjinheon/lazyflow/internal/ui/theme"
+	"github.com/yjinheon/lazyflow/internal/ui/views"
 )
 
+// TriggerParams is what the trigger form submits. RunId and Note are optional;
+// Airflow generates a run id when RunId is empty.
 type TriggerParams struct {
 	LogicalDate string
+	RunId       string
 	Conf        string
+	Note        string
 }
 
 type BackfillParams struct {
@@ -21,7 +29,11 @@ type BackfillParams struct {
 	DagRunConf    string
 }
 
-func (m *MainLayout) ShowTriggerModal(dagId string, onSubmit func(TriggerParams)) {
+// ShowTriggerModal opens the trigger form. defaults pre-fills it (an empty
+// LogicalDate means now, an empty Conf means "{}"), which is how "re-trigger
+// with this conf" reuses the form. history holds previously submitted confs,
+// newest first; picking one replaces the Conf text.
+func (m *MainLayout) ShowTriggerModal(dagId string, defaults TriggerParams, history []string, onSubmit func(TriggerParams)) {
 	if dagId == "" {
 		return
 	}
@@ -31,15 +43,50 @@ func (m *MainLayout) ShowTriggerModal(dagId string, onSubmit func(TriggerParams)
 		SetTitle(fmt.Sprintf(" Trigger DAG: %s ", dagId)).
 		SetBorderColor(theme.ActiveTheme().BorderFocused)
 
-	now := time.Now().UTC().Format(time.RFC3339)
-	form.AddInputField("Logical Date", now, 40, nil, nil)
-	form.AddTextArea("Conf (JSON)", "{}", 40, 4, 0, nil)
+	logicalDate := defaults.LogicalDate
+	if logicalDate == "" {
+		logicalDate = time.Now().UTC().Format(time.RFC3339)
+	}
+	conf := defaults.Conf
+	if conf == "" {
+		conf = "{}"
+	}
+
+	form.AddInputField("Logical Date", logicalDate, 40, nil, nil)
+	form.AddInputField("Run ID", defaults.RunId, 40, nil, nil)
+	form.AddTextArea("Conf (JSON)", conf, 40, 4, 0, nil)
+	confArea := form.GetFormItemByLabel("Conf (JSON)").(*tview.TextArea)
+	if len(history) > 0 {
+		options := make([]string, 0, len(history)+1)
+		options = append(options, "(keep current)")
+		for _, h := range history {
+			options = append(options, truncateConf(h, 38))
+		}
+		dropdown := tview.NewDropDown().
+			SetLabel("Conf History").
+			SetOptions(options, func(_ string, index int) {
+				if index <= 0 || index > len(history) {
+					return
+				}
+				confArea.SetText(prettyJSON(history[index-1]), false)
+			}).
+			SetCurrentOption(0)
+		// Keep the picker directly above the text it fills.
+		form.RemoveFormItem(form.GetFormItemIndex("Conf (JSON)"))
+		form.AddFormItem(dropdown)
+		form.AddFormItem(confArea)
+	}
+	form.AddInputField("Note", defaults.Note, 40, nil, nil)
 
 	submit := func() {
-		logicalDate := form.GetFormItemByLabel("Logical Date").(*tview.InputField).GetText()
-		conf := form.GetFormItemByLabel("Conf (JSON)").(*tview.TextArea).GetText()
+		params := TriggerParams{
+			LogicalDate: form.GetFormItemByLabel("Logical Date").(*tview.InputField).GetText(),
+			RunId:       strings.TrimSpace(form.GetFormItemByLabel("Run ID").(*tview.InputField).GetText()),
+			Conf:        confArea.GetText(),
+			Note:        form.GetFormItemByLabel("Note").(*tview.InputField).GetText(),
+		}
 		m.dismissModal()
-		onSubmit(TriggerParams{LogicalDate: logicalDate, Conf: conf})
+		onSubmit(params)
 	}
 
 	form.AddButton("Trigger", submit)
@@ -52,7 +99,6 @@ func (m *MainLayout) ShowTriggerModal(dagId string, onSubmit func(TriggerParams)
 	})
 	form.SetFocus(0)
 	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		formItem, _ := form.GetFocusedItemIndex()
 		switch event.Key() {
 		case tcell.KeyEsc:
 			m.dismissModal()
@@ -61,7 +107,7 @@ func (m *MainLayout) ShowTriggerModal(dagId string, onSubmit func(TriggerParams)
 			submit()
 			return nil
 		case tcell.KeyEnter:
-			if formItem != 1 {
+			if !enterBelongsToItem(form) {
 				submit()
 				return nil
 			}
@@ -69,7 +115,80 @@ func (m *MainLayout) ShowTriggerModal(dagId string, onSubmit func(TriggerParams)
 		return event
 	})
 
-	m.showModal(form, 60, 14)
+	height := 18
+	if len(history) > 0 {
+		height += 2
+	}
+	m.showModal(form, 60, height)
+}
+
+// enterBelongsToItem reports whether the focused form item uses Enter itself:
+// a text area inserts a newline and a drop-down opens its list.
+func enterBelongsToItem(form *tview.Form) bool {
+	idx, _ := form.GetFocusedItemIndex()
+	if idx < 0 {
+		return false
+	}
+	switch form.GetFormItem(idx).(type) {
+	case *tview.TextArea, *tview.DropDown:
+		return true
+	}
+	return false
+}
+
+// truncateConf shortens a compact conf for a drop-down label.
+func truncateConf(conf string, n int) string {
+	r := []rune(conf)
+	if len(r) <= n {
+		return conf
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// prettyJSON indents a stored (compact) conf for editing; anything that does
+// not parse is returned unchanged.
+func prettyJSON(conf string) string {
+	var v any
+	if err := json.Unmarshal([]byte(conf), &v); err != nil {
+		return conf
+	}
+	return views.PrettyConf(v)
+}
+
+// ShowConfModal shows a run's conf as a collapsible JSON tree. Enter folds a
+// branch; T closes the tree and calls onRetrigger (nil disables it).
+func (m *MainLayout) ShowConfModal(runId string, conf any, onRetrigger func()) {
+	tree := tview.NewTreeView()
+	root := views.ConfTree(runId, conf)
+	tree.SetRoot(root).SetCurrentNode(root)
+	tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		node.SetExpanded(!node.IsExpanded())
+	})
+
+	hint := "[gray]Enter fold  ·  Esc close[-]"
+	if onRetrigger != nil {
+		hint = "[gray]Enter fold  ·  [yellow]T[-][gray] re-trigger with this conf  ·  Esc close[-]"
+	}
+	footer := tview.NewTextView().SetDynamicColors(true).SetText(hint)
+
+	box := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tree, 0, 1, true).
+		AddItem(footer, 1, 0, false)
+	box.SetBorder(true).
+		SetTitle(" Run Conf ").
+		SetBorderColor(theme.ActiveTheme().BorderFocused)
+
+	tree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() == 'T' && onRetrigger != nil {
+			m.dismissModal()
+			onRetrigger()
+			return nil
+		}
+		return event
+	})
+
+	m.showModal(box, 70, 20)
+	m.app.SetFocus(tree)
 }
 
 func (m *MainLayout) ShowBackfillModal(dagId string, onSubmit func(BackfillParams)) {
@@ -134,6 +253,138 @@ func (m *MainLayout) ShowBackfillModal(dagId string, onSubmit func(BackfillParam
 	m.showModal(form, 60, 18)
 }
 
+// ExportParams is what the history export form submits. DAGs is a
+// comma-separated list (empty for all cached DAGs); Since and Until accept
+// anything app.ParseTimeBound does.
+type ExportParams struct {
+	DAGs   string
+	Since  string
+	Until  string
+	Format string
+	Path   string
+}
+
+// ShowExportModal opens the history export form. formats are the choices
+// offered, and picking one swaps the extension of the path field.
+func (m *MainLayout) ShowExportModal(defaults ExportParams, formats []string, onSubmit func(ExportParams)) {
+	form := tview.NewForm()
+	form.SetBorder(true).
+		SetTitle(" Export History ").
+		SetBorderColor(theme.ActiveTheme().BorderFocused)
+
+	form.AddInputField("DAGs", defaults.DAGs, 40, nil, nil)
+	form.AddInputField("Since", defaults.Since, 40, nil, nil)
+	form.AddInputField("Until", defaults.Until, 40, nil, nil)
+	form.AddInputField("Path", defaults.Path, 40, nil, nil)
+	pathField := form.GetFormItemByLabel("Path").(*tview.InputField)
+
+	current := 0
+	for i, f := range formats {
+		if f == defaults.Format {
+			current = i
+		}
+	}
+	format := defaults.Format
+	dropdown := tview.NewDropDown().
+		SetLabel("Format").
+		SetOptions(formats, func(text string, _ int) {
+			if text == "" || text == format {
+				return
+			}
+			format = text
+			pathField.SetText(withExportExt(pathField.GetText(), text))
+		}).
+		SetCurrentOption(current)
+	form.RemoveFormItem(form.GetFormItemIndex("Path"))
+	form.AddFormItem(dropdown)
+	form.AddFormItem(pathField)
+
+	submit := func() {
+		params := ExportParams{
+			DAGs:   form.GetFormItemByLabel("DAGs").(*tview.InputField).GetText(),
+			Since:  form.GetFormItemByLabel("Since").(*tview.InputField).GetText(),
+			Until:  form.GetFormItemByLabel("Until").(*tview.InputField).GetText(),
+			Format: format,
+			Path:   strings.TrimSpace(pathField.GetText()),
+		}
+		m.dismissModal()
+		onSubmit(params)
+	}
+
+	form.AddButton("Export", submit)
+	form.AddButton("Cancel", func() {
+		m.dismissModal()
+	})
+	form.SetCancelFunc(func() {
+		m.dismissModal()
+	})
+	form.SetFocus(0)
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			m.dismissModal()
+			return nil
+		case tcell.KeyCtrlJ, tcell.KeyCtrlM:
+			submit()
+			return nil
+		case tcell.KeyEnter:
+			if !enterBelongsToItem(form) {
+				submit()
+				return nil
+			}
+		}
+		return event
+	})
+
+	m.showModal(form, 60, 17)
+}
+
+// withExportExt replaces path's extension with the one format writes; CSV
+// exports are a directory and carry none.
+func withExportExt(path, format string) string {
+	ext := map[string]string{"csv": "", "jsonl": ".jsonl", "sqlite": ".db"}[format]
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ext
+}
+
+// ShowNoteModal opens a multi-line editor for a run or task instance note.
+// Enter inserts a newline inside the text; Ctrl+J (or the Save button) saves.
+// Saving empty text clears the note.
+func (m *MainLayout) ShowNoteModal(title, note string, onSave func(string)) {
+	form := tview.NewForm()
+	form.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Note: %s ", title)).
+		SetBorderColor(theme.ActiveTheme().BorderFocused)
+
+	form.AddTextArea("Note", note, 56, 8, 0, nil)
+	area := form.GetFormItemByLabel("Note").(*tview.TextArea)
+
+	save := func() {
+		text := strings.TrimSpace(area.GetText())
+		m.dismissModal()
+		onSave(text)
+	}
+	form.AddButton("Save", save)
+	form.AddButton("Cancel", func() {
+		m.dismissModal()
+	})
+	form.SetCancelFunc(func() {
+		m.dismissModal()
+	})
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			m.dismissModal()
+			return nil
+		case tcell.KeyCtrlJ:
+			save()
+			return nil
+		}
+		return event
+	})
+
+	m.showModal(form, 72, 14)
+}
+
 func (m *MainLayout) ShowConfirmModal(title, message string, onConfirm func()) {
 	modal := tview.NewModal().
 		SetText(message).
@@ -161,6 +412,45 @@ func (m *MainLayout) ShowConfirmModal(title, message string, onConfirm func()) {
 	m.app.SetFocus(modal)
 }
 
+// ShowTypedConfirmModal guards a mutation on a protected cluster: onConfirm
+// runs only once the user has typed expect (the DAG id) exactly.
+func (m *MainLayout) ShowTypedConfirmModal(title, message, expect string, onConfirm func()) {
+	form := tview.NewForm()
+	prompt := tview.NewTextView().SetDynamicColors(true).SetWrap(true).
+		SetText(fmt.Sprintf("%s\n\nType [yellow]%s[-] to confirm.", message, tview.Escape(expect)))
+	form.AddInputField("DAG id", "", 40, nil, nil)
+	input := form.GetFormItemByLabel("DAG id").(*tview.InputField)
+
+	confirm := func() {
+		if input.GetText() != expect {
+			prompt.SetText(fmt.Sprintf("%s\n\n[red]Does not match.[-] Type [yellow]%s[-] to confirm.", message, tview.Escape(expect)))
+			return
+		}
+		m.dismissModal()
+		onConfirm()
+	}
+	input.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			confirm()
+		}
+	})
+	form.AddButton("Confirm", confirm)
+	form.AddButton("Cancel", func() {
+		m.dismissModal()
+	})
+	form.SetCancelFunc(func() {
+		m.dismissModal()
+	})
+
+	box := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(prompt, 4, 0, false).
+		AddItem(form, 0, 1, true)
+	box.SetBorder(true).SetTitle(title).SetBorderColor(theme.ActiveTheme().StatusFailed)
+
+	m.showModal(box, 64, 12)
+	m.app.SetFocus(input)
+}
+
 func (m *MainLayout) ShowNotification(message string) {
 	modal := tview.NewModal().
 		SetText(message).
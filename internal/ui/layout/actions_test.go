@@ -0,0 +1,35 @@
+package layout
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// On a protected cluster a mutation runs only once the DAG id is typed.
+func TestTypedConfirmRequiresExactDAGId(t *testing.T) {
+	app := tview.NewApplication()
+	m := NewMainLayout(app)
+	confirmed := 0
+	m.ShowTypedConfirmModal(" Protected cluster ", "Pause DAG", "etl_daily", func() { confirmed++ })
+
+	input, ok := app.GetFocus().(*tview.InputField)
+	if !ok {
+		t.Fatalf("focus on %T, want the DAG id input", app.GetFocus())
+	}
+	enter := func() {
+		input.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(tview.Primitive) {})
+	}
+
+	input.SetText("etl")
+	enter()
+	if confirmed != 0 || !m.IsModalVisible() {
+		t.Fatal("a partial DAG id confirmed the action")
+	}
+	input.SetText("etl_daily")
+	enter()
+	if confirmed != 1 || m.IsModalVisible() {
+		t.Fatalf("exact DAG id: confirmed=%d modal=%v", confirmed, m.IsModalVisible())
+	}
+}
@@ -3,16 +3,28 @@ package layout
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/yjinheon/lazyflow/internal/ui/theme"
+	"github.com/yjinheon/lazyflow/internal/ui/views"
 )
 
 // ---------- Header ----------
 
 type Header struct {
 	*tview.TextView
+
+	host         string
+	connected    bool
+	dagCount     int
+	offline      bool
+	offlineSince time.Time
+	health       string // state.Conn* value; "" until the first API result
+	lastSuccess  time.Time
+	readOnly     bool
+	protected    bool
 }
 
 func NewHeader() *Header {
@@ -29,15 +41,69 @@ func (h *Header) SetConnection(host string, ok bool) {
 }
 
 func (h *Header) SetInfo(host string, connected bool, dagCount int) {
-	status := fmt.Sprintf("[green]%s[-]", host)
-	if !connected {
-		status = fmt.Sprintf("[red]%s (disconnected)[-]", host)
+	h.host, h.connected, h.dagCount = host, connected, dagCount
+	h.render()
+}
+
+// SetOffline switches the header into the offline banner: data on screen
+// comes from the history cache and actions are disabled until the API
+// answers again.
+func (h *Header) SetOffline(offline bool, since time.Time) {
+	h.offline, h.offlineSince = offline, since
+	h.render()
+}
+
+// SetHealth shows the derived connection state and how long ago any API call
+// last succeeded. Call it again periodically to keep the age current.
+func (h *Header) SetHealth(health string, lastSuccess time.Time) {
+	h.health, h.lastSuccess = health, lastSuccess
+	h.render()
+}
+
+// SetSafety badges the header for read-only mode, and recolours it for a
+// protected cluster so production is never mistaken for staging.
+func (h *Header) SetSafety(readOnly, protected bool) {
+	h.readOnly, h.protected = readOnly, protected
+	bg := theme.ActiveTheme().PrimaryBg
+	if protected {
+		bg = theme.ActiveTheme().ProtectedBg
+	}
+	h.SetBackgroundColor(bg)
+	h.render()
+}
+
+func (h *Header) render() {
+	status := fmt.Sprintf("[green]%s[-]", h.host)
+	switch {
+	case h.health == "auth_failed":
+		status = fmt.Sprintf("[black:red:b] AUTH FAILED [-:-:-] [red]%s[-] [gray]check credentials[-]", h.host)
+		if !h.lastSuccess.IsZero() {
+			status += fmt.Sprintf(" [gray]· last ok %s[-]", views.Ago(h.lastSuccess))
+		}
+	case h.offline:
+		status = fmt.Sprintf("[black:red:b] OFFLINE [-:-:-] [red]%s[-] [gray]cached data since %s · read-only[-]",
+			h.host, h.offlineSince.Local().Format("15:04:05"))
+	case h.health == "disconnected" || !h.connected:
+		status = fmt.Sprintf("[red]%s (disconnected)[-]", h.host)
+	case h.health == "degraded":
+		status = fmt.Sprintf("[yellow]%s (degraded)[-] [gray]updated %s[-]", h.host, views.Ago(h.lastSuccess))
+	case h.health == "connecting":
+		status = fmt.Sprintf("[gray]%s (connecting…)[-]", h.host)
+	case !h.lastSuccess.IsZero():
+		status += fmt.Sprintf(" [gray]updated %s[-]", views.Ago(h.lastSuccess))
 	}
 	extra := ""
-	if dagCount > 0 {
-		extra = fmt.Sprintf(" | DAGs: [yellow]%d[-]", dagCount)
+	if h.dagCount > 0 {
+		extra = fmt.Sprintf(" | DAGs: [yellow]%d[-]", h.dagCount)
+	}
+	badges := ""
+	if h.protected {
+		badges += "[white:red:b] PROTECTED [-:-:-] "
 	}
-	h.SetText(fmt.Sprintf(" [::b]lazyflow[::-] v0.1.0 | %s%s | [gray]?[-]:Help [gray]/[-]:Search", status, extra))
+	if h.readOnly {
+		badges += "[black:yellow:b] READ-ONLY [-:-:-] "
+	}
+	h.SetText(fmt.Sprintf(" [::b]lazyflow[::-] v0.1.0 | %s%s%s | [gray]?[-]:Help [gray]/[-]:Search", badges, status, extra))
 }
 
 func (h *Header) Root() *tview.TextView {
@@ -51,6 +117,7 @@ func (h *Header) Root() *tview.TextView {
 //   - all: every DAG
 //   - active/inactive: paused vs unpaused DAGs
 //   - running/success/failed: DAGs bucketed by their latest run's state
+//   - sla: DAGs with a breached SLA expectation
 type KpiBar struct {
 	root       *tview.Flex
 	cards      map[string]*tview.TextView
@@ -64,6 +131,7 @@ type KpiBar struct {
 	runningDAGs  int
 	successDAGs  int
 	failedDAGs   int
+	slaDAGs      int
 }
 
 func NewKpiBar() *KpiBar {
@@ -79,6 +147,7 @@ func NewKpiBar() *KpiBar {
 	k.addCard("running", "Running", theme.ActiveTheme().StatusRunning)
 	k.addCard("success", "Success", theme.ActiveTheme().StatusSuccess)
 	k.addCard("failed", "Failed", theme.ActiveTheme().StatusFailed)
+	k.addCard("sla", "SLA", theme.ActiveTheme().StatusFailed)
 	k.refresh()
 	return k
 }
@@ -161,6 +230,12 @@ func (k *KpiBar) SetDAGStateCounts(running, success, failed int) {
 	k.refresh()
 }
 
+// SetSLABreachCount sets how many DAGs currently breach an SLA.
+func (k *KpiBar) SetSLABreachCount(n int) {
+	k.slaDAGs = n
+	k.refresh()
+}
+
 func (k *KpiBar) refresh() {
 	k.setCard("all", k.activeDAGs+k.inactiveDAGs, "white")
 	k.setCard("active", k.activeDAGs, "green")
@@ -168,6 +243,11 @@ func (k *KpiBar) refresh() {
 	k.setCard("running", k.runningDAGs, "blue")
 	k.setCard("success", k.successDAGs, "green")
 	k.setCard("failed", k.failedDAGs, "red")
+	slaColor := "gray"
+	if k.slaDAGs > 0 {
+		slaColor = "red"
+	}
+	k.setCard("sla", k.slaDAGs, slaColor)
 	for key, card := range k.cards {
 		title := fmt.Sprintf(" %s ", k.titles[key])
 		if key == k.active {
@@ -273,6 +353,10 @@ type StatusBar struct {
 	flash                string // transient status/error; outranks the selection info
 	tab                  string
 	hasDAG               bool
+	health               string // state.Conn* value
+	lastSuccess          time.Time
+	failing              int
+	readOnly             bool // hide the keys of mutating actions
 }
 
 func NewStatusBar() *StatusBar {
@@ -292,7 +376,14 @@ func (s *StatusBar) Draw(screen tcell.Screen) {
 const statusSep = "  │  "
 
 func (s *StatusBar) compose(width int) string {
-	hints, hintsW := buildHints(s.tab, s.hasDAG)
+	hints, hintsW := buildHints(s.tab, s.hasDAG, s.readOnly)
+
+	// Connection trouble sits next to the hints so a flash cannot hide it.
+	health := s.healthSegment()
+	if health != "" {
+		hintsW += tview.TaggedStringWidth(health) + len(statusSep)
+		hints = health + statusSep + hints
+	}
 
 	// A flash carries caller-supplied markup of unknown width; let it clip.
 	left := s.flash
@@ -302,6 +393,23 @@ func (s *StatusBar) compose(width int) string {
 	return " " + left + statusSep + hints
 }
 
+// healthSegment summarises a non-healthy connection; empty while connected.
+func (s *StatusBar) healthSegment() string {
+	switch s.health {
+	case "auth_failed":
+		return "[red]auth failed[-] [yellow]![-][gray]:errors[-]"
+	case "disconnected":
+		return fmt.Sprintf("[red]disconnected · last ok %s[-]", views.Ago(s.lastSuccess))
+	case "degraded":
+		noun := "endpoints"
+		if s.failing == 1 {
+			noun = "endpoint"
+		}
+		return fmt.Sprintf("[yellow]degraded: %d %s failing · last ok %s[-]", s.failing, noun, views.Ago(s.lastSuccess))
+	}
+	return ""
+}
+
 // infoSegment renders as much selection context as budget allows, shrinking the
 // long, low-signal run id before dropping anything.
 func (s *StatusBar) infoSegment(budget int) string {
@@ -379,6 +487,16 @@ func (s *StatusBar) SetInfo(dagId, runId, taskId string) {
 	s.flash = "" // a new selection supersedes the previous action result
 }
 
+// SetHealth records the connection state shown beside the key hints.
+func (s *StatusBar) SetHealth(health string, lastSuccess time.Time, failing int) {
+	s.health, s.lastSuccess, s.failing = health, lastSuccess, failing
+}
+
+// SetReadOnly drops the hints of actions that change Airflow state.
+func (s *StatusBar) SetReadOnly(on bool) {
+	s.readOnly = on
+}
+
 // SetContext refreshes the key hints for the active tab and selection state.
 func (s *StatusBar) SetContext(tab string, hasDAG bool) {
 	s.tab, s.hasDAG = tab, hasDAG
@@ -386,16 +504,36 @@ func (s *StatusBar) SetContext(tab string, hasDAG bool) {
 
 // buildHints lists the keys that actually do something right now, returning the
 // markup and its visible width. Keep it short: it shares one line with the info.
-func buildHints(tab string, hasDAG bool) (string, int) {
+func buildHints(tab string, hasDAG, readOnly bool) (string, int) {
 	var keys [][2]string // key, label
-	if hasDAG {
+	if hasDAG && !readOnly {
 		keys = append(keys, [2]string{"t", "trigger"}, [2]string{"p", "pause"}, [2]string{"b", "backfill"})
 	}
 	switch tab {
+	case "runs":
+		if hasDAG {
+			keys = append(keys, [2]string{"v", "conf"})
+			if !readOnly {
+				keys = append(keys, [2]string{"T", "re-trigger"}, [2]string{"n", "note"})
+			}
+			keys = append(keys, [2]string{"m", "mark"}, [2]string{"C", "compare"}, [2]string{"g", "grid"})
+		}
+	case "compare":
+		keys = append(keys, [2]string{"Esc", "back to runs"})
+	case "grid":
+		keys = append(keys, [2]string{"Enter", "logs"}, [2]string{"Esc", "back to runs"})
+	case "sla":
+		keys = append(keys, [2]string{"Enter", "open DAG runs"})
+	case "actions":
+		if !readOnly {
+			keys = append(keys, [2]string{"U", "undo"})
+		}
 	case "backfills":
-		keys = append(keys, [2]string{"c", "cancel"}, [2]string{"u", "unpause"})
+		if !readOnly {
+			keys = append(keys, [2]string{"c", "cancel"}, [2]string{"u", "unpause"})
+		}
 	case "monitor":
-		keys = append(keys, [2]string{"[", "prev"}, [2]string{"]", "next"}, [2]string{"r", "refresh"})
+		keys = append(keys, [2]string{"[", "prev"}, [2]string{"]", "next"}, [2]string{"r", "refresh"}, [2]string{"F", "fleet"})
 	case "tasks":
 		keys = append(keys, [2]string{"g", "gantt"})
 	case "lineage":
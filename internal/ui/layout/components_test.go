@@ -3,12 +3,16 @@ package layout
 import (
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/yjinheon/lazyflow/internal/ui/theme"
 )
 
 func TestKpiBarDAGStateCounts(t *testing.T) {
 	k := NewKpiBar()
 	k.SetDAGCounts(12, 3)
 	k.SetDAGStateCounts(2, 9, 1)
+	k.SetSLABreachCount(4)
 
 	cases := []struct {
 		key  string
@@ -20,6 +24,7 @@ func TestKpiBarDAGStateCounts(t *testing.T) {
 		{"running", "2"},
 		{"success", "9"},
 		{"failed", "1"},
+		{"sla", "4"},
 	}
 	for _, c := range cases {
 		card, ok := k.cards[c.key]
@@ -51,3 +56,57 @@ func TestKpiBarSelectsDAGFilter(t *testing.T) {
 		t.Fatalf("next selection = callback %q, active %q; want running", selected, k.ActiveFilter())
 	}
 }
+
+func TestHeaderOfflineBanner(t *testing.T) {
+	h := NewHeader()
+	h.SetInfo("http://airflow:8080", true, 7)
+	h.SetOffline(true, time.Date(2026, 1, 2, 9, 30, 0, 0, time.Local))
+	got := h.GetText(true)
+	for _, want := range []string{"OFFLINE", "09:30:00", "read-only", "DAGs: 7"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("offline header %q missing %q", got, want)
+		}
+	}
+	h.SetOffline(false, time.Time{})
+	if got := h.GetText(true); strings.Contains(got, "OFFLINE") {
+		t.Errorf("online header still shows the banner: %q", got)
+	}
+}
+
+func TestHeaderHealthStates(t *testing.T) {
+	h := NewHeader()
+	h.SetInfo("http://airflow:8080", true, 0)
+	for _, c := range []struct {
+		health string
+		want   string
+	}{
+		{"connecting", "connecting"},
+		{"connected", "updated 3s ago"},
+		{"degraded", "(degraded) updated 3s ago"},
+		{"auth_failed", "AUTH FAILED"},
+	} {
+		h.SetHealth(c.health, time.Now().Add(-3*time.Second))
+		if got := h.GetText(true); !strings.Contains(got, c.want) {
+			t.Errorf("%s header %q missing %q", c.health, got, c.want)
+		}
+	}
+}
+
+func TestHeaderSafetyBadges(t *testing.T) {
+	h := NewHeader()
+	h.SetInfo("https://airflow.prod", true, 0)
+	h.SetSafety(true, true)
+	got := h.GetText(true)
+	for _, want := range []string{"PROTECTED", "READ-ONLY", "airflow.prod"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("header %q missing %q", got, want)
+		}
+	}
+	if h.GetBackgroundColor() != theme.ActiveTheme().ProtectedBg {
+		t.Error("protected header keeps the normal background")
+	}
+	h.SetSafety(false, false)
+	if got := h.GetText(true); strings.Contains(got, "PROTECTED") || strings.Contains(got, "READ-ONLY") {
+		t.Errorf("badges left after clearing: %q", got)
+	}
+}
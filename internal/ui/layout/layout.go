@@ -45,6 +45,11 @@ type MainLayout struct {
 	lineageView     *views.LineageView
 	backfillsView   *views.BackfillsView
 	helpView        *views.HelpView
+	compareView     *views.CompareView
+	gridView        *views.GridView
+	slaView         *views.SLAView
+	apiErrorsView   *views.APIErrorsView
+	actionsView     *views.ActionHistoryView
 	modalOpen       bool
 	searchOpen      bool
 
@@ -73,6 +78,11 @@ func NewMainLayout(app *tview.Application) *MainLayout {
 		lineageView:     views.NewLineageView(),
 		backfillsView:   views.NewBackfillsView(),
 		helpView:        views.NewHelpView(),
+		compareView:     views.NewCompareView(),
+		gridView:        views.NewGridView(),
+		slaView:         views.NewSLAView(),
+		apiErrorsView:   views.NewAPIErrorsView(),
+		actionsView:     views.NewActionHistoryView(),
 
 		tabContent: tview.NewPages(),
 	}
@@ -109,6 +119,11 @@ func (m *MainLayout) registerTabs() {
 	m.tabContent.AddPage("variables", m.variablesView.Root(), true, false)
 	m.tabContent.AddPage("config", m.configView.Root(), true, false)
 	m.tabContent.AddPage("help", m.helpView.Root(), true, false)
+	m.tabContent.AddPage("compare", m.compareView.Root(), true, false)
+	m.tabContent.AddPage("grid", m.gridView.Root(), true, false)
+	m.tabContent.AddPage("sla", m.slaView.Root(), true, false)
+	m.tabContent.AddPage("errors", m.apiErrorsView.Root(), true, false)
+	m.tabContent.AddPage("actions", m.actionsView.Root(), true, false)
 }
 
 func (m *MainLayout) SwitchTab(name string) {
@@ -116,6 +131,49 @@ func (m *MainLayout) SwitchTab(name string) {
 	m.tabBar.SetActive(name)
 }
 
+// ShowCompare brings up the run comparison. It has no tab of its own: it is a
+// drill-down from Runs, so the Runs tab stays highlighted.
+func (m *MainLayout) ShowCompare() {
+	m.tabContent.SwitchToPage("compare")
+	m.tabBar.SetActive("runs")
+	m.app.SetFocus(m.compareView.Table())
+}
+
+// ShowGrid brings up the tasks × runs grid, like compare a drill-down from Runs.
+func (m *MainLayout) ShowGrid() {
+	m.tabContent.SwitchToPage("grid")
+	m.tabBar.SetActive("runs")
+	m.app.SetFocus(m.gridView)
+}
+
+// ShowSLA brings up the SLA breach list. Like help it has no tab of its own.
+func (m *MainLayout) ShowSLA() {
+	m.SwitchTab("sla")
+	m.app.SetFocus(m.slaView)
+}
+
+// ShowAPIErrors brings up endpoint health and the recent API errors. Like
+// SLA it has no tab of its own.
+func (m *MainLayout) ShowAPIErrors() {
+	m.SwitchTab("errors")
+	m.app.SetFocus(m.apiErrorsView.Table())
+}
+
+// ShowActions brings up the audit trail of actions taken from this machine.
+// Like SLA it has no tab of its own.
+func (m *MainLayout) ShowActions() {
+	m.SwitchTab("actions")
+	m.app.SetFocus(m.actionsView)
+}
+
+// SetSafety applies read-only mode and the protected-cluster marking to the
+// header, status bar hints and keymap.
+func (m *MainLayout) SetSafety(readOnly, protected bool) {
+	m.header.SetSafety(readOnly, protected)
+	m.statusBar.SetReadOnly(readOnly)
+	m.helpView.SetReadOnly(readOnly)
+}
+
 // ShowHelp displays a help modal with keybinding reference.
 func (m *MainLayout) ShowHelp() {
 	m.SwitchTab("help")
@@ -199,6 +257,16 @@ func (m *MainLayout) ActiveTabPrimitive() tview.Primitive {
 		return m.lineageView
 	case "backfills":
 		return m.backfillsView.List()
+	case "compare":
+		return m.compareView.Table()
+	case "grid":
+		return m.gridView
+	case "sla":
+		return m.slaView
+	case "errors":
+		return m.apiErrorsView.Table()
+	case "actions":
+		return m.actionsView
 	default:
 		return m.runsView
 	}
@@ -219,6 +287,11 @@ func (m *MainLayout) Monitor() *views.MonitorView         { return m.monitorView
 func (m *MainLayout) Lineage() *views.LineageView         { return m.lineageView }
 func (m *MainLayout) Backfills() *views.BackfillsView     { return m.backfillsView }
 func (m *MainLayout) Help() *views.HelpView               { return m.helpView }
+func (m *MainLayout) Compare() *views.CompareView         { return m.compareView }
+func (m *MainLayout) Grid() *views.GridView               { return m.gridView }
+func (m *MainLayout) SLA() *views.SLAView                 { return m.slaView }
+func (m *MainLayout) APIErrors() *views.APIErrorsView     { return m.apiErrorsView }
+func (m *MainLayout) Actions() *views.ActionHistoryView   { return m.actionsView }
 func (m *MainLayout) Execution() *views.ExecutionView     { return m.tasksView.Run() }
 func (m *MainLayout) StatusBar() *StatusBar               { return m.statusBar }
 func (m *MainLayout) Header() *Header                     { return m.header }
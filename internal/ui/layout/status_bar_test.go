@@ -3,6 +3,7 @@ package layout
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 )
@@ -152,3 +153,46 @@ func TestStatusBarHintsAreContextual(t *testing.T) {
 		t.Errorf("tasks hint missing\n  got=%q", got)
 	}
 }
+
+// Connection trouble stays visible beside the hints, even over a flash.
+func TestStatusBarShowsConnectionHealth(t *testing.T) {
+	s := NewStatusBar()
+	s.SetContext("runs", true)
+	s.SetInfo("etl", "", "")
+	s.SetHealth("connected", time.Now(), 0)
+	if got := renderBar(t, s, 160); strings.Contains(got, "degraded") || strings.Contains(got, "last ok") {
+		t.Errorf("healthy connection should not take space\n  got=%q", got)
+	}
+
+	s.SetHealth("degraded", time.Now().Add(-12*time.Second), 1)
+	s.SetError("boom")
+	got := renderBar(t, s, 160)
+	for _, want := range []string{"degraded: 1 endpoint failing", "last ok 12s ago", "Error: boom", "t:trigger"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q\n  got=%q", want, got)
+		}
+	}
+
+	s.SetHealth("auth_failed", time.Time{}, 3)
+	if got := renderBar(t, s, 160); !strings.Contains(got, "auth failed !:errors") {
+		t.Errorf("auth failure not shown\n  got=%q", got)
+	}
+}
+
+// Read-only mode offers no keys for actions that would be refused.
+func TestStatusBarReadOnlyHidesMutations(t *testing.T) {
+	s := NewStatusBar()
+	s.SetReadOnly(true)
+	s.SetContext("runs", true)
+	got := renderBar(t, s, 160)
+	for _, hidden := range []string{"t:trigger", "p:pause", "b:backfill", "T:re-trigger", "n:note"} {
+		if strings.Contains(got, hidden) {
+			t.Errorf("read-only bar offers %q\n  got=%q", hidden, got)
+		}
+	}
+	for _, want := range []string{"v:conf", "C:compare"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("read-only bar lost %q\n  got=%q", want, got)
+		}
+	}
+}
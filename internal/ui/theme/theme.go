@@ -44,6 +44,9 @@ type Theme struct {
 	// (help sections, config section names, connection types).
 	SectionHeader tcell.Color
 
+	// ProtectedBg fills the header while connected to a protected cluster.
+	ProtectedBg tcell.Color
+
 	// SyntaxStyle names the chroma style used for DAG source highlighting.
 	SyntaxStyle string
 }
@@ -82,6 +85,8 @@ var TokyoNightStorm = Theme{
 
 	SectionHeader: hex(0x7dcfff), // cyan
 
+	ProtectedBg: hex(0x4a1f2a), // dark red
+
 	SyntaxStyle: "tokyonight-storm",
 }
 
@@ -189,6 +194,8 @@ func GanttMarkupColor(token string) string {
 		return colorHex(t.StatusUpstream)
 	case "critical":
 		return colorHex(t.CriticalPath)
+	case "ghost":
+		return colorHex(t.MutedText)
 	default:
 		return colorHex(t.PrimaryText)
 	}
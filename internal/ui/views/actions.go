@@ -0,0 +1,103 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/yjinheon/lazyflow/internal/cache"
+	"github.com/yjinheon/lazyflow/internal/ui/theme"
+)
+
+// ActionHistoryView lists the state-changing actions taken from this machine,
+// newest first, from the audit trail in the history cache. U on a row undoes
+// a reversible action.
+type ActionHistoryView struct {
+	*tview.Table
+	actions  []cache.ActionRecord
+	reverted map[int64]bool
+}
+
+func NewActionHistoryView() *ActionHistoryView {
+	v := &ActionHistoryView{Table: tview.NewTable()}
+	v.SetBorder(true).SetTitle(" Action History ")
+	v.SetFixed(1, 0)
+	v.SetSelectedStyle(tcell.StyleDefault.
+		Background(theme.ActiveTheme().TableSelected).
+		Foreground(theme.ActiveTheme().PrimaryText).
+		Attributes(tcell.AttrBold))
+	v.SetFocusFunc(func() { v.SetBorderColor(theme.ActiveTheme().BorderFocused) })
+	v.SetBlurFunc(func() { v.SetBorderColor(theme.ActiveTheme().BorderColor) })
+	v.Update(nil)
+	return v
+}
+
+// Update replaces the list. actions is expected newest first.
+func (v *ActionHistoryView) Update(actions []cache.ActionRecord) {
+	v.actions = append([]cache.ActionRecord(nil), actions...)
+	v.reverted = cache.RevertedIDs(v.actions)
+	v.render()
+}
+
+func (v *ActionHistoryView) render() {
+	t := theme.ActiveTheme()
+	v.Clear()
+	setHeaderRow(v.Table, "Time", "User", "Action", "DAG", "Target", "Result", "Request")
+	if len(v.actions) == 0 {
+		// keep table non-selectable while empty (see RunsView.setup)
+		v.SetSelectable(false, false)
+		setEmptyHint(v.Table, "No actions recorded yet.")
+		return
+	}
+	v.SetSelectable(true, false)
+	for i, a := range v.actions {
+		row := i + 1
+		bg := t.PrimaryBg
+		if row%2 == 0 {
+			bg = t.TableRowAlt
+		}
+		action := a.Action
+		if a.RevertOf != 0 {
+			action = fmt.Sprintf("%s (undo #%d)", a.Action, a.RevertOf)
+		}
+		result, resultColor := "ok", t.StatusSuccess
+		switch {
+		case a.Error != "":
+			result, resultColor = a.Error, t.StatusFailed
+		case v.reverted[a.ID]:
+			result, resultColor = "reverted", t.MutedText
+		}
+		cells := []struct {
+			text  string
+			color tcell.Color
+		}{
+			{a.At.Local().Format("01-02 15:04:05"), t.MutedText},
+			{a.User, t.SecondaryText},
+			{action, t.PrimaryText},
+			{a.DagId, t.PrimaryText},
+			{a.Target, t.SecondaryText},
+			{result, resultColor},
+			{a.Request, t.MutedText},
+		}
+		for c, cell := range cells {
+			v.SetCell(row, c, tview.NewTableCell(tview.Escape(cell.text)).
+				SetTextColor(cell.color).SetBackgroundColor(bg).SetMaxWidth(60))
+		}
+	}
+}
+
+// CurrentAction returns the record under the cursor.
+func (v *ActionHistoryView) CurrentAction() (cache.ActionRecord, bool) {
+	row, _ := v.GetSelection()
+	if row < 1 || row > len(v.actions) {
+		return cache.ActionRecord{}, false
+	}
+	return v.actions[row-1], true
+}
+
+// Reverted reports whether a later action already undid the one with id.
+func (v *ActionHistoryView) Reverted(id int64) bool { return v.reverted[id] }
+
+func (v *ActionHistoryView) Root() *tview.Table {
+	return v.Table
+}
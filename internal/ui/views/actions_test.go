@@ -0,0 +1,46 @@
+package views
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yjinheon/lazyflow/internal/cache"
+)
+
+func TestActionHistoryView(t *testing.T) {
+	v := NewActionHistoryView()
+	if got := v.GetCell(1, 0).Text; got != "No actions recorded yet." {
+		t.Fatalf("empty hint = %q", got)
+	}
+	if _, ok := v.CurrentAction(); ok {
+		t.Fatal("no action should be current while empty")
+	}
+
+	now := time.Now()
+	v.Update([]cache.ActionRecord{
+		{ID: 3, At: now, User: "alice", Action: cache.ActionUnpauseDAG, DagId: "etl", RevertOf: 1},
+		{ID: 2, At: now, User: "alice", Action: cache.ActionTrigger, DagId: "etl", Request: `{"conf":{}}`, Error: "api error 409 [exists]"},
+		{ID: 1, At: now, User: "alice", Action: cache.ActionPauseDAG, DagId: "etl"},
+	})
+
+	if got := v.GetCell(1, 2).Text; got != "unpause (undo #1)" {
+		t.Errorf("revert action = %q", got)
+	}
+	if got := v.GetCell(1, 5).Text; got != "ok" {
+		t.Errorf("revert result = %q", got)
+	}
+	if got := v.GetCell(2, 5).Text; got != "api error 409 [exists[]" {
+		t.Errorf("error should be escaped, got %q", got)
+	}
+	if got := v.GetCell(3, 5).Text; got != "reverted" {
+		t.Errorf("undone action result = %q", got)
+	}
+	if !v.Reverted(1) || v.Reverted(3) {
+		t.Error("only #1 was reverted")
+	}
+
+	v.Select(3, 0)
+	if a, ok := v.CurrentAction(); !ok || a.ID != 1 {
+		t.Fatalf("current = %+v, %v", a, ok)
+	}
+}
@@ -0,0 +1,139 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/yjinheon/lazyflow/internal/state"
+	"github.com/yjinheon/lazyflow/internal/ui/theme"
+)
+
+// maxEndpointRows caps the summary table so the error list keeps most of
+// the panel.
+const maxEndpointRows = 8
+
+// APIErrorsView shows per-endpoint health on top and the most recent API
+// errors below, newest first.
+type APIErrorsView struct {
+	*tview.Flex
+	endpoints *tview.Table
+	errors    *tview.Table
+}
+
+func NewAPIErrorsView() *APIErrorsView {
+	v := &APIErrorsView{
+		Flex:      tview.NewFlex().SetDirection(tview.FlexRow),
+		endpoints: tview.NewTable(),
+		errors:    tview.NewTable(),
+	}
+	v.endpoints.SetBorder(true).SetTitle(" API Endpoints ")
+	v.endpoints.SetSelectable(false, false).SetFixed(1, 0)
+
+	v.errors.SetBorder(true).SetTitle(" Recent API Errors ")
+	v.errors.SetFixed(1, 0)
+	v.errors.SetSelectedStyle(tcell.StyleDefault.
+		Background(theme.ActiveTheme().TableSelected).
+		Foreground(theme.ActiveTheme().PrimaryText).
+		Attributes(tcell.AttrBold))
+	v.errors.SetFocusFunc(func() { v.errors.SetBorderColor(theme.ActiveTheme().BorderFocused) })
+	v.errors.SetBlurFunc(func() { v.errors.SetBorderColor(theme.ActiveTheme().BorderColor) })
+
+	v.Update(nil, nil)
+	return v
+}
+
+// Update replaces both tables. errs is expected newest first.
+func (v *APIErrorsView) Update(endpoints []state.EndpointHealth, errs []state.APIError) {
+	v.renderEndpoints(endpoints)
+	v.renderErrors(errs)
+
+	rows := len(endpoints)
+	if rows == 0 {
+		rows = 1 // the empty hint
+	}
+	if rows > maxEndpointRows {
+		rows = maxEndpointRows
+	}
+	v.Clear()
+	v.AddItem(v.endpoints, rows+3, 0, false) // header row + borders
+	v.AddItem(v.errors, 0, 1, true)
+}
+
+func (v *APIErrorsView) renderEndpoints(endpoints []state.EndpointHealth) {
+	t := theme.ActiveTheme()
+	v.endpoints.Clear()
+	setHeaderRow(v.endpoints, "Endpoint", "Status", "Last success", "Last error", "Failures")
+	if len(endpoints) == 0 {
+		setEmptyHint(v.endpoints, "No API calls yet.")
+		return
+	}
+	for i, h := range endpoints {
+		row := i + 1
+		status, color := "ok", t.StatusSuccess
+		if h.Failures > 0 {
+			status, color = h.Kind, t.StatusFailed
+		}
+		failures := "-"
+		if h.Failures > 0 {
+			failures = fmt.Sprintf("%d", h.Failures)
+		}
+		v.endpoints.SetCell(row, 0, tview.NewTableCell(h.Endpoint).SetTextColor(t.PrimaryText))
+		v.endpoints.SetCell(row, 1, tview.NewTableCell(status).SetTextColor(color))
+		v.endpoints.SetCell(row, 2, tview.NewTableCell(Ago(h.LastSuccess)).SetTextColor(t.MutedText))
+		v.endpoints.SetCell(row, 3, tview.NewTableCell(Ago(h.LastError)).SetTextColor(t.MutedText))
+		v.endpoints.SetCell(row, 4, tview.NewTableCell(failures).SetTextColor(t.PrimaryText).SetExpansion(1))
+	}
+}
+
+func (v *APIErrorsView) renderErrors(errs []state.APIError) {
+	t := theme.ActiveTheme()
+	v.errors.Clear()
+	setHeaderRow(v.errors, "Time", "Endpoint", "Kind", "Error")
+	if len(errs) == 0 {
+		// keep table non-selectable while empty (see RunsView.setup)
+		v.errors.SetSelectable(false, false)
+		setEmptyHint(v.errors, "No API errors.")
+		return
+	}
+	v.errors.SetSelectable(true, false)
+	for i, e := range errs {
+		row := i + 1
+		bg := t.PrimaryBg
+		if row%2 == 0 {
+			bg = t.TableRowAlt
+		}
+		cells := []struct {
+			text  string
+			color tcell.Color
+		}{
+			{e.At.Local().Format("15:04:05"), t.MutedText},
+			{e.Endpoint, t.PrimaryText},
+			{e.Kind, t.StatusFailed},
+			{e.Message, t.PrimaryText},
+		}
+		for c, cell := range cells {
+			v.errors.SetCell(row, c, tview.NewTableCell(tview.Escape(cell.text)).
+				SetTextColor(cell.color).SetBackgroundColor(bg))
+		}
+	}
+}
+
+func setHeaderRow(table *tview.Table, headers ...string) {
+	for i, h := range headers {
+		cell := tview.NewTableCell(h).
+			SetTextColor(theme.ActiveTheme().TableHeaderText).
+			SetSelectable(false)
+		if i == len(headers)-1 {
+			cell.SetExpansion(1)
+		}
+		table.SetCell(0, i, cell)
+	}
+}
+
+// Table is the focusable error list.
+func (v *APIErrorsView) Table() *tview.Table { return v.errors }
+
+func (v *APIErrorsView) Root() *tview.Flex {
+	return v.Flex
+}
@@ -0,0 +1,36 @@
+package views
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yjinheon/lazyflow/internal/state"
+)
+
+func TestAPIErrorsView(t *testing.T) {
+	v := NewAPIErrorsView()
+	if got := v.Table().GetCell(1, 0).Text; got != "No API errors." {
+		t.Fatalf("empty hint = %q", got)
+	}
+
+	now := time.Now()
+	v.Update([]state.EndpointHealth{
+		{Endpoint: "dags", LastSuccess: now},
+		{Endpoint: "pools", LastSuccess: now.Add(-time.Minute), LastError: now, Err: "boom", Kind: state.APIErrOther, Failures: 2},
+	}, []state.APIError{
+		{At: now, Endpoint: "pools", Kind: state.APIErrOther, Message: "api error 500 [oops]"},
+	})
+
+	if got := v.endpoints.GetCell(1, 1).Text; got != "ok" {
+		t.Errorf("healthy endpoint status = %q", got)
+	}
+	if got := v.endpoints.GetCell(2, 4).Text; got != "2" {
+		t.Errorf("failures = %q", got)
+	}
+	if got := v.Table().GetCell(1, 1).Text; got != "pools" {
+		t.Errorf("error endpoint = %q", got)
+	}
+	if got := v.Table().GetCell(1, 3).Text; got != "api error 500 [oops[]" {
+		t.Errorf("message should be escaped, got %q", got)
+	}
+}
@@ -64,7 +64,7 @@ func (v *BackfillsView) UpdateList(bfs []models.Backfill) {
 			fmt.Sprintf("[%s]%s[-]", stateColorHex, bf.State()),
 			fmt.Sprintf("%s -> %s", bf.FromDate.Format("2006-01-02"), bf.ToDate.Format("2006-01-02")),
 			fmt.Sprintf("%d/%d", bf.CompletedRuns, bf.TotalRuns),
-			ago(bf.CreatedAt),
+			Ago(bf.CreatedAt),
 		}
 		for c, s := range cells {
 			v.list.SetCell(row, c, tview.NewTableCell(s))
@@ -117,7 +117,8 @@ func stateTokenForBackfill(state string) string {
 	}
 }
 
-func ago(t time.Time) string {
+// Ago renders t relative to now ("12s ago"), or "-" for the zero time.
+func Ago(t time.Time) string {
 	if t.IsZero() {
 		return "-"
 	}
@@ -0,0 +1,394 @@
+package views
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/yjinheon/lazyflow/internal/ui/theme"
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+// CompareRow pairs one task's instances from two runs. A or B is nil when the
+// task exists in only one of them (added/removed between DAG versions). A
+// mapped task has a row per map index; MapIndex is models.NotMapped otherwise.
+type CompareRow struct {
+	TaskId   string
+	MapIndex int
+	A, B     *models.TaskInstance
+}
+
+// Name labels the row: the task id, with the expansion's map label on a
+// mapped task ("render[emea]").
+func (r CompareRow) Name() string {
+	if r.MapIndex == models.NotMapped {
+		return r.TaskId
+	}
+	ti := r.A
+	if ti == nil {
+		ti = r.B
+	}
+	return r.TaskId + "[" + ti.MapLabel() + "]"
+}
+
+// CompareRuns joins two runs' task instances by task id and map index,
+// sorted by id, then index.
+func CompareRuns(a, b []models.TaskInstance) []CompareRow {
+	mapped := mappedTaskIds(a, nil)
+	for id := range mappedTaskIds(b, nil) {
+		mapped[id] = true
+	}
+	byKey := map[string]*CompareRow{}
+	join := func(ti *models.TaskInstance) *CompareRow {
+		key := instanceKey(*ti, mapped)
+		row := byKey[key]
+		if row == nil {
+			row = &CompareRow{TaskId: ti.TaskId, MapIndex: models.NotMapped}
+			if mapped[ti.TaskId] {
+				row.MapIndex = ti.MapIndex
+			}
+			byKey[key] = row
+		}
+		return row
+	}
+	for i := range a {
+		join(&a[i]).A = &a[i]
+	}
+	for i := range b {
+		join(&b[i]).B = &b[i]
+	}
+	out := make([]CompareRow, 0, len(byKey))
+	for _, r := range byKey {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].TaskId != out[j].TaskId {
+			return out[i].TaskId < out[j].TaskId
+		}
+		return out[i].MapIndex < out[j].MapIndex
+	})
+	return out
+}
+
+// taskDuration is the run time shown in the compare table: the API's duration
+// once finished, wall time so far while running.
+func taskDuration(ti *models.TaskInstance, now time.Time) time.Duration {
+	if ti == nil {
+		return 0
+	}
+	if ti.Duration > 0 {
+		return time.Duration(ti.Duration * float64(time.Second))
+	}
+	return effectiveDuration(*ti, now)
+}
+
+// queueDuration is how long the instance waited between queued and started.
+func queueDuration(ti *models.TaskInstance) time.Duration {
+	if ti == nil || ti.QueuedDttm == nil || ti.StartDate == nil {
+		return 0
+	}
+	if d := ti.StartDate.Sub(*ti.QueuedDttm); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// deltaMinimum keeps sub-second jitter from being highlighted as a change.
+const deltaMinimum = time.Second
+
+// formatDelta renders b-a with a sign and a colour: slower is red, faster is
+// green, a change under 10% (or deltaMinimum) stays muted.
+func formatDelta(a, b time.Duration) (string, tcell.Color) {
+	th := theme.ActiveTheme()
+	if a <= 0 || b <= 0 {
+		return "-", th.MutedText
+	}
+	d := b - a
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+	pct := float64(d) / float64(a) * 100
+	text := fmt.Sprintf("%s (%+.0f%%)", signedDuration(d), pct)
+	if abs < deltaMinimum || math.Abs(pct) < 10 {
+		return text, th.MutedText
+	}
+	if d > 0 {
+		return text, th.StatusFailed
+	}
+	return text, th.StatusSuccess
+}
+
+func signedDuration(d time.Duration) string {
+	if d < 0 {
+		return "-" + formatDuration(-d)
+	}
+	if d == 0 {
+		return "0s"
+	}
+	return "+" + formatDuration(d)
+}
+
+// shiftTI moves every timestamp of ti by d so two runs from different days can
+// share one time axis.
+func shiftTI(ti models.TaskInstance, d time.Duration) models.TaskInstance {
+	shift := func(t *time.Time) *time.Time {
+		if t == nil {
+			return nil
+		}
+		s := t.Add(d)
+		return &s
+	}
+	ti.QueuedDttm = shift(ti.QueuedDttm)
+	ti.StartDate = shift(ti.StartDate)
+	ti.EndDate = shift(ti.EndDate)
+	return ti
+}
+
+// CompareView puts two runs of the same DAG side by side: a per-task table
+// with deltas and a dual-lane Gantt (A above B) on a shared, start-aligned axis.
+type CompareView struct {
+	*tview.Flex
+
+	table *tview.Table
+	gantt *tview.TextView
+
+	runA, runB models.DAGRun
+	rows       []CompareRow
+	ganttW     int // width the Gantt was last rendered for
+}
+
+func NewCompareView() *CompareView {
+	v := &CompareView{
+		Flex:  tview.NewFlex(),
+		table: tview.NewTable(),
+		gantt: tview.NewTextView(),
+	}
+	th := theme.ActiveTheme()
+	v.table.SetBorder(true).SetTitle(" Compare Runs ")
+	v.table.SetFixed(1, 1)
+	v.table.SetSelectable(false, false)
+	v.table.SetSelectedStyle(tcell.StyleDefault.
+		Background(th.TableSelected).Foreground(th.PrimaryText).Attributes(tcell.AttrBold))
+	v.table.SetFocusFunc(func() { v.table.SetBorderColor(th.BorderFocused) })
+	v.table.SetBlurFunc(func() { v.table.SetBorderColor(th.BorderColor) })
+
+	v.gantt.SetDynamicColors(true).SetScrollable(true).SetWrap(false)
+	v.gantt.SetBorder(true).SetTitle(" Gantt (A / B, aligned at run start) ")
+
+	v.SetDirection(tview.FlexRow).
+		AddItem(v.table, 0, 1, true).
+		AddItem(v.gantt, 0, 1, false)
+	v.setMessage("Mark two runs with m in the Runs tab, then press C.")
+	return v
+}
+
+func (v *CompareView) Root() tview.Primitive { return v }
+
+// Table exposes the compare table for focus handling.
+func (v *CompareView) Table() *tview.Table { return v.table }
+
+func (v *CompareView) setMessage(msg string) {
+	v.rows = nil
+	v.table.Clear()
+	v.table.SetSelectable(false, false)
+	setEmptyHint(v.table, msg)
+	v.gantt.SetText("")
+}
+
+// SetLoading shows a placeholder while the two runs' task instances load.
+func (v *CompareView) SetLoading(a, b string) {
+	v.setMessage(fmt.Sprintf("Loading %s and %s...", a, b))
+}
+
+// SetError replaces the view with a fetch error.
+func (v *CompareView) SetError(msg string) {
+	v.setMessage("Compare failed: " + msg)
+}
+
+// Update renders a (the baseline) against b.
+func (v *CompareView) Update(runA models.DAGRun, tisA []models.TaskInstance, runB models.DAGRun, tisB []models.TaskInstance) {
+	v.runA, v.runB = runA, runB
+	v.rows = CompareRuns(tisA, tisB)
+	v.renderTable(time.Now())
+	v.ganttW = 0 // force a Gantt redraw on the next Draw
+}
+
+// Draw re-renders the Gantt when its width changes; bar widths depend on it.
+func (v *CompareView) Draw(screen tcell.Screen) {
+	v.Flex.Draw(screen)
+	_, _, w, _ := v.gantt.GetInnerRect()
+	if w > 0 && w != v.ganttW && len(v.rows) > 0 {
+		v.ganttW = w
+		v.gantt.SetText(v.renderGantt(w, time.Now()))
+		v.gantt.Draw(screen)
+	}
+}
+
+func (v *CompareView) renderTable(now time.Time) {
+	th := theme.ActiveTheme()
+	v.table.Clear()
+	v.table.SetTitle(fmt.Sprintf(" Compare  A: %s  vs  B: %s ", v.runA.RunId, v.runB.RunId))
+
+	headers := []string{"Task", "State A", "State B", "Dur A", "Dur B", "Δ Dur", "Queue A", "Queue B", "Δ Queue", "Try A/B", "Host A / B"}
+	for i, h := range headers {
+		cell := tview.NewTableCell(h).
+			SetTextColor(th.TableHeaderText).
+			SetSelectable(false)
+		if i == 0 {
+			cell.SetExpansion(1)
+		}
+		v.table.SetCell(0, i, cell)
+	}
+	if len(v.rows) == 0 {
+		setEmptyHint(v.table, "Neither run has task instances yet.")
+		return
+	}
+	v.table.SetSelectable(true, false)
+
+	for i, r := range v.rows {
+		row := i + 1
+		bg := th.PrimaryBg
+		if row%2 == 0 {
+			bg = th.TableRowAlt
+		}
+		set := func(col int, text string, color tcell.Color) {
+			v.table.SetCell(row, col, tview.NewTableCell(tview.Escape(text)).
+				SetTextColor(color).SetBackgroundColor(bg))
+		}
+
+		set(0, "  "+r.Name(), th.PrimaryText)
+		v.table.GetCell(row, 0).SetExpansion(1)
+
+		stateA, stateB := compareField(r.A, func(ti *models.TaskInstance) string { return ti.State }),
+			compareField(r.B, func(ti *models.TaskInstance) string { return ti.State })
+		_, colorA := th.StatusStyle(stateA)
+		_, colorB := th.StatusStyle(stateB)
+		set(1, stateA, colorA)
+		set(2, stateB, colorB)
+
+		durA, durB := taskDuration(r.A, now), taskDuration(r.B, now)
+		set(3, formatDuration(durA), th.PrimaryText)
+		set(4, formatDuration(durB), th.PrimaryText)
+		text, color := formatDelta(durA, durB)
+		set(5, text, color)
+
+		qA, qB := queueDuration(r.A), queueDuration(r.B)
+		set(6, formatDuration(qA), th.PrimaryText)
+		set(7, formatDuration(qB), th.PrimaryText)
+		text, color = formatDelta(qA, qB)
+		set(8, text, color)
+
+		tryA := compareField(r.A, func(ti *models.TaskInstance) string { return fmt.Sprintf("%d", ti.TryNumber) })
+		tryB := compareField(r.B, func(ti *models.TaskInstance) string { return fmt.Sprintf("%d", ti.TryNumber) })
+		set(9, tryA+"/"+tryB, diffColor(tryA, tryB))
+
+		hostA := compareField(r.A, func(ti *models.TaskInstance) string { return ti.Hostname })
+		hostB := compareField(r.B, func(ti *models.TaskInstance) string { return ti.Hostname })
+		set(10, hostA+" / "+hostB, diffColor(hostA, hostB))
+	}
+}
+
+// compareField reads a display field, "-" when the task is missing from a run.
+func compareField(ti *models.TaskInstance, get func(*models.TaskInstance) string) string {
+	if ti == nil {
+		return "-"
+	}
+	if s := get(ti); s != "" {
+		return s
+	}
+	return "-"
+}
+
+func diffColor(a, b string) tcell.Color {
+	if a != b {
+		return theme.ActiveTheme().StatusPaused
+	}
+	return theme.ActiveTheme().PrimaryText
+}
+
+// renderGantt draws each task as two lanes, A then B. B's timestamps are
+// shifted so both runs start at the same x, making drift visible at a glance.
+func (v *CompareView) renderGantt(innerW int, now time.Time) string {
+	const labelCol = 25
+	barW := innerW - labelCol - 1
+	if barW < 10 {
+		return fmt.Sprintf("[gray]Terminal too narrow (need >=%d cols).", labelCol+11)
+	}
+
+	var tisA, tisB []models.TaskInstance
+	for _, r := range v.rows {
+		if r.A != nil {
+			tisA = append(tisA, *r.A)
+		}
+		if r.B != nil {
+			tisB = append(tisB, *r.B)
+		}
+	}
+	startA, startB := earliestQueued(tisA), earliestQueued(tisB)
+	if startA.IsZero() && startB.IsZero() {
+		return "[gray]Neither run has started tasks yet."
+	}
+	var offset time.Duration
+	if !startA.IsZero() && !startB.IsZero() {
+		offset = startA.Sub(startB)
+	}
+	// Running bars end at now; shift it with B so its live bar ends correctly.
+	nowB := now.Add(offset)
+
+	shiftedB := make([]models.TaskInstance, len(tisB))
+	for i, ti := range tisB {
+		shiftedB[i] = shiftTI(ti, offset)
+	}
+	all := append(append([]models.TaskInstance(nil), tisA...), shiftedB...)
+	tMax := latestEnd(tisA, now)
+	if endB := latestEnd(shiftedB, nowB); endB.After(tMax) {
+		tMax = endB
+	}
+	buckets, tMin, tMax := ComputeBuckets(all, barW, tMax)
+	if buckets == nil {
+		return "[gray]Neither run has started tasks yet."
+	}
+
+	var b strings.Builder
+	muted := theme.MarkupHex(theme.ActiveTheme().MutedText)
+	fmt.Fprintf(&b, "[%s]A %s   B %s   elapsed 0 -> %s[-]\n",
+		muted, tview.Escape(v.runA.RunId), tview.Escape(v.runB.RunId), formatDuration(tMax.Sub(tMin)))
+	for _, r := range v.rows {
+		label := truncate(r.Name(), labelCol-4)
+		fmt.Fprintf(&b, "%s A ", tview.Escape(fmt.Sprintf("%-*s", labelCol-3, label)))
+		if r.A != nil {
+			b.WriteString(EmitRLE(RenderCells(*r.A, buckets, now), false))
+		}
+		b.WriteByte('\n')
+		fmt.Fprintf(&b, "%-*s B ", labelCol-3, "")
+		if r.B != nil {
+			b.WriteString(EmitRLE(RenderCells(shiftTI(*r.B, offset), buckets, nowB), false))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// latestEnd is the right edge of the shared axis: the last end time, or now
+// when something is still running.
+func latestEnd(tis []models.TaskInstance, now time.Time) time.Time {
+	var t time.Time
+	for _, ti := range tis {
+		end := deref(ti.EndDate)
+		if end.IsZero() && ti.StartDate != nil && ti.State == "running" {
+			end = now
+		}
+		if end.After(t) {
+			t = end
+		}
+	}
+	if t.IsZero() {
+		return now
+	}
+	return t
+}
@@ -0,0 +1,93 @@
+package views
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/rivo/tview"
+	"github.com/yjinheon/lazyflow/internal/ui/theme"
+)
+
+// PrettyConf renders a decoded DAG-run conf as indented JSON for the trigger
+// form. A nil or empty conf yields "{}" so the form always holds valid JSON.
+func PrettyConf(conf any) string {
+	if conf == nil {
+		return "{}"
+	}
+	if m, ok := conf.(map[string]any); ok && len(m) == 0 {
+		return "{}"
+	}
+	out, err := json.MarshalIndent(conf, "", "  ")
+	if err != nil {
+		return "{}"
+	}
+	return string(out)
+}
+
+// ConfTree builds a collapsible tree of a decoded JSON value. Objects and
+// arrays become branches (keys sorted for a stable layout); scalars are
+// leaves rendered as "key: value". Node text is escaped because array indices
+// ("[0]") would otherwise parse as colour tags.
+func ConfTree(title string, conf any) *tview.TreeNode {
+	root := tview.NewTreeNode(title).SetColor(theme.ActiveTheme().Accent)
+	if conf == nil {
+		root.AddChild(tview.NewTreeNode("(empty conf)").SetColor(theme.ActiveTheme().MutedText))
+		return root
+	}
+	addConfChildren(root, conf)
+	if len(root.GetChildren()) == 0 {
+		root.AddChild(tview.NewTreeNode("(empty conf)").SetColor(theme.ActiveTheme().MutedText))
+	}
+	return root
+}
+
+func addConfChildren(parent *tview.TreeNode, v any) {
+	switch val := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			parent.AddChild(confNode(k, val[k]))
+		}
+	case []any:
+		for i, item := range val {
+			parent.AddChild(confNode(fmt.Sprintf("[%d]", i), item))
+		}
+	default:
+		parent.AddChild(confNode("", val))
+	}
+}
+
+func confNode(key string, v any) *tview.TreeNode {
+	th := theme.ActiveTheme()
+	switch val := v.(type) {
+	case map[string]any:
+		node := tview.NewTreeNode(tview.Escape(fmt.Sprintf("%s {%d}", key, len(val)))).SetColor(th.SectionHeader)
+		addConfChildren(node, val)
+		return node
+	case []any:
+		node := tview.NewTreeNode(tview.Escape(fmt.Sprintf("%s [%d]", key, len(val)))).SetColor(th.SectionHeader)
+		addConfChildren(node, val)
+		return node
+	}
+	text := confScalar(v)
+	if key != "" {
+		text = key + ": " + text
+	}
+	return tview.NewTreeNode(tview.Escape(text)).SetColor(th.PrimaryText)
+}
+
+func confScalar(v any) string {
+	if v == nil {
+		return "null"
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(out)
+}
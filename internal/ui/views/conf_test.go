@@ -0,0 +1,55 @@
+package views
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rivo/tview"
+)
+
+func TestPrettyConf(t *testing.T) {
+	if got := PrettyConf(nil); got != "{}" {
+		t.Errorf("nil conf = %q, want {}", got)
+	}
+	if got := PrettyConf(map[string]any{}); got != "{}" {
+		t.Errorf("empty conf = %q, want {}", got)
+	}
+	got := PrettyConf(map[string]any{"b": 1, "a": "x"})
+	want := "{\n  \"a\": \"x\",\n  \"b\": 1\n}"
+	if got != want {
+		t.Errorf("PrettyConf = %q, want %q", got, want)
+	}
+}
+
+func TestConfTreeShape(t *testing.T) {
+	var conf any
+	if err := json.Unmarshal([]byte(`{"date":"2026-01-01","opts":{"full":true},"ids":[1,2]}`), &conf); err != nil {
+		t.Fatal(err)
+	}
+	root := ConfTree("run-1", conf)
+	children := root.GetChildren()
+	if len(children) != 3 {
+		t.Fatalf("top-level children = %d, want 3", len(children))
+	}
+	// Keys are sorted: date, ids, opts.
+	if got := children[0].GetText(); got != `date: "2026-01-01"` {
+		t.Errorf("first leaf = %q", got)
+	}
+	ids := children[1]
+	if len(ids.GetChildren()) != 2 {
+		t.Errorf("ids branch has %d children, want 2", len(ids.GetChildren()))
+	}
+	if got := ids.GetChildren()[0].GetText(); got != tview.Escape("[0]: 1") {
+		t.Errorf("array leaf = %q, want escaped index", got)
+	}
+	if got := children[2].GetChildren()[0].GetText(); got != "full: true" {
+		t.Errorf("nested leaf = %q", got)
+	}
+}
+
+func TestConfTreeEmpty(t *testing.T) {
+	root := ConfTree("run-1", nil)
+	if len(root.GetChildren()) != 1 || root.GetChildren()[0].GetText() != "(empty conf)" {
+		t.Fatalf("empty conf should render a placeholder leaf")
+	}
+}
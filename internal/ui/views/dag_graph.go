@@ -101,10 +101,101 @@ func topoLevels(tasks []models.Task) [][]string {
 	return levels
 }
 
+// graphFocus marks the selected node for renderGraph: it is drawn reversed,
+// its upstream and downstream closure bold, and every other node dimmed.
+// first is the leftmost stage drawn. labels replaces the id drawn for a
+// node, already cut to fit. The zero value draws the plain graph.
+type graphFocus struct {
+	selected string
+	related  map[string]bool
+	first    int
+	labels   map[string]string
+}
+
+// newGraphFocus selects taskId and collects its lineage.
+func newGraphFocus(tasks []models.Task, taskId string, first int) graphFocus {
+	up, down := lineageOf(tasks, taskId)
+	related := make(map[string]bool, len(up)+len(down))
+	for id := range up {
+		related[id] = true
+	}
+	for id := range down {
+		related[id] = true
+	}
+	return graphFocus{selected: taskId, related: related, first: first}
+}
+
+// lineageOf returns every task upstream and downstream of taskId, following
+// upstream_task_ids (downstream edges are derived from them).
+func lineageOf(tasks []models.Task, taskId string) (up, down map[string]bool) {
+	parents, children := graphEdges(tasks)
+	walk := func(edges map[string][]string) map[string]bool {
+		seen := map[string]bool{}
+		stack := append([]string(nil), edges[taskId]...)
+		for len(stack) > 0 {
+			id := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if seen[id] || id == taskId {
+				continue
+			}
+			seen[id] = true
+			stack = append(stack, edges[id]...)
+		}
+		return seen
+	}
+	return walk(parents), walk(children)
+}
+
+// graphEdges maps each task to its direct upstream and downstream tasks,
+// ignoring edges to tasks that are not in the list.
+func graphEdges(tasks []models.Task) (parents, children map[string][]string) {
+	known := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		known[t.TaskId] = true
+	}
+	parents, children = map[string][]string{}, map[string][]string{}
+	for _, t := range tasks {
+		for _, u := range t.UpstreamTaskIds {
+			if known[u] {
+				parents[t.TaskId] = append(parents[t.TaskId], u)
+				children[u] = append(children[u], t.TaskId)
+			}
+		}
+	}
+	return parents, children
+}
+
+// graphWindow returns the stages [first, end) drawn in width columns. When
+// stages remain on the right, the last slot is left for the "+N more" marker.
+func graphWindow(stages, first, width int) (int, int) {
+	maxVisible := max(width/graphStageWidth, 1)
+	first = max(min(first, stages-1), 0)
+	end := min(stages, first+maxVisible)
+	if end < stages && end-first > 1 {
+		end--
+	}
+	return first, end
+}
+
+// scrollStages returns the first stage to draw so that stage stays in view,
+// moving as little as possible from first.
+func scrollStages(first, stage, stages, width int) int {
+	if stage < first {
+		return stage
+	}
+	for {
+		f, end := graphWindow(stages, first, width)
+		if stage < end || f >= stage {
+			return f
+		}
+		first++
+	}
+}
+
 // renderGraph builds tview dynamic-color markup laying out tasks as
 // topological stages from left to right. Stages beyond the available width are
-// collapsed into an overflow marker.
-func renderGraph(tasks []models.Task, stateOf func(taskId string) NodeState, width int) string {
+// collapsed into overflow markers.
+func renderGraph(tasks []models.Task, stateOf func(taskId string) NodeState, width int, focus graphFocus) string {
 	levels := topoLevels(tasks)
 	if len(levels) == 0 {
 		return "[gray]no tasks to graph"
@@ -112,17 +203,12 @@ func renderGraph(tasks []models.Task, stateOf func(taskId string) NodeState, wid
 	if width < graphStageWidth {
 		width = graphStageWidth
 	}
-	maxVisible := max(width/graphStageWidth, 1)
-	visible := len(levels)
-	truncated := 0
-	if len(levels) > maxVisible {
-		visible = max(maxVisible-1, 1)
-		truncated = len(levels) - visible
-	}
+	first, end := graphWindow(len(levels), focus.first, width)
+	truncated := len(levels) - end
 
 	th := theme.ActiveTheme()
 	var b strings.Builder
-	for i := 0; i < visible; i++ {
+	for i := first; i < end; i++ {
 		fmt.Fprintf(&b, "[yellow::b]%-*s[-:-:-]", graphStageWidth, fmt.Sprintf("Stage %d", i+1))
 	}
 	if truncated > 0 {
@@ -131,22 +217,36 @@ func renderGraph(tasks []models.Task, stateOf func(taskId string) NodeState, wid
 	b.WriteByte('\n')
 
 	maxRows := 0
-	for i := 0; i < visible; i++ {
+	for i := first; i < end; i++ {
 		if len(levels[i]) > maxRows {
 			maxRows = len(levels[i])
 		}
 	}
 	for row := 0; row < maxRows; row++ {
-		for i := 0; i < visible; i++ {
+		for i := first; i < end; i++ {
 			cell := ""
 			if row < len(levels[i]) {
 				id := levels[i][row]
 				sym, color := th.StatusStyle(string(stateOf(id)))
 				arrow := ""
-				if i < visible-1 {
+				if i < end-1 {
 					arrow = " → "
 				}
-				cell = fmt.Sprintf("[%s]%s %s[-]%s", theme.MarkupHex(color), sym, truncate(id, graphNodeLabel), arrow)
+				attr := ""
+				switch {
+				case focus.selected == "":
+				case id == focus.selected:
+					attr = "::r"
+				case focus.related[id]:
+					attr = "::b"
+				default:
+					color = th.MutedText
+				}
+				label, ok := focus.labels[id]
+				if !ok {
+					label = truncate(id, graphNodeLabel)
+				}
+				cell = fmt.Sprintf("[%s%s]%s %s[-::-]%s", theme.MarkupHex(color), attr, sym, label, arrow)
 			}
 			b.WriteString(cell)
 			if pad := graphStageWidth - displayLen(cell); pad > 0 {
@@ -155,6 +255,9 @@ func renderGraph(tasks []models.Task, stateOf func(taskId string) NodeState, wid
 		}
 		b.WriteByte('\n')
 	}
+	if first > 0 {
+		fmt.Fprintf(&b, "[gray]… %d earlier stage(s) to the left[-]\n", first)
+	}
 	if truncated > 0 {
 		fmt.Fprintf(&b, "[gray]… %d more stage(s) - press g for tree view[-]\n", truncated)
 	}
@@ -83,7 +83,7 @@ func TestRenderGraphContainsTasks(t *testing.T) {
 		}
 		return NodePending
 	}
-	out := renderGraph(tasks, stateOf, 120)
+	out := renderGraph(tasks, stateOf, 120, graphFocus{})
 	for _, id := range []string{"extract", "transform", "load"} {
 		if !strings.Contains(out, id) {
 			t.Errorf("renderGraph output missing task %q\n%s", id, out)
@@ -103,14 +103,14 @@ func TestRenderGraphOverflowMarker(t *testing.T) {
 		{TaskId: "s5", UpstreamTaskIds: []string{"s4"}},
 		{TaskId: "s6", UpstreamTaskIds: []string{"s5"}},
 	}
-	out := renderGraph(tasks, func(string) NodeState { return NodePending }, 24)
+	out := renderGraph(tasks, func(string) NodeState { return NodePending }, 24, graphFocus{})
 	if !strings.Contains(out, "more stage") {
 		t.Errorf("expected overflow marker for narrow width\n%s", out)
 	}
 }
 
 func TestRenderGraphEmpty(t *testing.T) {
-	out := renderGraph(nil, func(string) NodeState { return NodePending }, 80)
+	out := renderGraph(nil, func(string) NodeState { return NodePending }, 80, graphFocus{})
 	if !strings.Contains(out, "no tasks") {
 		t.Errorf("expected empty message, got %q", out)
 	}
@@ -15,7 +15,8 @@ type DagListView struct {
 	*tview.Table
 	allDags     []models.DAG // unfiltered
 	dags        []models.DAG // currently displayed (filtered)
-	filterMode  string       // "all", "active", "paused", or latest-run state
+	filterMode  string       // "all", "active", "paused", "sla", or latest-run state
+	slaBreached map[string]bool
 	searchQuery string
 	activeDagId string // committed via Enter; distinct from the cursor row
 	onSelected  func(dagId string)
@@ -130,6 +131,12 @@ func (v *DagListView) applyFilter() {
 				filtered = append(filtered, d)
 			}
 		}
+	case "sla":
+		for _, d := range v.allDags {
+			if v.slaBreached[d.DagId] {
+				filtered = append(filtered, d)
+			}
+		}
 	case "running", "success", "failed":
 		for _, d := range v.allDags {
 			if d.LastRunState == v.filterMode {
@@ -164,6 +171,37 @@ func (v *DagListView) applyFilter() {
 	v.dags = filtered
 }
 
+// SelectDag commits dagId as if Enter were pressed on its row, moving the
+// cursor there when the current filter shows it.
+func (v *DagListView) SelectDag(dagId string) {
+	for i, d := range v.dags {
+		if d.DagId == dagId {
+			v.Select(i+1, 0)
+			break
+		}
+	}
+	v.setActiveDag(dagId)
+	if v.onSelected != nil {
+		v.onSelected(dagId)
+	}
+}
+
+// SetSLABreaches marks the DAGs with a breached SLA; they get a ⚑ badge and
+// make up the "sla" filter.
+func (v *DagListView) SetSLABreaches(dagIds map[string]bool) {
+	v.slaBreached = dagIds
+	v.applyFilter()
+	v.render()
+}
+
+func (v *DagListView) dagLabel(dagId string, active bool) string {
+	label := rowLabel(dagId, active)
+	if v.slaBreached[dagId] {
+		label += fmt.Sprintf(" [%s]⚑[-]", theme.MarkupHex(theme.ActiveTheme().StatusFailed))
+	}
+	return label
+}
+
 // setActiveDag re-marks the committed row in place. It avoids Clear/SetSelectable
 // because it runs inside the table's own input handler.
 func (v *DagListView) setActiveDag(dagId string) {
@@ -177,7 +215,7 @@ func (v *DagListView) setActiveDag(dagId string) {
 			continue
 		}
 		active := d.DagId == dagId
-		cell.SetText(rowLabel(d.DagId, active)).SetTextColor(rowLabelColor(active))
+		cell.SetText(v.dagLabel(d.DagId, active)).SetTextColor(rowLabelColor(active))
 	}
 }
 
@@ -200,7 +238,7 @@ func (v *DagListView) render() {
 		}
 
 		active := dag.DagId == v.activeDagId
-		v.SetCell(row, 0, tview.NewTableCell(rowLabel(dag.DagId, active)).
+		v.SetCell(row, 0, tview.NewTableCell(v.dagLabel(dag.DagId, active)).
 			SetTextColor(rowLabelColor(active)).SetExpansion(1).SetBackgroundColor(bg))
 
 		stateStr := "Active"
@@ -2,9 +2,14 @@ package views
 
 import (
 	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+	"github.com/yjinheon/lazyflow/internal/metrics"
 	"github.com/yjinheon/lazyflow/internal/ui/theme"
 	"github.com/yjinheon/lazyflow/pkg/airflow/models"
 )
@@ -47,7 +52,11 @@ type ExecutionView struct {
 
 	tasks     []models.TaskInstance
 	defs      []models.Task
+	rows      []groupRow // task list rows after the header
+	expanded  map[string]bool
+	mapped    map[string]bool // mapped task ids, listed one row per expansion
 	runId     string
+	baselines map[string]metrics.TaskBaseline
 	onTaskSel func(taskId string)
 }
 
@@ -60,6 +69,7 @@ func NewExecutionView() *ExecutionView {
 		logs:     tview.NewTextView(),
 		miniDAG:  tview.NewTextView(),
 		gantt:    NewGanttView(),
+		expanded: map[string]bool{},
 	}
 	v.setup()
 	return v
@@ -104,13 +114,19 @@ func (v *ExecutionView) setup() {
 		AddItem(body, 0, 1, true)
 
 	v.taskList.SetSelectedFunc(func(row, _ int) {
-		if row > 0 && row <= len(v.tasks) && v.onTaskSel != nil {
-			v.onTaskSel(v.tasks[row-1].TaskId)
+		if row <= 0 || row > len(v.rows) {
+			return
+		}
+		if r := v.rows[row-1]; r.group != "" {
+			v.toggleGroup(r.group)
+		} else if v.onTaskSel != nil {
+			v.onTaskSel(v.tasks[r.index].TaskId)
 		}
 	})
 	v.taskList.SetSelectionChangedFunc(func(row, _ int) {
-		if row > 0 && row <= len(v.tasks) {
-			v.renderDetail(v.tasks[row-1])
+		if row > 0 && row <= len(v.rows) {
+			v.renderRowDetail(v.rows[row-1])
+			v.renderMiniDAG()
 		}
 	})
 }
@@ -119,21 +135,134 @@ func (v *ExecutionView) SetOnTaskSelected(fn func(taskId string)) { v.onTaskSel
 func (v *ExecutionView) Root() tview.Primitive                    { return v.Flex }
 func (v *ExecutionView) TaskList() *tview.Table                   { return v.taskList }
 
+// SetBaselines supplies per-task historical durations (see
+// metrics.TaskBaselines) so unusually slow tasks are flagged. Baselines are
+// cleared whenever a different run is shown.
+func (v *ExecutionView) SetBaselines(runId string, baselines map[string]metrics.TaskBaseline) {
+	if runId != v.runId {
+		return // a slow history read finished after the user moved on
+	}
+	v.baselines = baselines
+	v.renderTaskList(v.tasks)
+	if row, ok := v.currentRow(); ok {
+		v.renderRowDetail(row)
+	}
+}
+
+// anomaly reports whether ti's duration is an outlier for its task.
+func (v *ExecutionView) anomaly(ti models.TaskInstance) (metrics.TaskBaseline, float64, bool) {
+	b, ok := v.baselines[ti.TaskId]
+	if !ok {
+		return metrics.TaskBaseline{}, 0, false
+	}
+	z, bad := metrics.CheckDuration(metrics.TaskDuration(ti, time.Now()), b)
+	return b, z, bad
+}
+
+// currentRow returns the task list row under the cursor.
+func (v *ExecutionView) currentRow() (groupRow, bool) {
+	row, _ := v.taskList.GetSelection()
+	if row <= 0 || row > len(v.rows) {
+		return groupRow{}, false
+	}
+	return v.rows[row-1], true
+}
+
+// CurrentTask returns the task instance under the cursor; false on a task
+// group's row.
+func (v *ExecutionView) CurrentTask() (models.TaskInstance, bool) {
+	row, ok := v.currentRow()
+	if !ok || row.group != "" {
+		return models.TaskInstance{}, false
+	}
+	return v.tasks[row.index], true
+}
+
+// CurrentMapIndex is the map index of the expansion under the cursor;
+// models.NotMapped on an unmapped task or a header row.
+func (v *ExecutionView) CurrentMapIndex() int {
+	ti, ok := v.CurrentTask()
+	if !ok || !v.mapped[ti.TaskId] {
+		return models.NotMapped
+	}
+	return ti.MapIndex
+}
+
+// taskIds lists the run's instance keys in list order, for groupedRows.
+func (v *ExecutionView) taskIds() []string {
+	ids := make([]string, len(v.tasks))
+	for i, ti := range v.tasks {
+		ids[i] = instanceKey(ti, v.mapped)
+	}
+	return ids
+}
+
+// groupsOf places each instance under its task groups; the expansions of a
+// mapped task also sit under the task itself, which lists like a group.
+func (v *ExecutionView) groupsOf() map[string][]string {
+	groups := taskGroupsOf(v.defs)
+	out := make(map[string][]string, len(v.tasks))
+	for _, ti := range v.tasks {
+		g := groups[ti.TaskId]
+		if v.mapped[ti.TaskId] {
+			g = append(g[:len(g):len(g)], ti.TaskId)
+		}
+		out[instanceKey(ti, v.mapped)] = g
+	}
+	return out
+}
+
+// rowLabel is the task list text of a row.
+func (v *ExecutionView) rowLabel(r groupRow, ids []string) string {
+	expanded := v.expanded[r.group]
+	if v.mapped[r.group] || (r.group == "" && v.mapped[v.tasks[r.index].TaskId]) {
+		return mappedRowLabel(r, v.tasks, expanded)
+	}
+	return r.label(ids, expanded)
+}
+
+// toggleGroup opens or closes a task group in the list, keeping the cursor
+// on its row.
+func (v *ExecutionView) toggleGroup(group string) {
+	v.expanded[group] = !v.expanded[group]
+	v.renderTaskList(v.tasks)
+	v.selectKey(group)
+}
+
+// selectKey puts the cursor on the row of a task or group id, the first row
+// when there is none.
+func (v *ExecutionView) selectKey(key string) {
+	ids := v.taskIds()
+	row := 1
+	for i, r := range v.rows {
+		if r.key(ids) == key {
+			row = i + 1
+			break
+		}
+	}
+	v.taskList.Select(row, 0)
+	v.renderRowDetail(v.rows[row-1])
+}
+
 func (v *ExecutionView) UpdateRun(run models.DAGRun, tis []models.TaskInstance, defs []models.Task, onCritical map[string]bool) {
 	// Preserve the user's current selection across poll-driven refreshes.
 	// Only reset to the first task when the run itself changes; otherwise the
 	// periodic "tasks" poll would snap the selection back to row 1 every tick.
 	sameRun := v.runId == run.RunId
-	prevTaskId := ""
+	if !sameRun {
+		v.baselines = nil
+	}
+	prevKey := ""
 	if sameRun {
-		if r, _ := v.taskList.GetSelection(); r > 0 && r <= len(v.tasks) {
-			prevTaskId = v.tasks[r-1].TaskId
+		if r, ok := v.currentRow(); ok {
+			prevKey = r.key(v.taskIds())
 		}
 	}
 
 	v.runId = run.RunId
 	v.tasks = tis
 	v.defs = defs
+	v.mapped = mappedTaskIds(tis, defs)
 	v.renderSummary(run, tis)
 	v.renderTaskList(tis)
 	v.renderMiniDAG()
@@ -146,17 +275,7 @@ func (v *ExecutionView) UpdateRun(run models.DAGRun, tis []models.TaskInstance,
 
 	// Re-select the previously selected task by id so the choice survives
 	// reordering; fall back to the first row for a new run or if it vanished.
-	row := 1
-	if prevTaskId != "" {
-		for i, ti := range tis {
-			if ti.TaskId == prevTaskId {
-				row = i + 1
-				break
-			}
-		}
-	}
-	v.taskList.Select(row, 0)
-	v.renderDetail(tis[row-1])
+	v.selectKey(prevKey)
 }
 
 func (v *ExecutionView) renderSummary(run models.DAGRun, tis []models.TaskInstance) {
@@ -167,13 +286,22 @@ func (v *ExecutionView) renderSummary(run models.DAGRun, tis []models.TaskInstan
 	if !run.StartDate.IsZero() {
 		start = run.StartDate.Format("01-02 15:04:05")
 	}
+	note := ""
+	if p := notePreview(run.Note, 40); p != "" {
+		note = "  -  [yellow]✎[-] " + tview.Escape(p)
+	}
 	v.summary.SetText(fmt.Sprintf(
-		" [%s]%s %s[-]  -  %s  -  [green]%d/%d done[-] - [red]%d failed[-] - [gray]%d queued[-]  -  [gray]Enter load logs / 3 full logs[-]",
-		theme.MarkupHex(color), sym, run.RunId, start, s.Done, s.Total, s.Failed, s.Queued))
+		" [%s]%s %s[-]  -  %s  -  [green]%d/%d done[-] - [red]%d failed[-] - [gray]%d queued[-]%s  -  [gray]Enter load logs / 3 full logs / n note[-]",
+		theme.MarkupHex(color), sym, run.RunId, start, s.Done, s.Total, s.Failed, s.Queued, note))
 }
 
+// renderTaskList lists the run's tasks, those in task groups under a header
+// row carrying the group's summed-up state. A mapped task lists the same way,
+// its expansions under it by rendered map index. Groups start closed.
 func (v *ExecutionView) renderTaskList(tis []models.TaskInstance) {
 	th := theme.ActiveTheme()
+	ids := v.taskIds()
+	v.rows = groupedRows(ids, v.groupsOf(), v.expanded)
 	v.taskList.Clear()
 	hdr := []string{"Task", "State", "Try"}
 	for i, h := range hdr {
@@ -183,15 +311,61 @@ func (v *ExecutionView) renderTaskList(tis []models.TaskInstance) {
 		}
 		v.taskList.SetCell(0, i, c)
 	}
-	for i, ti := range tis {
+	for i, r := range v.rows {
 		row := i + 1
+		if r.group != "" {
+			state := v.groupState(r)
+			sym, color := th.StatusStyle(state)
+			v.taskList.SetCell(row, 0, tview.NewTableCell(truncate(v.rowLabel(r, ids), 24)).
+				SetTextColor(th.Accent).SetExpansion(1))
+			v.taskList.SetCell(row, 1, tview.NewTableCell(fmt.Sprintf("%s %s", sym, state)).SetTextColor(color))
+			v.taskList.SetCell(row, 2, tview.NewTableCell(""))
+			continue
+		}
+		ti := tis[r.index]
+		name := v.rowLabel(r, ids)
 		sym, color := th.StatusStyle(ti.State)
-		v.taskList.SetCell(row, 0, tview.NewTableCell(truncate(ti.TaskId, 22)).SetExpansion(1))
+		label := tview.NewTableCell(truncate(name, 22)).SetExpansion(1)
+		if _, _, bad := v.anomaly(ti); bad {
+			label.SetText("⚠ " + truncate(name, 20)).SetTextColor(th.StatusPaused)
+		}
+		v.taskList.SetCell(row, 0, label)
 		v.taskList.SetCell(row, 1, tview.NewTableCell(fmt.Sprintf("%s %s", sym, ti.State)).SetTextColor(color))
 		v.taskList.SetCell(row, 2, tview.NewTableCell(fmt.Sprintf("%d", ti.TryNumber)))
 	}
 }
 
+// groupState sums up the states of a group row's instances.
+func (v *ExecutionView) groupState(r groupRow) string {
+	states := make([]string, len(r.members))
+	for i, m := range r.members {
+		states[i] = v.tasks[m].State
+	}
+	return groupState(states)
+}
+
+func (v *ExecutionView) renderRowDetail(r groupRow) {
+	if r.group == "" {
+		v.renderDetail(v.tasks[r.index])
+		return
+	}
+	th := theme.ActiveTheme()
+	state := v.groupState(r)
+	sym, color := th.StatusStyle(state)
+	members := make([]models.TaskInstance, len(r.members))
+	for i, m := range r.members {
+		members[i] = v.tasks[m]
+	}
+	s := summarize(members)
+	kind, count, noun := "Task group", "Tasks", "group"
+	if v.mapped[r.group] {
+		kind, count, noun = "Mapped task", "Expansions", "task"
+	}
+	v.detail.SetText(fmt.Sprintf(
+		"[yellow]%s:[-] %s\n[yellow]State:[-] [%s]%s %s[-]\n[yellow]%s:[-] %d\n[green]%d done[-] - [red]%d failed[-] - [gray]%d queued[-] - %d running\n\n[gray]Enter opens and closes the %s.",
+		kind, tview.Escape(r.group), theme.MarkupHex(color), sym, state, count, s.Total, s.Done, s.Failed, s.Queued, s.Running, noun))
+}
+
 func (v *ExecutionView) renderDetail(ti models.TaskInstance) {
 	start, end := "-", "-"
 	if ti.StartDate != nil && !ti.StartDate.IsZero() {
@@ -200,9 +374,25 @@ func (v *ExecutionView) renderDetail(ti models.TaskInstance) {
 	if ti.EndDate != nil && !ti.EndDate.IsZero() {
 		end = ti.EndDate.Format("01-02 15:04:05")
 	}
-	v.detail.SetText(fmt.Sprintf(
+	text := fmt.Sprintf(
 		"[yellow]Task:[-] %s\n[yellow]State:[-] %s\n[yellow]Operator:[-] %s\n[yellow]Try:[-] %d\n[yellow]Duration:[-] %.1fs\n[yellow]Pool:[-] %s\n[yellow]Queue:[-] %s\n[yellow]Start:[-] %s\n[yellow]End:[-] %s\n[yellow]Host:[-] %s",
-		ti.TaskId, ti.State, ti.Operator, ti.TryNumber, ti.Duration, ti.Pool, ti.Queue, start, end, ti.Hostname))
+		ti.TaskId, ti.State, ti.Operator, ti.TryNumber, ti.Duration, ti.Pool, ti.Queue, start, end, ti.Hostname)
+	if v.mapped[ti.TaskId] {
+		text += fmt.Sprintf("\n[yellow]Map index:[-] %d (%s)", ti.MapIndex, tview.Escape(ti.MapLabel()))
+	}
+	if b, z, bad := v.anomaly(ti); b.Samples > 0 {
+		text += fmt.Sprintf("\n[yellow]Usual:[-] median %s · p95 %s (%d runs)",
+			formatDuration(b.Median), formatDuration(b.P95), b.Samples)
+		if bad {
+			d := metrics.TaskDuration(ti, time.Now())
+			text += fmt.Sprintf("\n[red]⚠ Slow:[-] %s, %s over the median (robust z %s)",
+				formatDuration(d), formatDuration(d-b.Median), formatZ(z))
+		}
+	}
+	if note := strings.TrimSpace(ti.Note); note != "" {
+		text += "\n\n[yellow]Note:[-]\n" + tview.Escape(note)
+	}
+	v.detail.SetText(text)
 }
 
 func (v *ExecutionView) renderMiniDAG() {
@@ -210,13 +400,48 @@ func (v *ExecutionView) renderMiniDAG() {
 	if w <= 0 {
 		w = 30
 	}
-	stateByTask := map[string]string{}
-	for _, ti := range v.tasks {
-		stateByTask[ti.TaskId] = ti.State
+	stateByTask := taskStates(v.tasks)
+	// The task under the list cursor is highlighted with its lineage. Task
+	// groups stay folded except the ones the task sits in.
+	selected := ""
+	open := map[string]bool{}
+	if r, ok := v.currentRow(); ok && r.group != "" {
+		selected = r.group
+		for _, g := range r.parents {
+			open[g] = true
+		}
+	} else if ok {
+		selected = v.tasks[r.index].TaskId
+		for _, d := range v.defs {
+			if d.TaskId == selected {
+				for _, g := range d.Groups {
+					open[g] = true
+				}
+			}
+		}
 	}
-	v.miniDAG.SetText(renderGraph(v.defs, func(id string) NodeState {
+	nodes, members := collapseGroups(v.defs, open)
+	focus := graphFocus{}
+	if selected != "" {
+		levels := topoLevels(nodes)
+		for s, stage := range levels {
+			if slices.Contains(stage, selected) {
+				focus = newGraphFocus(nodes, selected, scrollStages(0, s, len(levels), w))
+				break
+			}
+		}
+	}
+	focus.labels = groupNodeLabels(nodes, members)
+	v.miniDAG.SetText(renderGraph(nodes, func(id string) NodeState {
+		if m, ok := members[id]; ok {
+			states := make([]string, len(m))
+			for i, t := range m {
+				states[i] = stateByTask[t.TaskId]
+			}
+			return NodeStateFromTI(groupState(states))
+		}
 		return NodeStateFromTI(stateByTask[id])
-	}, w))
+	}, w, focus))
 }
 
 func (v *ExecutionView) SetLogs(text string) {
@@ -237,3 +462,11 @@ func (v *ExecutionView) SetLogMessage(msg string) {
 func (v *ExecutionView) SetLogError(msg string) {
 	v.logs.SetText("[red]" + tview.Escape(msg))
 }
+
+// formatZ prints a robust z-score; +Inf (no spread in history) reads as "∞".
+func formatZ(z float64) string {
+	if math.IsInf(z, 1) {
+		return "∞"
+	}
+	return fmt.Sprintf("%.1f", z)
+}
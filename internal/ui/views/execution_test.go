@@ -1,8 +1,13 @@
 package views
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/yjinheon/lazyflow/internal/metrics"
 	"github.com/yjinheon/lazyflow/pkg/airflow/models"
 )
 
@@ -82,3 +87,128 @@ func TestUpdateRunPreservesSelection(t *testing.T) {
 		t.Errorf("selection after run change = %d, want 1 (reset)", r)
 	}
 }
+
+func TestExecutionViewFlagsSlowTasks(t *testing.T) {
+	v := NewExecutionView()
+	run := models.DAGRun{RunId: "r1"}
+	v.UpdateRun(run, []models.TaskInstance{
+		{TaskId: "extract", RunId: "r1", State: "success", Duration: 600},
+		{TaskId: "load", RunId: "r1", State: "success", Duration: 61},
+	}, nil, nil)
+
+	baselines := map[string]metrics.TaskBaseline{
+		"extract": {Samples: 10, Median: time.Minute, P95: 70 * time.Second, MAD: 5 * time.Second},
+		"load":    {Samples: 10, Median: time.Minute, P95: 70 * time.Second, MAD: 5 * time.Second},
+	}
+	v.SetBaselines("other-run", baselines)
+	if strings.Contains(v.taskList.GetCell(1, 0).Text, "⚠") {
+		t.Fatal("baselines for another run must be ignored")
+	}
+
+	v.SetBaselines("r1", baselines)
+	if got := v.taskList.GetCell(1, 0).Text; !strings.Contains(got, "⚠") {
+		t.Fatalf("extract not flagged: %q", got)
+	}
+	if got := v.taskList.GetCell(2, 0).Text; strings.Contains(got, "⚠") {
+		t.Fatalf("load flagged: %q", got)
+	}
+	if detail := v.detail.GetText(true); !strings.Contains(detail, "Slow:") || !strings.Contains(detail, "median 1m0s") {
+		t.Fatalf("detail missing anomaly explanation:\n%s", detail)
+	}
+}
+
+func TestUpdateRunGroupsTasks(t *testing.T) {
+	v := NewExecutionView()
+	tis := []models.TaskInstance{
+		{TaskId: "start", State: "success"},
+		{TaskId: "extract.orders", State: "success"},
+		{TaskId: "extract.users", State: "failed"},
+		{TaskId: "notify"},
+	}
+	v.UpdateRun(models.DAGRun{RunId: "run-1"}, tis, groupedTasks(), nil)
+
+	if got := v.taskList.GetCell(2, 0).Text; got != "▸ extract (2)" {
+		t.Fatalf("row 2 = %q, want the closed extract group", got)
+	}
+	if got := v.taskList.GetCell(2, 1).Text; !strings.Contains(got, "failed") {
+		t.Fatalf("group state = %q, want failed from extract.users", got)
+	}
+	v.taskList.Select(2, 0)
+	if _, ok := v.CurrentTask(); ok {
+		t.Fatal("a group row is not a task")
+	}
+
+	var picked string
+	v.SetOnTaskSelected(func(id string) { picked = id })
+	enter := func() {
+		v.taskList.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(tview.Primitive) {})
+	}
+	enter()
+	if picked != "" || v.taskList.GetRowCount() != 1+5 {
+		t.Fatalf("Enter on a group should open it: %d rows, picked %q", v.taskList.GetRowCount()-1, picked)
+	}
+	v.taskList.Select(4, 0)
+	enter()
+	if picked != "extract.users" {
+		t.Fatalf("picked %q", picked)
+	}
+
+	// A refresh keeps the group open and the cursor on its task.
+	v.UpdateRun(models.DAGRun{RunId: "run-1"}, tis, groupedTasks(), nil)
+	if ti, ok := v.CurrentTask(); !ok || ti.TaskId != "extract.users" {
+		t.Fatalf("refresh moved the cursor to %q", ti.TaskId)
+	}
+}
+
+func TestUpdateRunListsMappedExpansions(t *testing.T) {
+	v := NewExecutionView()
+	defs := []models.Task{
+		{TaskId: "fetch"},
+		{TaskId: "render", UpstreamTaskIds: []string{"fetch"}, IsMapped: true},
+	}
+	tis := []models.TaskInstance{
+		{TaskId: "fetch", State: "success", MapIndex: models.NotMapped},
+		{TaskId: "render", State: "success", MapIndex: 0, RenderedMapIndex: "emea"},
+		{TaskId: "render", State: "running", MapIndex: 1, TryNumber: 2},
+	}
+	v.UpdateRun(models.DAGRun{RunId: "run-1"}, tis, defs, nil)
+
+	if got := v.taskList.GetCell(2, 0).Text; got != "▸ render ×2" {
+		t.Fatalf("row 2 = %q, want the closed mapped task", got)
+	}
+	if got := v.taskList.GetCell(2, 1).Text; !strings.Contains(got, "running") {
+		t.Fatalf("mapped task state = %q, want running from index 1", got)
+	}
+	v.taskList.Select(2, 0)
+	if v.CurrentMapIndex() != models.NotMapped {
+		t.Fatal("the mapped task's own row is no expansion")
+	}
+
+	var picked string
+	v.SetOnTaskSelected(func(id string) { picked = id })
+	enter := func() {
+		v.taskList.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(tview.Primitive) {})
+	}
+	enter()
+	var rows []string
+	for r := 1; r < v.taskList.GetRowCount(); r++ {
+		rows = append(rows, v.taskList.GetCell(r, 0).Text+" "+v.taskList.GetCell(r, 2).Text)
+	}
+	if got := strings.Join(rows, "|"); got != "fetch 0|▾ render ×2 |  emea 0|  1 2" {
+		t.Fatalf("open = %s", got)
+	}
+	v.taskList.Select(4, 0)
+	enter()
+	if picked != "render" || v.CurrentMapIndex() != 1 {
+		t.Fatalf("picked %q map index %d", picked, v.CurrentMapIndex())
+	}
+	if !strings.Contains(v.detail.GetText(true), "Map index: 1 (1)") {
+		t.Fatalf("detail:\n%s", v.detail.GetText(true))
+	}
+
+	// A refresh keeps the cursor on the same expansion.
+	v.UpdateRun(models.DAGRun{RunId: "run-1"}, tis, defs, nil)
+	if ti, ok := v.CurrentTask(); !ok || ti.MapIndex != 1 {
+		t.Fatalf("refresh moved the cursor to %+v", ti)
+	}
+}
@@ -0,0 +1,205 @@
+package views
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/yjinheon/lazyflow/internal/cache"
+	"github.com/yjinheon/lazyflow/internal/ui/theme"
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+// fleetSort is one ordering of the fleet table. Ties fall back to DAG id.
+type fleetSort struct {
+	label string
+	less  func(a, b cache.DagDashboardRow) bool
+}
+
+// fleetSorts is the s-key cycle; the first entry (failures first) matches the
+// order GetDagDashboardRows already returns.
+var fleetSorts = []fleetSort{
+	{"failed", func(a, b cache.DagDashboardRow) bool { return a.Failed > b.Failed }},
+	{"success rate", func(a, b cache.DagDashboardRow) bool { return fleetRate(a) < fleetRate(b) }},
+	{"runs", func(a, b cache.DagDashboardRow) bool { return a.Runs > b.Runs }},
+	{"avg duration", func(a, b cache.DagDashboardRow) bool { return a.AvgDuration > b.AvgDuration }},
+	{"max duration", func(a, b cache.DagDashboardRow) bool { return a.MaxDuration > b.MaxDuration }},
+	{"queue avg", func(a, b cache.DagDashboardRow) bool { return a.AvgQueueTime > b.AvgQueueTime }},
+	{"failed tasks", func(a, b cache.DagDashboardRow) bool { return a.FailedTasks > b.FailedTasks }},
+	{"dag", func(a, b cache.DagDashboardRow) bool { return a.DagId < b.DagId }},
+}
+
+// fleetRate is the success share of terminal runs; DAGs with none sort last
+// when ordering worst-first.
+func fleetRate(r cache.DagDashboardRow) float64 {
+	if r.Success+r.Failed == 0 {
+		return 2
+	}
+	return float64(r.Success) / float64(r.Success+r.Failed)
+}
+
+func newFleetTable() *tview.Table {
+	t := tview.NewTable().SetFixed(1, 1).SetSelectable(false, false)
+	t.SetSelectedStyle(tcell.StyleDefault.
+		Background(theme.ActiveTheme().TableSelected).
+		Foreground(theme.ActiveTheme().PrimaryText).
+		Attributes(tcell.AttrBold))
+	return t
+}
+
+// FleetMode reports whether the monitor shows the cluster-wide DAG table:
+// always before a DAG is selected, and after F toggles it on. Safe to call
+// from pollers.
+func (v *MonitorView) FleetMode() bool { return v.fleetMode.Load() }
+
+// syncFleetMode republishes FleetMode after dagID or showFleet change.
+func (v *MonitorView) syncFleetMode() { v.fleetMode.Store(v.showFleet || v.dagID == "") }
+
+// ToggleFleet switches between the fleet table and the selected DAG's
+// dashboard. Without a selected DAG the fleet table stays up.
+func (v *MonitorView) ToggleFleet() {
+	v.showFleet = !v.showFleet && v.dagID != ""
+	v.syncFleetMode()
+	v.renderSnapshot(80, 10)
+	v.rebuildLayout()
+}
+
+// SetOnFleetSelected registers the callback fired when Enter is pressed on a
+// fleet row. The view leaves fleet mode first so the drill-down shows.
+func (v *MonitorView) SetOnFleetSelected(fn func(dagId string)) { v.onFleetSelected = fn }
+
+// CycleFleetSort moves the fleet table to the next sort column.
+func (v *MonitorView) CycleFleetSort() {
+	v.fleetSort = (v.fleetSort + 1) % len(fleetSorts)
+	v.sortFleet()
+	v.renderFleet()
+}
+
+// SetFleetLoading marks the fleet table as refreshing, keeping any rows.
+func (v *MonitorView) SetFleetLoading() {
+	v.fleetState = "loading"
+	v.renderFleet()
+	v.rebuildLayout()
+}
+
+// UpdateFleet replaces the fleet aggregates. runs supplies each DAG's recent
+// outcomes for the sparkline column.
+func (v *MonitorView) UpdateFleet(rows []cache.DagDashboardRow, runs []models.DAGRun) {
+	v.fleetRows = append(v.fleetRows[:0], rows...)
+	v.fleetRuns = make(map[string][]models.DAGRun)
+	for _, r := range runs {
+		v.fleetRuns[r.DagId] = append(v.fleetRuns[r.DagId], r)
+	}
+	v.fleetState = ""
+	v.sortFleet()
+	v.renderFleet()
+	v.rebuildLayout()
+}
+
+func (v *MonitorView) sortFleet() {
+	less := fleetSorts[v.fleetSort].less
+	sort.SliceStable(v.fleetRows, func(i, j int) bool {
+		a, b := v.fleetRows[i], v.fleetRows[j]
+		if less(a, b) != less(b, a) {
+			return less(a, b)
+		}
+		return a.DagId < b.DagId
+	})
+}
+
+func (v *MonitorView) renderFleet() {
+	badge := ""
+	if v.fleetState == "loading" {
+		badge = "    [blue]updating…[-]"
+	}
+	back := ""
+	if v.dagID != "" {
+		back = fmt.Sprintf("  [yellow]F[-][gray]:back to %s[-]", tview.Escape(v.dagID))
+	}
+	v.fleetHeader.SetText(fmt.Sprintf(
+		" [white::b]All DAGs[-::-]    [gray]window:[-] %s    [gray]sort:[-] [blue]%s[-]%s\n [yellow]s[-][gray]:sort  [yellow]Enter[-][gray]:open DAG[-]%s",
+		v.windowSelector(), fleetSorts[v.fleetSort].label, badge, back))
+
+	t := v.fleet
+	th := theme.ActiveTheme()
+	row, col := t.GetSelection()
+	t.Clear()
+	headers := []string{"DAG", "Runs", "Success", "Failed", "Running", "Avg", "Max", "Queue", "✗ Tasks", "Retries", "Last", "Recent"}
+	for i, h := range headers {
+		cell := tview.NewTableCell(h).SetTextColor(th.TableHeaderText).SetSelectable(false)
+		if i == len(headers)-1 {
+			cell.SetExpansion(1)
+		}
+		t.SetCell(0, i, cell)
+	}
+	if len(v.fleetRows) == 0 {
+		t.SetSelectable(false, false)
+		msg := "No run history in this window — visit DAGs or wait for the rollup poll to fill the cache."
+		if v.fleetState == "loading" {
+			msg = "Loading history…"
+		}
+		setEmptyHint(t, msg)
+		return
+	}
+	t.SetSelectable(true, false)
+
+	for i, r := range v.fleetRows {
+		rowIdx := i + 1
+		bg := th.PrimaryBg
+		if rowIdx%2 == 0 {
+			bg = th.TableRowAlt
+		}
+		rate, rateColor := "—", th.MutedText
+		if terminal := r.Success + r.Failed; terminal > 0 {
+			pct := r.Success * 100 / terminal
+			rate = fmt.Sprintf("%d%%", pct)
+			switch {
+			case pct >= 95:
+				rateColor = th.StatusSuccess
+			case pct >= 80:
+				rateColor = th.StatusPaused
+			default:
+				rateColor = th.StatusFailed
+			}
+		}
+		failedColor := th.PrimaryText
+		if r.Failed > 0 {
+			failedColor = th.StatusFailed
+		}
+		_, lastColor := th.StatusStyle(r.LastState)
+		cells := []struct {
+			text  string
+			color tcell.Color
+		}{
+			{tview.Escape(r.DagId), th.PrimaryText},
+			{fmt.Sprintf("%d", r.Runs), th.PrimaryText},
+			{rate, rateColor},
+			{fmt.Sprintf("%d", r.Failed), failedColor},
+			{fmt.Sprintf("%d", r.Running), th.PrimaryText},
+			{observedDuration(r.AvgDuration), th.PrimaryText},
+			{observedDuration(r.MaxDuration), th.PrimaryText},
+			{observedDuration(r.AvgQueueTime), th.PrimaryText},
+			{fmt.Sprintf("%d", r.FailedTasks), th.PrimaryText},
+			{fmt.Sprintf("%d", r.RetriedTasks), th.PrimaryText},
+			{r.LastState, lastColor},
+			{renderSparkline(v.fleetRuns[r.DagId]), th.PrimaryText},
+		}
+		for c, cell := range cells {
+			t.SetCell(rowIdx, c, tview.NewTableCell(cell.text).SetTextColor(cell.color).SetBackgroundColor(bg))
+		}
+	}
+	if row < 1 {
+		row = 1
+	}
+	t.Select(min(row, len(v.fleetRows)), col)
+}
+
+// InputHandler forwards keys to the fleet table while it is shown so j/k and
+// Enter work on it; the per-DAG dashboard has nothing selectable.
+func (v *MonitorView) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	if v.FleetMode() {
+		return v.fleet.InputHandler()
+	}
+	return v.Flex.InputHandler()
+}
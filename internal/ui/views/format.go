@@ -2,6 +2,7 @@ package views
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -28,3 +29,22 @@ func formatDuration(d time.Duration) string {
 	m := int(d.Minutes()) % 60
 	return fmt.Sprintf("%dh%dm", h, m)
 }
+
+// notePreview squeezes a (possibly multi-line) note into one table cell: the
+// first line, cut to n runes, with "…" when anything was dropped.
+func notePreview(note string, n int) string {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return ""
+	}
+	line, rest, multi := strings.Cut(note, "\n")
+	line = strings.TrimSpace(line)
+	r := []rune(line)
+	if len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	if multi && strings.TrimSpace(rest) != "" {
+		return line + " …"
+	}
+	return line
+}
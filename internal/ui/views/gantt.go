@@ -5,75 +5,401 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+	"github.com/yjinheon/lazyflow/internal/metrics"
 	"github.com/yjinheon/lazyflow/internal/ui/theme"
 	"github.com/yjinheon/lazyflow/pkg/airflow/models"
 )
 
 // GanttView renders a per-run Gantt chart inside a tview.TextView.
-// The renderer functions are pure (gantt_renderer.go); this view owns
-// only the markup assembly and the TextView container.
+// The renderer functions are pure (gantt_renderer.go); this view owns the
+// markup assembly, the row cursor and the visible time window.
+//
+// Zoom halves the window per step around the selected task; pan slides it
+// by a quarter. Ghost bars, once timings are supplied, draw each task's
+// median start and end from the history cache behind its real bar.
 type GanttView struct {
 	*tview.TextView
+
+	runId    string
+	tis      []models.TaskInstance // sorted by task id
+	mapped   map[string]bool       // tasks drawn one row per expansion
+	critical map[string]bool
+	timings  map[string]metrics.TaskTiming
+	timedRun string // run the timings were computed for
+	ghosts   bool
+
+	cursor int           // index into tis
+	top    int           // first task row on screen
+	zoom   int           // 1 shows the whole run; doubles per step
+	offset time.Duration // window's left edge, from the run's first queue
+
+	onSelected     func(taskId string)
+	drawnW, drawnH int
 }
 
+const (
+	ganttLabelCol = 25
+	maxGanttZoom  = 1024
+	// minGanttBucket stops zooming in once a column is this short.
+	minGanttBucket = 100 * time.Millisecond
+)
+
 func NewGanttView() *GanttView {
 	tv := tview.NewTextView().
 		SetDynamicColors(true).
 		SetScrollable(true).
 		SetWrap(false)
 	tv.SetBorder(true).SetTitle(" Gantt ")
-	return &GanttView{TextView: tv}
+	return &GanttView{TextView: tv, zoom: 1}
 }
 
+// SetOnSelected is called with the task under the cursor on Enter.
+func (v *GanttView) SetOnSelected(fn func(taskId string)) { v.onSelected = fn }
+
 // Update redraws the Gantt for the given run.
 // onCritical (task_id → bool) is used for critical-path bold highlighting;
-// pass nil or empty map to disable highlighting.
+// pass nil or empty map to disable highlighting. The cursor, zoom and pan
+// survive refreshes of the same run and reset for a different one.
 func (v *GanttView) Update(runId string, tis []models.TaskInstance, onCritical map[string]bool) {
-	if len(tis) == 0 {
-		if runId == "" {
+	prev := ""
+	if runId == v.runId {
+		if ti, ok := v.CurrentTask(); ok {
+			prev = instanceKey(ti, v.mapped)
+		}
+	} else {
+		v.runId = runId
+		v.cursor, v.top, v.zoom, v.offset = 0, 0, 1, 0
+	}
+	// Sort tasks alphabetically by task_id for deterministic order.
+	v.tis = append([]models.TaskInstance(nil), tis...)
+	sortByTaskID(v.tis)
+	v.mapped = mappedTaskIds(v.tis, nil)
+	v.critical = onCritical
+	for i, ti := range v.tis {
+		if instanceKey(ti, v.mapped) == prev {
+			v.cursor = i
+		}
+	}
+	v.cursor = min(v.cursor, max(len(v.tis)-1, 0))
+	v.render()
+}
+
+// SetTimings supplies each task's historical position in its run (see
+// metrics.TaskTimings) for the ghost bars. They may arrive before the run is
+// first drawn, and are ignored while a different run is shown.
+func (v *GanttView) SetTimings(runId string, timings map[string]metrics.TaskTiming) {
+	v.timings, v.timedRun = timings, runId
+	if runId == v.runId && v.ghosts {
+		v.render()
+	}
+}
+
+// ghostTimings returns the timings to draw: none while ghosts are off or
+// the timings belong to another run.
+func (v *GanttView) ghostTimings() map[string]metrics.TaskTiming {
+	if !v.ghosts || v.timedRun != v.runId {
+		return nil
+	}
+	return v.timings
+}
+
+// ToggleGhosts shows or hides the historical ghost bars.
+func (v *GanttView) ToggleGhosts() {
+	v.ghosts = !v.ghosts
+	v.render()
+}
+
+// CurrentTask returns the task instance under the cursor.
+func (v *GanttView) CurrentTask() (models.TaskInstance, bool) {
+	if v.cursor < 0 || v.cursor >= len(v.tis) {
+		return models.TaskInstance{}, false
+	}
+	return v.tis[v.cursor], true
+}
+
+// CurrentMapIndex is the map index of the expansion under the cursor;
+// models.NotMapped on an unmapped task.
+func (v *GanttView) CurrentMapIndex() int {
+	ti, ok := v.CurrentTask()
+	if !ok || !v.mapped[ti.TaskId] {
+		return models.NotMapped
+	}
+	return ti.MapIndex
+}
+
+// Window returns the time range currently on screen.
+func (v *GanttView) Window() (from, to time.Time) {
+	tMin, tMax := v.span(time.Now())
+	if tMin.IsZero() {
+		return time.Time{}, time.Time{}
+	}
+	width := v.windowWidth(tMax.Sub(tMin))
+	from = tMin.Add(v.offset)
+	return from, from.Add(width)
+}
+
+func (v *GanttView) render() {
+	_, _, innerW, innerH := v.GetInnerRect()
+	v.drawnW, v.drawnH = innerW, innerH
+	if len(v.tis) == 0 {
+		if v.runId == "" {
 			v.SetText("[gray]Select a DAG run to view task-instance timing.")
 			return
 		}
 		v.SetText("[gray]No tasks have started yet.")
 		return
 	}
-	_, _, innerW, _ := v.GetInnerRect()
-	const labelCol = 25
-	barW := innerW - labelCol - 1
+	barW := innerW - ganttLabelCol - 1
 	if barW < 10 {
-		v.SetText(fmt.Sprintf("[gray]Terminal too narrow (need >=%d cols).", labelCol+11))
+		v.SetText(fmt.Sprintf("[gray]Terminal too narrow (need >=%d cols).", ganttLabelCol+11))
 		return
 	}
 
 	now := time.Now()
-	buckets, tMin, tMax := ComputeBuckets(tis, barW, now)
-	if buckets == nil {
+	tMin, tMax := v.span(now)
+	if tMin.IsZero() {
 		v.SetText("[gray]No tasks have started yet.")
 		return
 	}
+	full := tMax.Sub(tMin)
+	v.zoom = min(v.zoom, v.maxZoom(full, barW))
+	width := v.windowWidth(full)
+	v.offset = max(min(v.offset, full-width), 0)
+	from, to := tMin.Add(v.offset), tMin.Add(v.offset+width)
+	buckets := WindowBuckets(from, to, barW)
 
 	var b strings.Builder
-	fmt.Fprintf(&b, "[gray]Gantt -- %s   %s -> %s   [g] back to table\n",
-		runId, formatTick(tMin, tMax), formatTick(tMax, tMax))
+	fmt.Fprintf(&b, "[gray]Gantt -- %s   %s -> %s", v.runId, formatTick(from, to), formatTick(to, to))
+	if v.zoom > 1 {
+		fmt.Fprintf(&b, "   zoom %dx", v.zoom)
+	}
+	if v.ghosts {
+		if len(v.ghostTimings()) == 0 {
+			b.WriteString("   ghosts: no history")
+		} else {
+			b.WriteString("   ghosts: median")
+		}
+	}
+	b.WriteString(tview.Escape("   [+/-/z] zoom  [h/l] pan  [e] ghosts  [Enter] logs  [g] back to table") + "\n")
 
-	// Sort tasks alphabetically by task_id for deterministic order.
-	sorted := append([]models.TaskInstance(nil), tis...)
-	sortByTaskID(sorted)
+	rows := max(innerH-2, 1) // header and axis stay put
+	if v.cursor < v.top {
+		v.top = v.cursor
+	} else if v.cursor >= v.top+rows {
+		v.top = v.cursor - rows + 1
+	}
+	v.top = max(min(v.top, len(v.tis)-rows), 0)
 
-	for _, ti := range sorted {
+	for i := v.top; i < len(v.tis) && i < v.top+rows; i++ {
+		ti := v.tis[i]
 		cells := RenderCells(ti, buckets, now)
-		bold := onCritical[ti.TaskId]
-		label := truncate(ti.TaskId, labelCol-2)
-		fmt.Fprintf(&b, "%-*s ", labelCol-1, label)
-		b.WriteString(EmitRLE(cells, bold))
+		if t, ok := v.ghostTimings()[ti.TaskId]; ok {
+			OverlayGhost(cells, buckets, tMin.Add(t.Start), tMin.Add(t.End))
+		}
+		markOffscreen(cells, ti, from, to, now)
+		name := ti.TaskId
+		if v.mapped[ti.TaskId] {
+			name += "[" + ti.MapLabel() + "]"
+		}
+		label := tview.Escape(fmt.Sprintf("%-*s", ganttLabelCol-1, truncate(name, ganttLabelCol-2)))
+		if i == v.cursor {
+			label = "[::r]" + label + "[::-]"
+		}
+		b.WriteString(label + " ")
+		b.WriteString(EmitRLE(cells, v.critical[ti.TaskId]))
 		b.WriteByte('\n')
 	}
-	b.WriteString(renderXAxis(tMin, tMax, barW, labelCol))
+	b.WriteString(renderXAxis(from, to, barW, ganttLabelCol))
 	v.SetText(b.String())
+	v.ScrollToBeginning()
+}
+
+// span is the whole run: from the first queued task to the last end, or now
+// while anything is unfinished, stretched to fit the ghosts when shown.
+func (v *GanttView) span(now time.Time) (time.Time, time.Time) {
+	tMin := earliestQueued(v.tis)
+	if tMin.IsZero() {
+		return time.Time{}, time.Time{}
+	}
+	tMax := tMin
+	for _, ti := range v.tis {
+		_, end := taskInterval(ti, now)
+		if end.After(tMax) {
+			tMax = end
+		}
+	}
+	for _, ti := range v.tis {
+		if t, ok := v.ghostTimings()[ti.TaskId]; ok && tMin.Add(t.End).After(tMax) {
+			tMax = tMin.Add(t.End)
+		}
+	}
+	if !tMax.After(tMin) {
+		tMax = tMin.Add(time.Second)
+	}
+	return tMin, tMax
+}
+
+func (v *GanttView) windowWidth(full time.Duration) time.Duration {
+	return full / time.Duration(max(v.zoom, 1))
+}
+
+func (v *GanttView) maxZoom(full time.Duration, barW int) int {
+	z := 1
+	for z < maxGanttZoom && full/time.Duration(z*2) >= minGanttBucket*time.Duration(barW) {
+		z *= 2
+	}
+	return z
+}
+
+// taskInterval is the part of a task drawn as a bar: queued (or started) to
+// ended, or to now while unfinished. Zero when it has not been queued.
+func taskInterval(ti models.TaskInstance, now time.Time) (time.Time, time.Time) {
+	start := deref(ti.QueuedDttm)
+	if start.IsZero() {
+		start = deref(ti.StartDate)
+	}
+	if start.IsZero() {
+		return time.Time{}, time.Time{}
+	}
+	end := deref(ti.EndDate)
+	if end.IsZero() {
+		end = now
+	}
+	return start, end
+}
+
+// markOffscreen puts an arrow at the edge a bar lies beyond when zoomed in.
+func markOffscreen(cells []Cell, ti models.TaskInstance, from, to, now time.Time) {
+	start, end := taskInterval(ti, now)
+	if start.IsZero() || len(cells) == 0 {
+		return
+	}
+	switch {
+	case end.Before(from):
+		cells[0] = Cell{Char: '◀', State: "run", Color: stateColor(ti.State)}
+	case start.After(to):
+		cells[len(cells)-1] = Cell{Char: '▶', State: "run", Color: stateColor(ti.State)}
+	}
+}
+
+// zoomBy doubles (in > 0) or halves the zoom. Zooming in centres on the
+// selected task's bar when there is one.
+func (v *GanttView) zoomBy(in int) {
+	now := time.Now()
+	tMin, tMax := v.span(now)
+	if tMin.IsZero() {
+		return
+	}
+	full := tMax.Sub(tMin)
+	width := v.windowWidth(full)
+	centre := v.offset + width/2
+	if ti, ok := v.CurrentTask(); ok && in > 0 {
+		if s, e := taskInterval(ti, now); !s.IsZero() {
+			centre = s.Add(e.Sub(s) / 2).Sub(tMin)
+		}
+	}
+	if in > 0 {
+		_, _, innerW, _ := v.GetInnerRect()
+		v.zoom = min(v.zoom*2, v.maxZoom(full, max(innerW-ganttLabelCol-1, 10)))
+	} else {
+		v.zoom = max(v.zoom/2, 1)
+	}
+	v.offset = centre - v.windowWidth(full)/2
+	v.render()
+}
+
+// pan slides the window by a quarter of its width.
+func (v *GanttView) pan(dir int) {
+	tMin, tMax := v.span(time.Now())
+	if tMin.IsZero() || v.zoom == 1 {
+		return
+	}
+	v.offset += time.Duration(dir) * v.windowWidth(tMax.Sub(tMin)) / 4
+	v.render() // clamps the offset
+}
+
+// moveCursor selects another row and, when zoomed, brings its bar into view.
+func (v *GanttView) moveCursor(row int) {
+	if len(v.tis) == 0 {
+		return
+	}
+	v.cursor = max(min(row, len(v.tis)-1), 0)
+	if v.zoom > 1 {
+		now := time.Now()
+		from, to := v.Window()
+		if s, e := taskInterval(v.tis[v.cursor], now); !s.IsZero() && (e.Before(from) || s.After(to)) {
+			tMin, _ := v.span(now)
+			v.offset = s.Sub(tMin) - to.Sub(from)/4
+		}
+	}
+	v.render()
+}
+
+// InputHandler drives the cursor, zoom and pan; the TextView's own
+// scrolling is not used because rows are laid out here.
+func (v *GanttView) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return v.WrapInputHandler(func(event *tcell.EventKey, _ func(p tview.Primitive)) {
+		_, _, _, innerH := v.GetInnerRect()
+		page := max(innerH-2, 1)
+		switch event.Key() {
+		case tcell.KeyUp:
+			v.moveCursor(v.cursor - 1)
+		case tcell.KeyDown:
+			v.moveCursor(v.cursor + 1)
+		case tcell.KeyPgUp:
+			v.moveCursor(v.cursor - page)
+		case tcell.KeyPgDn:
+			v.moveCursor(v.cursor + page)
+		case tcell.KeyHome:
+			v.moveCursor(0)
+		case tcell.KeyEnd:
+			v.moveCursor(len(v.tis) - 1)
+		case tcell.KeyLeft:
+			v.pan(-1)
+		case tcell.KeyRight:
+			v.pan(1)
+		case tcell.KeyEnter:
+			if ti, ok := v.CurrentTask(); ok && v.onSelected != nil {
+				v.onSelected(ti.TaskId)
+			}
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'k':
+				v.moveCursor(v.cursor - 1)
+			case 'j':
+				v.moveCursor(v.cursor + 1)
+			case 'G':
+				v.moveCursor(len(v.tis) - 1)
+			case 'h':
+				v.pan(-1)
+			case 'l':
+				v.pan(1)
+			case '+', '=':
+				v.zoomBy(1)
+			case '-', '_':
+				v.zoomBy(-1)
+			case 'z':
+				v.zoom, v.offset = 1, 0
+				v.render()
+			case 'e':
+				v.ToggleGhosts()
+			}
+		}
+	})
+}
+
+// Draw re-lays the chart when the pane is resized; bar widths and the
+// number of visible rows depend on it.
+func (v *GanttView) Draw(screen tcell.Screen) {
+	if _, _, w, h := v.GetInnerRect(); w != v.drawnW || h != v.drawnH {
+		v.render()
+	}
+	v.TextView.Draw(screen)
 }
 
-func (v *GanttView) Root() tview.Primitive { return v.TextView }
+func (v *GanttView) Root() tview.Primitive { return v }
 
 func truncate(s string, n int) string {
 	if len(s) <= n {
@@ -15,9 +15,10 @@ func ComputeCriticalPath(tasks []models.Task, tis []models.TaskInstance, now tim
 		return map[string]bool{}
 	}
 
+	// The expansions of a mapped task run side by side; the longest counts.
 	durByID := make(map[string]time.Duration, len(tis))
 	for _, ti := range tis {
-		durByID[ti.TaskId] = effectiveDuration(ti, now)
+		durByID[ti.TaskId] = max(durByID[ti.TaskId], effectiveDuration(ti, now))
 	}
 
 	tByID := make(map[string]models.Task, len(tasks))
@@ -17,7 +17,7 @@ type Bucket struct {
 // Cell is one rendered character.
 type Cell struct {
 	Char  rune
-	State string // "queue" | "run" | "empty"
+	State string // "queue" | "run" | "ghost" | "empty"
 	Color string // theme color token; empty = no markup
 }
 
@@ -42,17 +42,26 @@ func ComputeBuckets(tis []models.TaskInstance, width int, tMax time.Time) ([]Buc
 	if tMax.IsZero() || !tMax.After(tMin) {
 		tMax = tMin.Add(time.Second)
 	}
-	step := tMax.Sub(tMin) / time.Duration(width)
+	return WindowBuckets(tMin, tMax, width), tMin, tMax
+}
+
+// WindowBuckets divides [from..to] into `width` equal slices; the zoomed
+// Gantt passes the visible window rather than the whole run.
+func WindowBuckets(from, to time.Time, width int) []Bucket {
+	if width <= 0 || !to.After(from) {
+		return nil
+	}
+	step := to.Sub(from) / time.Duration(width)
 	buckets := make([]Bucket, width)
 	for i := range width {
-		buckets[i].Start = tMin.Add(step * time.Duration(i))
+		buckets[i].Start = from.Add(step * time.Duration(i))
 		if i == width-1 {
-			buckets[i].End = tMax
+			buckets[i].End = to
 		} else {
-			buckets[i].End = tMin.Add(step * time.Duration(i+1))
+			buckets[i].End = from.Add(step * time.Duration(i+1))
 		}
 	}
-	return buckets, tMin, tMax
+	return buckets
 }
 
 func earliestQueued(tis []models.TaskInstance) time.Time {
@@ -93,6 +102,17 @@ func RenderCells(ti models.TaskInstance, buckets []Bucket, now time.Time) []Cell
 	return out
 }
 
+// OverlayGhost draws a task's typical [start..end] into the cells the real
+// bars leave empty, so a run that overshoots or finishes early shows against
+// its history.
+func OverlayGhost(cells []Cell, buckets []Bucket, start, end time.Time) {
+	for i, b := range buckets {
+		if i < len(cells) && cells[i].State == "empty" && overlap(b.Start, b.End, start, end) {
+			cells[i] = Cell{Char: '░', State: "ghost", Color: "ghost"}
+		}
+	}
+}
+
 func overlap(aStart, aEnd, bStart, bEnd time.Time) bool {
 	if bStart.IsZero() || bEnd.IsZero() {
 		return false
@@ -0,0 +1,108 @@
+package views
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/yjinheon/lazyflow/internal/metrics"
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+func ganttKey(v *GanttView, key tcell.Key, r rune) {
+	v.InputHandler()(tcell.NewEventKey(key, r, tcell.ModNone), func(tview.Primitive) {})
+}
+
+// A six-hour run with a ten-second task: the short bar must become visible
+// by zooming, and Enter must hand over the task under the cursor.
+func TestGanttView_zoomPanAndSelect(t *testing.T) {
+	base := time.Date(2026, 5, 23, 4, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time { v := base.Add(d); return &v }
+	tis := []models.TaskInstance{
+		{TaskId: "long", State: "success", QueuedDttm: at(0), StartDate: at(0), EndDate: at(6 * time.Hour)},
+		{TaskId: "short", State: "success", QueuedDttm: at(3 * time.Hour), StartDate: at(3 * time.Hour), EndDate: at(3*time.Hour + 10*time.Second)},
+	}
+	v := NewGanttView()
+	v.SetRect(0, 0, 127, 12) // 100 bar columns
+	v.Update("run-1", tis, nil)
+
+	if from, to := v.Window(); to.Sub(from) != 6*time.Hour {
+		t.Fatalf("initial window = %s, want the whole run", to.Sub(from))
+	}
+
+	ganttKey(v, tcell.KeyDown, 0)
+	if ti, _ := v.CurrentTask(); ti.TaskId != "short" {
+		t.Fatalf("cursor on %s, want short", ti.TaskId)
+	}
+	for range 6 {
+		ganttKey(v, tcell.KeyRune, '+')
+	}
+	from, to := v.Window()
+	if to.Sub(from) != 6*time.Hour/64 {
+		t.Fatalf("window after 6 zooms = %s", to.Sub(from))
+	}
+	if mid := base.Add(3*time.Hour + 5*time.Second); mid.Before(from) || mid.After(to) {
+		t.Fatalf("zoom should centre on the selected task, window %s..%s", from, to)
+	}
+	if !strings.Contains(v.GetText(false), "zoom 64x") {
+		t.Fatal("header should show the zoom level")
+	}
+
+	ganttKey(v, tcell.KeyRune, 'l')
+	if f, _ := v.Window(); f.Sub(from) != to.Sub(from)/4 {
+		t.Fatalf("pan moved %s, want a quarter window", f.Sub(from))
+	}
+	ganttKey(v, tcell.KeyRune, 'z')
+	if f, tt := v.Window(); tt.Sub(f) != 6*time.Hour {
+		t.Fatal("z should fit the whole run again")
+	}
+
+	var selected string
+	v.SetOnSelected(func(id string) { selected = id })
+	ganttKey(v, tcell.KeyEnter, 0)
+	if selected != "short" {
+		t.Fatalf("Enter selected %q", selected)
+	}
+
+	// A refresh of the same run keeps the cursor; another run resets it.
+	v.Update("run-1", tis, nil)
+	if ti, _ := v.CurrentTask(); ti.TaskId != "short" {
+		t.Fatal("refresh moved the cursor")
+	}
+	v.Update("run-2", tis, nil)
+	if ti, _ := v.CurrentTask(); ti.TaskId != "long" {
+		t.Fatal("a new run should start at the first row")
+	}
+}
+
+func TestGanttView_ghosts(t *testing.T) {
+	base := time.Date(2026, 5, 23, 4, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time { v := base.Add(d); return &v }
+	tis := []models.TaskInstance{
+		{TaskId: "load", State: "success", QueuedDttm: at(0), StartDate: at(0), EndDate: at(time.Minute)},
+	}
+	v := NewGanttView()
+	v.SetRect(0, 0, 127, 12)
+	v.SetTimings("run-1", map[string]metrics.TaskTiming{"load": {Samples: 5, Start: 0, End: 3 * time.Minute}})
+	v.Update("run-1", tis, nil)
+	if strings.Contains(v.GetText(false), "░") {
+		t.Fatal("ghosts are off by default")
+	}
+
+	ganttKey(v, tcell.KeyRune, 'e')
+	if from, to := v.Window(); to.Sub(from) != 3*time.Minute {
+		t.Fatalf("window %s should stretch to the ghost's end", to.Sub(from))
+	}
+	if got := strings.Count(v.GetText(true), "░"); got < 60 {
+		t.Fatalf("ghost should fill the two thirds the real bar leaves, got %d cells", got)
+	}
+
+	// Timings of another run are not drawn.
+	v.SetTimings("run-0", map[string]metrics.TaskTiming{"load": {Samples: 5, End: time.Hour}})
+	v.Update("run-1", tis, nil)
+	if !strings.Contains(v.GetText(false), "ghosts: no history") {
+		t.Fatal("stale timings should not be drawn")
+	}
+}
@@ -0,0 +1,287 @@
+package views
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/yjinheon/lazyflow/internal/ui/theme"
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+// GraphView is the navigable DAG graph: tasks in topological stages with a
+// cursor that follows edges. Left and right step to a direct upstream or
+// downstream task, up and down to the neighbours in the same stage. The
+// selected task's lineage is highlighted and everything else dimmed. Task
+// groups start collapsed into one node each and open with space.
+type GraphView struct {
+	*tview.TextView
+
+	all      []models.Task
+	expanded map[string]bool
+	tasks    []models.Task            // all, with collapsed groups folded
+	members  map[string][]models.Task // group node → its tasks
+	levels   [][]string
+	pos      map[string][2]int // task id → stage, row
+	states   map[string]string
+	selected string
+	first    int // leftmost stage on screen
+
+	onSelected     func(taskId string)
+	onChanged      func(taskId string)
+	drawnW, drawnH int
+}
+
+func NewGraphView() *GraphView {
+	tv := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(false)
+	tv.SetBorder(true).SetTitle(" DAG Graph (g: tree) ")
+	return &GraphView{TextView: tv, expanded: map[string]bool{}}
+}
+
+// SetOnSelected is called with the task under the cursor on Enter; Enter on
+// a group node opens the group instead.
+func (v *GraphView) SetOnSelected(fn func(taskId string)) { v.onSelected = fn }
+
+// SetOnChanged is called whenever the cursor lands on another task.
+func (v *GraphView) SetOnChanged(fn func(taskId string)) { v.onChanged = fn }
+
+// SetTasks lays out a DAG's tasks. The cursor stays on the same task when it
+// still exists, moves to its group's node when the group is collapsed, and
+// starts on the first root otherwise.
+func (v *GraphView) SetTasks(tasks []models.Task) {
+	v.all = tasks
+	v.layout()
+	v.render()
+}
+
+// layout folds the collapsed groups and places the nodes in stages.
+func (v *GraphView) layout() {
+	v.tasks, v.members = collapseGroups(v.all, v.expanded)
+	v.levels = topoLevels(v.tasks)
+	v.pos = map[string][2]int{}
+	for s, stage := range v.levels {
+		for r, id := range stage {
+			v.pos[id] = [2]int{s, r}
+		}
+	}
+	if _, ok := v.pos[v.selected]; ok {
+		return
+	}
+	for _, t := range v.all {
+		if t.TaskId != v.selected && !slices.Contains(t.Groups, v.selected) {
+			continue
+		}
+		// Hidden in a collapsed group, or a group that was opened.
+		for _, u := range v.tasks {
+			if _, folded := v.members[u.TaskId]; u.TaskId == t.TaskId || folded && slices.Contains(t.Groups, u.TaskId) {
+				v.selected = u.TaskId
+				v.changed()
+				return
+			}
+		}
+	}
+	v.selected, v.first = "", 0
+	if len(v.levels) > 0 {
+		v.selected = v.levels[0][0]
+	}
+	v.changed()
+}
+
+// IsGroup reports whether id is a collapsed group's node.
+func (v *GraphView) IsGroup(id string) bool {
+	_, ok := v.members[id]
+	return ok
+}
+
+// Members returns the tasks a collapsed group's node stands for.
+func (v *GraphView) Members(id string) []models.Task { return v.members[id] }
+
+// Nodes returns the graph as drawn: tasks, with collapsed groups folded into
+// one node each.
+func (v *GraphView) Nodes() []models.Task { return v.tasks }
+
+// toggle opens the group under the cursor, or closes the innermost group the
+// task under the cursor sits in.
+func (v *GraphView) toggle() {
+	if v.selected == "" {
+		return
+	}
+	if v.IsGroup(v.selected) {
+		v.expanded[v.selected] = true
+	} else {
+		var groups []string
+		for _, t := range v.tasks {
+			if t.TaskId == v.selected {
+				groups = t.Groups
+			}
+		}
+		if len(groups) == 0 {
+			return
+		}
+		delete(v.expanded, groups[len(groups)-1])
+	}
+	v.layout()
+	v.render()
+}
+
+// SetStates colours the nodes by task-instance state (task id → state).
+func (v *GraphView) SetStates(states map[string]string) {
+	v.states = states
+	v.render()
+}
+
+// state is a task's state, or the summed-up state of a group node's tasks.
+func (v *GraphView) state(id string) string {
+	members, ok := v.members[id]
+	if !ok {
+		return v.states[id]
+	}
+	states := make([]string, len(members))
+	for i, t := range members {
+		states[i] = v.states[t.TaskId]
+	}
+	return groupState(states)
+}
+
+// Selected returns the task under the cursor, "" for an empty graph.
+func (v *GraphView) Selected() string { return v.selected }
+
+// Select moves the cursor to taskId if the graph has it.
+func (v *GraphView) Select(taskId string) {
+	if _, ok := v.pos[taskId]; !ok || taskId == v.selected {
+		return
+	}
+	v.selected = taskId
+	v.changed()
+	v.render()
+}
+
+func (v *GraphView) changed() {
+	if v.onChanged != nil {
+		v.onChanged(v.selected)
+	}
+}
+
+// follow steps along an edge: to the parent (dir < 0) or child (dir > 0)
+// closest to the cursor, first by stage and then by row.
+func (v *GraphView) follow(dir int) {
+	at, ok := v.pos[v.selected]
+	if !ok {
+		return
+	}
+	parents, children := graphEdges(v.tasks)
+	next := children[v.selected]
+	if dir < 0 {
+		next = parents[v.selected]
+	}
+	best, bestStage, bestRow := "", 0, 0
+	for _, id := range next {
+		p := v.pos[id]
+		stage, row := abs(p[0]-at[0]), abs(p[1]-at[1])
+		if best == "" || stage < bestStage || stage == bestStage && (row < bestRow || row == bestRow && id < best) {
+			best, bestStage, bestRow = id, stage, row
+		}
+	}
+	v.Select(best)
+}
+
+// step moves within the cursor's stage.
+func (v *GraphView) step(delta int) {
+	at, ok := v.pos[v.selected]
+	if !ok {
+		return
+	}
+	stage := v.levels[at[0]]
+	v.Select(stage[max(min(at[1]+delta, len(stage)-1), 0)])
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func (v *GraphView) render() {
+	_, _, w, h := v.GetInnerRect()
+	v.drawnW, v.drawnH = w, h
+	if w <= 0 {
+		w = 80
+	}
+	stateOf := func(id string) NodeState { return NodeStateFromTI(v.state(id)) }
+	focus := graphFocus{}
+	if at, ok := v.pos[v.selected]; ok {
+		v.first = scrollStages(v.first, at[0], len(v.levels), w)
+		focus = newGraphFocus(v.tasks, v.selected, v.first)
+	}
+	focus.labels = groupNodeLabels(v.tasks, v.members)
+	th := theme.ActiveTheme()
+	legend := fmt.Sprintf("\n[gray]legend:[-] %s%s %s%s %s%s %s%s %s%s\n",
+		mk(th, "success"), " success ",
+		mk(th, "running"), " running ",
+		mk(th, "failed"), " failed ",
+		mk(th, "skipped"), " skipped ",
+		mk(th, ""), " pending")
+	hint := "[gray]" + tview.Escape("[←/→] follow edges  [↑/↓] same stage  [Enter] logs  [space] open/close group  [g] tree") + "[-]\n"
+	v.SetText(renderGraph(v.tasks, stateOf, w, focus) + legend + hint)
+
+	// Keep the cursor's row on screen; node rows start below the header.
+	top := 0
+	if at, ok := v.pos[v.selected]; ok && h > 0 {
+		top = max(at[1]+2-h, 0)
+	}
+	v.ScrollTo(top, 0)
+}
+
+// InputHandler drives the node cursor; the TextView's own scrolling is not
+// used because rows are laid out here.
+func (v *GraphView) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return v.WrapInputHandler(func(event *tcell.EventKey, _ func(p tview.Primitive)) {
+		switch event.Key() {
+		case tcell.KeyLeft:
+			v.follow(-1)
+		case tcell.KeyRight:
+			v.follow(1)
+		case tcell.KeyUp:
+			v.step(-1)
+		case tcell.KeyDown:
+			v.step(1)
+		case tcell.KeyHome:
+			if len(v.levels) > 0 {
+				v.Select(v.levels[0][0])
+			}
+		case tcell.KeyEnter:
+			if v.IsGroup(v.selected) {
+				v.toggle()
+			} else if v.selected != "" && v.onSelected != nil {
+				v.onSelected(v.selected)
+			}
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'h':
+				v.follow(-1)
+			case 'l':
+				v.follow(1)
+			case 'k':
+				v.step(-1)
+			case 'j':
+				v.step(1)
+			case ' ':
+				v.toggle()
+			}
+		}
+	})
+}
+
+// Draw re-lays the graph when the pane is resized; how many stages fit
+// depends on it.
+func (v *GraphView) Draw(screen tcell.Screen) {
+	if _, _, w, h := v.GetInnerRect(); w != v.drawnW || h != v.drawnH {
+		v.render()
+	}
+	v.TextView.Draw(screen)
+}
@@ -0,0 +1,114 @@
+package views
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+func graphKey(v *GraphView, key tcell.Key, r rune) {
+	v.InputHandler()(tcell.NewEventKey(key, r, tcell.ModNone), func(tview.Primitive) {})
+}
+
+// extract fans out to two transforms that join again in load; report hangs
+// off the second transform only.
+func graphTasks() []models.Task {
+	return []models.Task{
+		{TaskId: "extract"},
+		{TaskId: "clean", UpstreamTaskIds: []string{"extract"}},
+		{TaskId: "enrich", UpstreamTaskIds: []string{"extract"}},
+		{TaskId: "load", UpstreamTaskIds: []string{"clean", "enrich"}},
+		{TaskId: "report", UpstreamTaskIds: []string{"enrich"}},
+	}
+}
+
+func TestGraphView_followsEdges(t *testing.T) {
+	v := NewGraphView()
+	v.SetRect(0, 0, 100, 20)
+	var moved []string
+	v.SetOnChanged(func(id string) { moved = append(moved, id) })
+	v.SetTasks(graphTasks())
+
+	if v.Selected() != "extract" {
+		t.Fatalf("cursor starts on %q, want the first root", v.Selected())
+	}
+	steps := []struct {
+		key  tcell.Key
+		r    rune
+		want string
+	}{
+		{tcell.KeyRight, 0, "clean"}, // the closer of two children
+		{tcell.KeyDown, 0, "enrich"}, // same stage
+		{tcell.KeyRune, 'l', "report"},
+		{tcell.KeyRight, 0, "report"}, // a leaf: nowhere to go
+		{tcell.KeyUp, 0, "load"},
+		{tcell.KeyLeft, 0, "clean"},
+		{tcell.KeyRune, 'h', "extract"},
+	}
+	for _, s := range steps {
+		graphKey(v, s.key, s.r)
+		if v.Selected() != s.want {
+			t.Fatalf("after %v %q on the way to %s: cursor on %q", s.key, s.r, s.want, v.Selected())
+		}
+	}
+	if got := strings.Join(moved, ","); got != "extract,clean,enrich,report,load,clean,extract" {
+		t.Fatalf("change notifications %s", got)
+	}
+
+	var opened string
+	v.SetOnSelected(func(id string) { opened = id })
+	graphKey(v, tcell.KeyEnter, 0)
+	if opened != "extract" {
+		t.Fatalf("Enter opened %q", opened)
+	}
+
+	// A refresh keeps the cursor while the task still exists.
+	v.Select("enrich")
+	v.SetTasks(graphTasks())
+	if v.Selected() != "enrich" {
+		t.Fatalf("refresh moved the cursor to %q", v.Selected())
+	}
+}
+
+func TestLineageOf(t *testing.T) {
+	up, down := lineageOf(graphTasks(), "enrich")
+	if len(up) != 1 || !up["extract"] {
+		t.Errorf("upstream of enrich = %v", up)
+	}
+	if len(down) != 2 || !down["load"] || !down["report"] {
+		t.Errorf("downstream of enrich = %v", down)
+	}
+	if up, down := lineageOf(graphTasks(), "clean"); len(up) != 1 || len(down) != 1 || down["report"] {
+		t.Errorf("clean's lineage = %v / %v; report is not downstream of it", up, down)
+	}
+}
+
+func TestRenderGraphFocus(t *testing.T) {
+	tasks := graphTasks()
+	stateOf := func(string) NodeState { return NodeSuccess }
+	out := renderGraph(tasks, stateOf, 120, newGraphFocus(tasks, "clean", 0))
+	if !strings.Contains(out, "::r]● clean") {
+		t.Errorf("selected node should be reversed\n%s", out)
+	}
+	if !strings.Contains(out, "::b]● load") {
+		t.Errorf("downstream node should be bold\n%s", out)
+	}
+	for _, id := range []string{"enrich", "report"} {
+		if strings.Contains(out, "::b]● "+id) || strings.Contains(out, "::r]● "+id) {
+			t.Errorf("%s is outside clean's lineage and should be dimmed\n%s", id, out)
+		}
+	}
+
+	// Scrolled right, the graph starts at the selected stage's neighbourhood.
+	first := scrollStages(0, 2, 3, 2*graphStageWidth)
+	out = renderGraph(tasks, stateOf, 2*graphStageWidth, newGraphFocus(tasks, "load", first))
+	if !strings.Contains(out, "load") || strings.Contains(out, "Stage 1 ") {
+		t.Errorf("stage 3 should be on screen, stage 1 scrolled off\n%s", out)
+	}
+	if !strings.Contains(out, "earlier stage") {
+		t.Errorf("expected a marker for the stages scrolled off\n%s", out)
+	}
+}
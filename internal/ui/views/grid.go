@@ -0,0 +1,243 @@
+package views
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/yjinheon/lazyflow/internal/ui/theme"
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+// GridRuns is how many of a DAG's most recent runs the grid lays out.
+const GridRuns = 25
+
+// gridRows orders the grid's rows: tasks in topological stages, then any task
+// ids seen only in instances (removed from the DAG since) sorted at the end.
+func gridRows(defs []models.Task, tis []models.TaskInstance) []string {
+	var rows []string
+	seen := map[string]bool{}
+	for _, stage := range topoLevels(defs) {
+		for _, id := range stage {
+			rows = append(rows, id)
+			seen[id] = true
+		}
+	}
+	var extra []string
+	for _, ti := range tis {
+		if !seen[ti.TaskId] {
+			extra = append(extra, ti.TaskId)
+			seen[ti.TaskId] = true
+		}
+	}
+	sort.Strings(extra)
+	return append(rows, extra...)
+}
+
+// GridColumns keeps the GridRuns most recent runs, oldest first: the grid's
+// columns, and the runs whose instances a caller needs to load.
+func GridColumns(runs []models.DAGRun) []models.DAGRun {
+	out := append([]models.DAGRun(nil), runs...)
+	sort.SliceStable(out, func(i, j int) bool { return runRecency(out[i]).Before(runRecency(out[j])) })
+	if len(out) > GridRuns {
+		out = out[len(out)-GridRuns:]
+	}
+	return out
+}
+
+// GridView is the tasks × runs matrix of one DAG: recent runs as columns
+// (newest on the right), tasks as rows, a state-coloured cell per instance.
+// The header row carries each run's own state.
+type GridView struct {
+	*tview.Table
+
+	dagId      string
+	runs       []models.DAGRun
+	tasks      []string
+	cells      map[string]map[string]models.TaskInstance // run id → task id
+	instances  map[string][]models.TaskInstance          // run id → all its instances
+	onSelected func(run models.DAGRun, ti models.TaskInstance)
+}
+
+func NewGridView() *GridView {
+	v := &GridView{Table: tview.NewTable()}
+	th := theme.ActiveTheme()
+	v.SetBorder(true).SetTitle(" Grid ")
+	v.SetFixed(1, 1)
+	v.SetSelectable(false, false)
+	v.SetSelectedStyle(tcell.StyleDefault.
+		Background(th.TableSelected).Foreground(th.PrimaryText).Attributes(tcell.AttrBold))
+	v.SetFocusFunc(func() { v.SetBorderColor(th.BorderFocused) })
+	v.SetBlurFunc(func() { v.SetBorderColor(th.BorderColor) })
+	v.SetSelectionChangedFunc(func(int, int) { v.updateTitle() })
+	v.SetSelectedFunc(func(int, int) {
+		run, ti, ok := v.Current()
+		if ok && v.onSelected != nil {
+			v.onSelected(run, ti)
+		}
+	})
+	v.setMessage("Select a DAG, then press g in the Runs tab.")
+	return v
+}
+
+func (v *GridView) Root() tview.Primitive { return v.Table }
+
+// SetOnSelected is called with the run and instance under the cursor on Enter.
+func (v *GridView) SetOnSelected(handler func(run models.DAGRun, ti models.TaskInstance)) {
+	v.onSelected = handler
+}
+
+// DagId is the DAG the grid currently shows.
+func (v *GridView) DagId() string { return v.dagId }
+
+func (v *GridView) setMessage(msg string) {
+	v.runs, v.tasks, v.cells, v.instances = nil, nil, nil, nil
+	v.Clear()
+	v.SetSelectable(false, false)
+	v.SetTitle(" Grid ")
+	setEmptyHint(v.Table, msg)
+}
+
+// SetLoading shows a placeholder while a DAG's runs and instances load.
+func (v *GridView) SetLoading(dagId string) {
+	v.dagId = dagId
+	v.setMessage(fmt.Sprintf("Loading recent runs of %s...", dagId))
+}
+
+// SetError replaces the grid with a fetch error.
+func (v *GridView) SetError(msg string) {
+	v.setMessage("Grid failed: " + msg)
+}
+
+// Update lays out runs against the task definitions; tis holds the instances
+// of any of the runs. The cursor stays on the same run and task across
+// refreshes of one DAG and starts on the newest run otherwise.
+func (v *GridView) Update(dagId string, runs []models.DAGRun, defs []models.Task, tis []models.TaskInstance) {
+	var keepRun, keepTask string
+	if dagId == v.dagId {
+		if run, ti, ok := v.cursor(); ok {
+			keepRun, keepTask = run.RunId, ti
+		}
+	}
+	v.dagId = dagId
+	v.runs = GridColumns(runs)
+	v.cells = map[string]map[string]models.TaskInstance{}
+	v.instances = map[string][]models.TaskInstance{}
+	inGrid := map[string]bool{}
+	for _, r := range v.runs {
+		v.cells[r.RunId] = map[string]models.TaskInstance{}
+		inGrid[r.RunId] = true
+	}
+	var shown []models.TaskInstance
+	for _, ti := range tis {
+		if !inGrid[ti.RunId] {
+			continue
+		}
+		// The expansions of a mapped task share its cell, which shows the
+		// worst of them the way the run's task list sums them up.
+		if prev, ok := v.cells[ti.RunId][ti.TaskId]; !ok || groupState([]string{prev.State, ti.State}) != prev.State {
+			v.cells[ti.RunId][ti.TaskId] = ti
+		}
+		v.instances[ti.RunId] = append(v.instances[ti.RunId], ti)
+		shown = append(shown, ti)
+	}
+	v.tasks = gridRows(defs, shown)
+
+	if len(v.runs) == 0 {
+		v.setMessage(fmt.Sprintf("No runs of %s yet.", dagId))
+		return
+	}
+	if len(v.tasks) == 0 {
+		v.setMessage(fmt.Sprintf("No tasks loaded for %s.", dagId))
+		return
+	}
+	v.render()
+
+	row, col := 1, len(v.runs)
+	for c, r := range v.runs {
+		if r.RunId == keepRun {
+			col = c + 1
+		}
+	}
+	for i, id := range v.tasks {
+		if id == keepTask {
+			row = i + 1
+		}
+	}
+	v.Select(row, col)
+	v.updateTitle()
+}
+
+func (v *GridView) render() {
+	th := theme.ActiveTheme()
+	v.Clear()
+	v.SetSelectable(true, true)
+	v.SetCell(0, 0, tview.NewTableCell("Task").
+		SetTextColor(th.TableHeaderText).SetSelectable(false))
+	for c, r := range v.runs {
+		sym, color := th.StatusStyle(r.State)
+		v.SetCell(0, c+1, tview.NewTableCell(sym).
+			SetTextColor(color).SetSelectable(false))
+	}
+	for i, id := range v.tasks {
+		row := i + 1
+		v.SetCell(row, 0, tview.NewTableCell(tview.Escape(id)).
+			SetTextColor(th.PrimaryText).SetSelectable(false))
+		for c, r := range v.runs {
+			ti, ok := v.cells[r.RunId][id]
+			cell := tview.NewTableCell("·").SetTextColor(th.MutedText)
+			if ok {
+				sym, color := th.StatusStyle(ti.State)
+				cell.SetText(sym).SetTextColor(color)
+			}
+			v.SetCell(row, c+1, cell)
+		}
+	}
+}
+
+// cursor resolves the selection to a run and a task id.
+func (v *GridView) cursor() (models.DAGRun, string, bool) {
+	row, col := v.GetSelection()
+	if row < 1 || row > len(v.tasks) || col < 1 || col > len(v.runs) {
+		return models.DAGRun{}, "", false
+	}
+	return v.runs[col-1], v.tasks[row-1], true
+}
+
+// Current returns the run and instance under the cursor; false on an empty
+// cell (the task did not exist in that run).
+func (v *GridView) Current() (models.DAGRun, models.TaskInstance, bool) {
+	run, taskId, ok := v.cursor()
+	if !ok {
+		return models.DAGRun{}, models.TaskInstance{}, false
+	}
+	ti, ok := v.cells[run.RunId][taskId]
+	return run, ti, ok
+}
+
+// RunInstances returns every instance the grid holds for runId.
+func (v *GridView) RunInstances(runId string) []models.TaskInstance {
+	return append([]models.TaskInstance(nil), v.instances[runId]...)
+}
+
+// updateTitle names the run and instance under the cursor; the cells are
+// single glyphs with no room for either.
+func (v *GridView) updateTitle() {
+	run, taskId, ok := v.cursor()
+	if !ok {
+		return
+	}
+	state := "no instance"
+	if ti, found := v.cells[run.RunId][taskId]; found {
+		state = ti.State
+		if state == "" {
+			state = "none"
+		}
+		if ti.TryNumber > 1 {
+			state = fmt.Sprintf("%s, try %d", state, ti.TryNumber)
+		}
+	}
+	v.SetTitle(fmt.Sprintf(" Grid %s  %s (%s)  %s: %s ",
+		tview.Escape(v.dagId), tview.Escape(run.RunId), run.State, tview.Escape(taskId), state))
+}
@@ -0,0 +1,116 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+func gridKey(v *GridView, key tcell.Key, r rune) {
+	v.InputHandler()(tcell.NewEventKey(key, r, tcell.ModNone), func(tview.Primitive) {})
+}
+
+func TestGridView(t *testing.T) {
+	defs := []models.Task{
+		{TaskId: "load", UpstreamTaskIds: []string{"transform"}},
+		{TaskId: "extract"},
+		{TaskId: "transform", UpstreamTaskIds: []string{"extract"}},
+	}
+	base := time.Date(2026, 5, 23, 0, 0, 0, 0, time.UTC)
+	var runs []models.DAGRun
+	var tis []models.TaskInstance
+	for i := range 30 {
+		runId := fmt.Sprintf("r%02d", i)
+		// Newest first, the way the Runs tab receives them.
+		runs = append([]models.DAGRun{{RunId: runId, State: "success", RunAfter: base.Add(time.Duration(i) * time.Hour)}}, runs...)
+		for _, d := range defs {
+			tis = append(tis, models.TaskInstance{RunId: runId, TaskId: d.TaskId, State: "success"})
+		}
+	}
+	for i := range tis {
+		if tis[i].RunId == "r29" && tis[i].TaskId == "load" {
+			tis[i].State = "failed"
+		}
+	}
+	tis = append(tis, models.TaskInstance{RunId: "r03", TaskId: "legacy", State: "failed"})
+
+	v := NewGridView()
+	v.SetRect(0, 0, 80, 10)
+	v.Update("etl", runs, defs, tis)
+
+	if v.GetColumnCount() != GridRuns+1 {
+		t.Fatalf("columns = %d, want %d runs and the label", v.GetColumnCount(), GridRuns)
+	}
+	var rows []string
+	for r := 1; r < v.GetRowCount(); r++ {
+		rows = append(rows, v.GetCell(r, 0).Text)
+	}
+	if got := strings.Join(rows, ","); got != "extract,transform,load" {
+		t.Fatalf("rows = %s; legacy ran only in a run off the grid", got)
+	}
+	if n, off := len(v.RunInstances("r29")), len(v.RunInstances("r03")); n != 3 || off != 0 {
+		t.Fatalf("RunInstances = %d for r29, %d for r03 off the grid", n, off)
+	}
+
+	run, ti, ok := v.Current()
+	if !ok || run.RunId != "r29" || ti.TaskId != "extract" {
+		t.Fatalf("cursor starts on %s/%s, want the newest run's first task", run.RunId, ti.TaskId)
+	}
+	gridKey(v, tcell.KeyDown, 0)
+	gridKey(v, tcell.KeyDown, 0)
+	gridKey(v, tcell.KeyLeft, 0)
+	if run, ti, _ := v.Current(); run.RunId != "r28" || ti.TaskId != "load" {
+		t.Fatalf("cursor on %s/%s, want r28/load", run.RunId, ti.TaskId)
+	}
+	gridKey(v, tcell.KeyRight, 0)
+	if !strings.Contains(v.GetTitle(), "r29") || !strings.Contains(v.GetTitle(), "load: failed") {
+		t.Fatalf("title %q should name the cell under the cursor", v.GetTitle())
+	}
+
+	var picked string
+	v.SetOnSelected(func(run models.DAGRun, ti models.TaskInstance) { picked = run.RunId + "/" + ti.TaskId })
+	gridKey(v, tcell.KeyEnter, 0)
+	if picked != "r29/load" {
+		t.Fatalf("Enter picked %q", picked)
+	}
+
+	// A refresh that adds a run keeps the cursor on the same cell.
+	runs = append([]models.DAGRun{{RunId: "r30", State: "running", RunAfter: base.Add(30 * time.Hour)}}, runs...)
+	v.Update("etl", runs, defs, tis)
+	if run, ti, _ := v.Current(); run.RunId != "r29" || ti.TaskId != "load" {
+		t.Fatalf("refresh moved the cursor to %s/%s", run.RunId, ti.TaskId)
+	}
+	if _, _, ok := v.Current(); !ok {
+		t.Fatal("r29/load has an instance")
+	}
+	gridKey(v, tcell.KeyRight, 0)
+	if _, _, ok := v.Current(); ok {
+		t.Fatal("r30 has no instances yet; its cells are empty")
+	}
+}
+
+func TestGridView_mappedTaskShowsWorstExpansion(t *testing.T) {
+	defs := []models.Task{{TaskId: "render", IsMapped: true}}
+	runs := []models.DAGRun{{RunId: "r1", State: "failed"}}
+	tis := []models.TaskInstance{
+		{RunId: "r1", TaskId: "render", MapIndex: 0, State: "success"},
+		{RunId: "r1", TaskId: "render", MapIndex: 1, State: "failed"},
+		{RunId: "r1", TaskId: "render", MapIndex: 2, State: "running"},
+		{RunId: "r1", TaskId: "render", MapIndex: 3, State: "success"},
+	}
+
+	v := NewGridView()
+	v.SetRect(0, 0, 80, 10)
+	v.Update("etl", runs, defs, tis)
+	if _, ti, ok := v.Current(); !ok || ti.State != "failed" || ti.MapIndex != 1 {
+		t.Fatalf("cell = %+v, want the failed expansion", ti)
+	}
+	if got := v.RunInstances("r1"); len(got) != len(tis) {
+		t.Fatalf("RunInstances = %d instances, want every expansion", len(got))
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 type HelpView struct {
 	*tview.Table
+	readOnly bool // omit actions that change Airflow state
 }
 
 func NewHelpView() *HelpView {
@@ -19,6 +20,13 @@ func NewHelpView() *HelpView {
 	return v
 }
 
+// SetReadOnly re-renders the keymap without (or with) mutating actions.
+func (v *HelpView) SetReadOnly(on bool) {
+	v.readOnly = on
+	v.Clear()
+	v.render()
+}
+
 func (v *HelpView) render() {
 	v.SetCell(0, 0, tview.NewTableCell("Key").
 		SetTextColor(theme.ActiveTheme().TableHeaderText).
@@ -44,28 +52,72 @@ func (v *HelpView) render() {
 	row = v.addBinding(row, "< / >  ·  Shift+← / →", "Previous / next tab")
 	row = v.addBinding(row, "B", "Backfills")
 	row = v.addBinding(row, "g", "Toggle gantt (Tasks) or graph (Lineage)")
+	row = v.addBinding(row, "S", "SLA breaches (Enter opens the DAG's runs)")
+	row = v.addBinding(row, "!", "API health per endpoint and recent API errors")
+	row = v.addBinding(row, "H", "Action history: what was triggered, paused or backfilled, by whom")
 	row = v.addBinding(row, "?", "Open this keymap page")
 
-	row = v.addSection(row+1, "DAG Actions")
-	row = v.addBinding(row, "t", "Trigger selected DAG run")
-	row = v.addBinding(row, "p", "Pause / unpause selected DAG")
-	row = v.addBinding(row, "b", "Backfill selected DAG")
+	if !v.readOnly {
+		row = v.addSection(row+1, "DAG Actions")
+		row = v.addBinding(row, "t", "Trigger selected DAG run")
+		row = v.addBinding(row, "p", "Pause / unpause selected DAG")
+		row = v.addBinding(row, "b", "Backfill selected DAG")
+	}
+
+	row = v.addSection(row+1, "Runs Tab")
+	row = v.addBinding(row, "v", "View run conf as a JSON tree")
+	if !v.readOnly {
+		row = v.addBinding(row, "T", "Re-trigger with the run's conf (run id / note optional)")
+		row = v.addBinding(row, "n", "Edit note on the run (Runs) or task instance (Tasks)")
+	}
+	row = v.addBinding(row, "m", "Mark / unmark run for comparison (two at most)")
+	row = v.addBinding(row, "C", "Compare the two marked runs (Esc returns to Runs)")
+	row = v.addBinding(row, "g", "Grid of tasks × the last 25 runs (g or Esc returns to Runs)")
+
+	row = v.addSection(row+1, "Grid (Runs tab, g)")
+	row = v.addBinding(row, "h / l  ·  ← / →", "Move between runs, oldest on the left")
+	row = v.addBinding(row, "j / k  ·  ↑ / ↓", "Move between tasks, in dependency order")
+	row = v.addBinding(row, "Enter", "Open the task instance's logs")
+
+	row = v.addSection(row+1, "Gantt (Tasks tab, g)")
+	row = v.addBinding(row, "j / k  ·  ↑ / ↓", "Move the row cursor")
+	row = v.addBinding(row, "+ / -  ·  z", "Zoom the time axis in / out around the selected task  ·  fit the whole run")
+	row = v.addBinding(row, "h / l  ·  ← / →", "Pan the time axis while zoomed")
+	row = v.addBinding(row, "e", "Ghost bars: each task's median start / end from cached history")
+	row = v.addBinding(row, "Enter", "Open the selected task's logs")
+
+	row = v.addSection(row+1, "Lineage Graph (Lineage tab, g)")
+	row = v.addBinding(row, "h / l  ·  ← / →", "Step to an upstream / downstream task")
+	row = v.addBinding(row, "j / k  ·  ↑ / ↓", "Step within the same stage")
+	row = v.addBinding(row, "Enter", "Open the task's logs for the selected run, or open a task group")
+	row = v.addBinding(row, "Space", "Open the task group under the cursor  ·  close the selected task's group")
 
 	row = v.addSection(row+1, "Modal Actions")
 	row = v.addBinding(row, "Esc", "Close without running")
 	row = v.addBinding(row, "Enter", "Submit when focused outside a JSON text area")
 	row = v.addBinding(row, "Ctrl+J / Ctrl+M", "Submit from anywhere in the form")
-
-	row = v.addSection(row+1, "Backfill Actions")
-	row = v.addBinding(row, "p / u", "Pause / unpause selected backfill")
-	row = v.addBinding(row, "c", "Cancel selected backfill")
+	row = v.addBinding(row, "Ctrl+J", "Save in the note editor (Enter adds a line)")
+
+	if v.readOnly {
+		row = v.addSection(row+1, "Read-only Mode")
+		row = v.addBinding(row, "", "Trigger, pause, backfill, note and undo keys are disabled")
+	} else {
+		row = v.addSection(row+1, "Backfill Actions")
+		row = v.addBinding(row, "p / u", "Pause / unpause selected backfill")
+		row = v.addBinding(row, "c", "Cancel selected backfill")
+
+		row = v.addSection(row+1, "Action History")
+		row = v.addBinding(row, "U", "Undo selected pause / unpause (DAG or backfill)")
+	}
 
 	row = v.addSection(row+1, "Monitor Tab")
 	row = v.addBinding(row, "[ / ]", "Previous / next time window")
 	row = v.addBinding(row, "r", "Refresh dashboard")
+	row = v.addBinding(row, "F", "Toggle the all-DAGs fleet table (shown until a DAG is selected)")
+	row = v.addBinding(row, "s", "Cycle fleet sort column; Enter opens the DAG")
 
 	row = v.addSection(row+1, "DAG Filters")
-	row = v.addBinding(row, "← / → on KPI bar", "All / active / paused / run-state filters")
+	row = v.addBinding(row, "← / → on KPI bar", "All / active / paused / run-state / SLA filters")
 	row = v.addBinding(row, "a", "Active DAGs")
 	row = v.addBinding(row, "A", "All DAGs")
 	row = v.addBinding(row, "f", "Failed DAGs")
@@ -79,6 +131,7 @@ func (v *HelpView) render() {
 	row = v.addSection(row+1, "General")
 	row = v.addBinding(row, "F5", "Refresh")
 	row = v.addBinding(row, "/", "Search")
+	row = v.addBinding(row, "E", "Export cached run / task history (CSV, JSON Lines, SQLite)")
 	_ = v.addBinding(row, "Ctrl+C", "Quit")
 }
 
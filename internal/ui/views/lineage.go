@@ -2,6 +2,8 @@ package views
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/rivo/tview"
 	"github.com/yjinheon/lazyflow/internal/ui/theme"
@@ -15,20 +17,28 @@ const (
 
 type LineageView struct {
 	*tview.Pages
-	treeFlex *tview.Flex
-	tree     *tview.TreeView
-	details  *tview.TextView
-	graph    *tview.TextView
-	tasks    []models.Task
+	treeFlex     *tview.Flex
+	tree         *tview.TreeView
+	details      *tview.TextView
+	graphFlex    *tview.Flex
+	graph        *GraphView
+	graphDetails *tview.TextView
+	tasks        []models.Task
+
+	runId      string
+	instances  map[string]models.TaskInstance // selected run's, by task id
+	onOpenLogs func(taskId string)
 }
 
 func NewLineageView() *LineageView {
 	v := &LineageView{
-		Pages:    tview.NewPages(),
-		treeFlex: tview.NewFlex(),
-		tree:     tview.NewTreeView(),
-		details:  tview.NewTextView(),
-		graph:    tview.NewTextView(),
+		Pages:        tview.NewPages(),
+		treeFlex:     tview.NewFlex(),
+		tree:         tview.NewTreeView(),
+		details:      tview.NewTextView(),
+		graphFlex:    tview.NewFlex(),
+		graph:        NewGraphView(),
+		graphDetails: tview.NewTextView(),
 	}
 	v.setup()
 	return v
@@ -49,18 +59,39 @@ func (v *LineageView) setup() {
 		AddItem(v.tree, 0, 60, true).
 		AddItem(v.details, 0, 40, false)
 
-	v.graph.SetBorder(true).
-		SetTitle(" DAG Graph (g: tree) ").
+	v.graph.SetBorderColor(theme.ActiveTheme().BorderColor)
+	v.graph.SetFocusFunc(func() { v.graph.SetBorderColor(theme.ActiveTheme().BorderFocused) })
+	v.graph.SetBlurFunc(func() { v.graph.SetBorderColor(theme.ActiveTheme().BorderColor) })
+	v.graph.SetOnChanged(func(string) { v.showGraphDetails() })
+	v.graph.SetOnSelected(func(taskId string) {
+		if v.onOpenLogs != nil {
+			v.onOpenLogs(taskId)
+		}
+	})
+
+	v.graphDetails.SetBorder(true).
+		SetTitle(" Task Details ").
 		SetBorderColor(theme.ActiveTheme().BorderColor)
-	v.graph.SetDynamicColors(true).SetWrap(false)
+	v.graphDetails.SetDynamicColors(true)
+
+	v.graphFlex.SetDirection(tview.FlexColumn).
+		AddItem(v.graph, 0, 65, true).
+		AddItem(v.graphDetails, 0, 35, false)
 
 	v.AddPage(lineagePageTree, v.treeFlex, true, true)
-	v.AddPage(lineagePageGraph, v.graph, true, false)
+	v.AddPage(lineagePageGraph, v.graphFlex, true, false)
+}
+
+// SetOnOpenLogs is called with the graph's selected task on Enter.
+func (v *LineageView) SetOnOpenLogs(handler func(taskId string)) {
+	v.onOpenLogs = handler
 }
 
 func (v *LineageView) SetTasks(dagId string, tasks []models.Task) {
 	v.tasks = tasks
 	v.details.SetText("")
+	v.graph.SetTasks(tasks)
+	v.showGraphDetails()
 
 	root := tview.NewTreeNode(dagId).
 		SetColor(theme.ActiveTheme().Accent)
@@ -71,44 +102,61 @@ func (v *LineageView) SetTasks(dagId string, tasks []models.Task) {
 		return
 	}
 
+	v.addLineage(root, tasks, nil)
+}
+
+// addLineage hangs the downstream trees of tasks under parent, with each
+// task group folded into one node. A group node's first child lists the
+// group's own tasks the same way; it starts closed and opens on Enter.
+func (v *LineageView) addLineage(parent *tview.TreeNode, tasks []models.Task, open []string) {
+	expanded := make(map[string]bool, len(open))
+	for _, g := range open {
+		expanded[g] = true
+	}
+	units, members := collapseGroups(tasks, expanded)
+
 	taskMap := make(map[string]models.Task)
-	for _, t := range tasks {
+	for _, t := range units {
 		taskMap[t.TaskId] = t
 	}
 
 	roots := []models.Task{}
-	for _, t := range tasks {
+	for _, t := range units {
 		if len(t.UpstreamTaskIds) == 0 {
 			roots = append(roots, t)
 		}
 	}
 
-	if len(roots) == 0 && len(tasks) > 0 {
-		roots = append(roots, tasks[0])
+	if len(roots) == 0 && len(units) > 0 {
+		roots = append(roots, units[0])
 	}
 
 	for _, t := range roots {
-		node := v.buildNode(t, taskMap, 0)
-		root.AddChild(node)
+		node := v.buildNode(t, taskMap, members, 0)
+		parent.AddChild(node)
 	}
 }
 
-func (v *LineageView) buildNode(task models.Task, taskMap map[string]models.Task, depth int) *tview.TreeNode {
+func (v *LineageView) buildNode(task models.Task, taskMap map[string]models.Task, members map[string][]models.Task, depth int) *tview.TreeNode {
 	if depth > 10 {
 		return tview.NewTreeNode("... (max depth)")
 	}
 
-	node := tview.NewTreeNode(task.TaskId).
+	node := tview.NewTreeNode(groupLabel(task.TaskId, task.Groups)).
 		SetReference(task.TaskId).
 		SetSelectable(true)
 
-	node.SetSelectedFunc(func() {
-		v.showDetails(task)
-	})
+	if inside, isGroup := members[task.TaskId]; isGroup {
+		v.buildGroupNode(node, task, inside)
+	} else {
+		node.SetSelectedFunc(func() {
+			v.showDetails(task)
+		})
+	}
 
 	for _, downId := range task.DownstreamTaskIds {
 		if downstreamTask, exists := taskMap[downId]; exists {
-			childNode := v.buildNode(downstreamTask, taskMap, depth+1)
+			childNode := v.buildNode(downstreamTask, taskMap, members, depth+1)
 			node.AddChild(childNode)
 		}
 	}
@@ -116,6 +164,33 @@ func (v *LineageView) buildNode(task models.Task, taskMap map[string]models.Task
 	return node
 }
 
+// buildGroupNode gives a group node its closed list of tasks and the Enter
+// handler that opens and closes it.
+func (v *LineageView) buildGroupNode(node *tview.TreeNode, group models.Task, inside []models.Task) {
+	name := groupLabel(group.TaskId, group.Groups)
+	list := tview.NewTreeNode("").SetSelectable(true).SetExpanded(false).
+		SetColor(theme.ActiveTheme().MutedText)
+	v.addLineage(list, inside, append(group.Groups[:len(group.Groups):len(group.Groups)], group.TaskId))
+	label := func() {
+		marker := "▸"
+		if list.IsExpanded() {
+			marker = "▾"
+		}
+		node.SetText(fmt.Sprintf("%s %s (%d)", marker, name, len(inside)))
+		list.SetText(fmt.Sprintf("%s tasks in %s", marker, name))
+	}
+	toggle := func() {
+		list.SetExpanded(!list.IsExpanded())
+		label()
+		v.showGroupDetails(v.details, group.TaskId, inside, group.UpstreamTaskIds, group.DownstreamTaskIds,
+			"Enter opens and closes the group.")
+	}
+	label()
+	node.SetColor(theme.ActiveTheme().Accent).SetSelectedFunc(toggle)
+	list.SetSelectedFunc(toggle)
+	node.AddChild(list)
+}
+
 func (v *LineageView) showDetails(task models.Task) {
 	text := fmt.Sprintf("[yellow]Task ID:[white] %s\n", task.TaskId)
 	text += fmt.Sprintf("[yellow]Owner:[white] %s\n", task.Owner)
@@ -138,25 +213,107 @@ func (v *LineageView) IsGraphMode() bool {
 	return name == lineagePageGraph
 }
 
-func (v *LineageView) UpdateGraph(stateByTask map[string]string) {
-	_, _, w, _ := v.graph.GetInnerRect()
-	if w <= 0 {
-		w = 80
+// UpdateGraph colours the graph with the instances of the selected run,
+// which the details panel and Enter on a node refer to. A mapped task's node
+// carries the summed-up state of its expansions.
+func (v *LineageView) UpdateGraph(runId string, tis []models.TaskInstance) {
+	v.runId = runId
+	v.instances = make(map[string]models.TaskInstance, len(tis))
+	states := taskStates(tis)
+	for _, ti := range tis {
+		ti.State = states[ti.TaskId]
+		v.instances[ti.TaskId] = ti
 	}
-	stateOf := func(id string) NodeState {
-		if stateByTask == nil {
-			return NodePending
+	v.graph.SetStates(states)
+	v.showGraphDetails()
+}
+
+// Graph exposes the navigable graph for focus handling.
+func (v *LineageView) Graph() *GraphView { return v.graph }
+
+// showGraphDetails describes the graph's selected task: its definition, its
+// direct neighbours and lineage size, and its instance in the selected run.
+func (v *LineageView) showGraphDetails() {
+	taskId := v.graph.Selected()
+	if v.graph.IsGroup(taskId) {
+		parents, children := graphEdges(v.graph.Nodes())
+		v.showGroupDetails(v.graphDetails, taskId, v.graph.Members(taskId), parents[taskId], children[taskId],
+			"Enter or space opens the group.")
+		return
+	}
+	var task models.Task
+	found := false
+	for _, t := range v.tasks {
+		if t.TaskId == taskId {
+			task, found = t, true
+			break
+		}
+	}
+	if !found {
+		v.graphDetails.SetText("[gray]No task selected.")
+		return
+	}
+	parents, children := graphEdges(v.tasks)
+	up, down := lineageOf(v.tasks, taskId)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[yellow]Task ID:[white] %s\n", tview.Escape(task.TaskId))
+	fmt.Fprintf(&b, "[yellow]Operator:[white] %s\n", tview.Escape(task.Operator))
+	fmt.Fprintf(&b, "[yellow]Owner:[white] %s\n", tview.Escape(task.Owner))
+	fmt.Fprintf(&b, "[yellow]Trigger:[white] %s\n", tview.Escape(task.TriggerRule))
+	fmt.Fprintf(&b, "[yellow]Retries:[white] %.0f\n", task.Retries)
+	fmt.Fprintf(&b, "\n[yellow]Upstream:[white] %s\n", tview.Escape(strings.Join(parents[taskId], ", ")))
+	fmt.Fprintf(&b, "[yellow]Downstream:[white] %s\n", tview.Escape(strings.Join(children[taskId], ", ")))
+	fmt.Fprintf(&b, "[yellow]Lineage:[white] %d upstream, %d downstream\n", len(up), len(down))
+
+	if v.runId == "" {
+		b.WriteString("\n[gray]Select a run to see this task's instance.")
+	} else if ti, ok := v.instances[taskId]; ok {
+		sym, color := theme.ActiveTheme().StatusStyle(ti.State)
+		fmt.Fprintf(&b, "\n[yellow]Run:[white] %s\n", tview.Escape(v.runId))
+		fmt.Fprintf(&b, "[yellow]State:[-] [%s]%s %s[-]  [yellow]Try:[white] %d\n", theme.MarkupHex(color), sym, ti.State, ti.TryNumber)
+		if d := effectiveDuration(ti, time.Now()); d > 0 {
+			fmt.Fprintf(&b, "[yellow]Duration:[white] %s\n", formatDuration(d))
 		}
-		return NodeStateFromTI(stateByTask[id])
-	}
-	body := renderGraph(v.tasks, stateOf, w)
-	legend := fmt.Sprintf("\n[gray]legend:[-] %s%s %s%s %s%s %s%s %s%s\n",
-		mk(theme.ActiveTheme(), "success"), " success ",
-		mk(theme.ActiveTheme(), "running"), " running ",
-		mk(theme.ActiveTheme(), "failed"), " failed ",
-		mk(theme.ActiveTheme(), "skipped"), " skipped ",
-		mk(theme.ActiveTheme(), ""), " pending")
-	v.graph.SetText(body + legend)
+		b.WriteString("\n[gray]Enter opens this task's logs.")
+	} else {
+		fmt.Fprintf(&b, "\n[gray]No instance in %s.", tview.Escape(v.runId))
+	}
+	v.graphDetails.SetText(b.String())
+}
+
+// showGroupDetails describes a task group: its tasks, its neighbours outside
+// it and, with a run selected, the summed-up state of its instances.
+func (v *LineageView) showGroupDetails(view *tview.TextView, groupId string, inside []models.Task, up, down []string, hint string) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[yellow]Task group:[white] %s\n", tview.Escape(groupId))
+	fmt.Fprintf(&b, "[yellow]Tasks:[white] %d\n", len(inside))
+	fmt.Fprintf(&b, "\n[yellow]Upstream:[white] %s\n", tview.Escape(strings.Join(up, ", ")))
+	fmt.Fprintf(&b, "[yellow]Downstream:[white] %s\n", tview.Escape(strings.Join(down, ", ")))
+
+	if v.runId != "" {
+		states := make([]string, len(inside))
+		counts := map[string]int{}
+		for i, t := range inside {
+			states[i] = v.instances[t.TaskId].State
+			counts[states[i]]++
+		}
+		state := groupState(states)
+		sym, color := theme.ActiveTheme().StatusStyle(state)
+		fmt.Fprintf(&b, "\n[yellow]Run:[white] %s\n", tview.Escape(v.runId))
+		fmt.Fprintf(&b, "[yellow]State:[-] [%s]%s %s[-]\n", theme.MarkupHex(color), sym, state)
+		for _, s := range groupStatePriority {
+			if n := counts[s]; n > 0 {
+				name := s
+				if name == "" {
+					name = "no state"
+				}
+				fmt.Fprintf(&b, "  %s: %d\n", name, n)
+			}
+		}
+	}
+	b.WriteString("\n[gray]" + hint)
+	view.SetText(b.String())
 }
 
 func mk(t theme.Theme, state string) string {
@@ -0,0 +1,65 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+// mappedTaskIds names the tasks of a run that are mapped: those the DAG
+// declares mapped, and any with more than one instance, which only
+// expansions of a mapped task have.
+func mappedTaskIds(tis []models.TaskInstance, defs []models.Task) map[string]bool {
+	mapped := map[string]bool{}
+	for _, d := range defs {
+		if d.IsMapped {
+			mapped[d.TaskId] = true
+		}
+	}
+	seen := make(map[string]bool, len(tis))
+	for _, ti := range tis {
+		if seen[ti.TaskId] {
+			mapped[ti.TaskId] = true
+		}
+		seen[ti.TaskId] = true
+	}
+	return mapped
+}
+
+// instanceKey identifies a task instance within a run: its task id, with the
+// map index of an expansion ("render[2]").
+func instanceKey(ti models.TaskInstance, mapped map[string]bool) string {
+	if !mapped[ti.TaskId] {
+		return ti.TaskId
+	}
+	return fmt.Sprintf("%s[%d]", ti.TaskId, ti.MapIndex)
+}
+
+// taskStates gives each task its state in a run; a mapped task sums up its
+// expansions the way a task group does.
+func taskStates(tis []models.TaskInstance) map[string]string {
+	all := make(map[string][]string, len(tis))
+	for _, ti := range tis {
+		all[ti.TaskId] = append(all[ti.TaskId], ti.State)
+	}
+	out := make(map[string]string, len(all))
+	for id, states := range all {
+		out[id] = groupState(states)
+	}
+	return out
+}
+
+// mappedRowLabel labels the rows of a mapped task in a grouped list: the
+// header counts the expansions, each expansion reads its rendered map index.
+func mappedRowLabel(r groupRow, tis []models.TaskInstance, expanded bool) string {
+	indent := strings.Repeat("  ", len(r.parents))
+	if r.group == "" {
+		return indent + tis[r.index].MapLabel()
+	}
+	marker := "▸ "
+	if expanded {
+		marker = "▾ "
+	}
+	return fmt.Sprintf("%s%s%s ×%d", indent, marker, groupLabel(r.group, r.parents), len(r.members))
+}
@@ -5,10 +5,12 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+	"github.com/yjinheon/lazyflow/internal/cache"
 	"github.com/yjinheon/lazyflow/internal/metrics"
 	"github.com/yjinheon/lazyflow/internal/ui/theme"
 	"github.com/yjinheon/lazyflow/pkg/airflow/models"
@@ -23,6 +25,7 @@ const (
 	monitorMediumWidth  = 80
 	monitorMediumHeight = 16
 	monitorRecentLimit  = 20
+	monitorAnomalyLimit = 5
 )
 
 type monitorLayoutMode int
@@ -46,6 +49,7 @@ type MonitorView struct {
 	chart         *tview.TextView
 	reliability   *tview.TextView
 	recent        *tview.TextView
+	anomalies     *tview.TextView
 	mediumSummary *tview.TextView
 	compact       *tview.TextView
 	empty         *tview.TextView
@@ -53,9 +57,21 @@ type MonitorView struct {
 	dagID        string
 	runs         []models.DAGRun
 	tasks        []models.TaskInstance
+	slow         []metrics.Anomaly // top anomalies, recomputed only when tasks change
 	state        string
 	errorMessage string
 	layoutMode   monitorLayoutMode
+
+	// Fleet mode: every DAG's aggregates over the window (cache dashboard rows).
+	fleet           *tview.Table
+	fleetHeader     *tview.TextView
+	fleetRows       []cache.DagDashboardRow
+	fleetRuns       map[string][]models.DAGRun
+	fleetSort       int
+	fleetState      string
+	showFleet       bool
+	fleetMode       atomic.Bool // mirrors showFleet || dagID == "" for pollers
+	onFleetSelected func(dagId string)
 }
 
 func NewMonitorView() *MonitorView {
@@ -67,10 +83,23 @@ func NewMonitorView() *MonitorView {
 		chart:         monitorPanel(" Run duration & outcome "),
 		reliability:   monitorPanel(" Reliability "),
 		recent:        monitorPanel(" Recent runs "),
+		anomalies:     monitorPanel(" Slow tasks "),
 		mediumSummary: monitorText(),
 		compact:       monitorText(),
 		empty:         monitorText().SetTextAlign(tview.AlignCenter),
+		fleet:         newFleetTable(),
+		fleetHeader:   monitorText(),
 	}
+	v.fleet.SetSelectedFunc(func(row, _ int) {
+		if row < 1 || row > len(v.fleetRows) {
+			return
+		}
+		v.showFleet = false
+		v.syncFleetMode()
+		if v.onFleetSelected != nil {
+			v.onFleetSelected(v.fleetRows[row-1].DagId)
+		}
+	})
 	v.SetBorder(true).SetTitle(" Monitor ")
 	for _, title := range []string{" Success rate ", " Failed ", " P90 duration ", " Queue avg "} {
 		card := monitorPanel(title).SetTextAlign(tview.AlignCenter)
@@ -78,6 +107,7 @@ func NewMonitorView() *MonitorView {
 		v.kpis.AddItem(card, 0, 1, false)
 	}
 	v.body.AddItem(v.chart, 0, 7, false).AddItem(v.reliability, 0, 3, false)
+	v.fleetMode.Store(true)
 	v.Update("", nil, nil)
 	return v
 }
@@ -108,8 +138,10 @@ func (v *MonitorView) CycleWindow(delta int) {
 
 func (v *MonitorView) Update(dagID string, runs []models.DAGRun, tasks []models.TaskInstance) {
 	v.dagID = dagID
+	v.syncFleetMode()
 	v.runs = append(v.runs[:0], runs...)
 	v.tasks = append(v.tasks[:0], tasks...)
+	v.slow = metrics.TopAnomalies(v.tasks, time.Now(), monitorAnomalyLimit)
 	v.state = ""
 	v.errorMessage = ""
 	v.renderSnapshot(80, 10)
@@ -122,8 +154,10 @@ func (v *MonitorView) SetLoading(dagID string) {
 	if dagID != v.dagID {
 		v.runs = v.runs[:0]
 		v.tasks = v.tasks[:0]
+		v.slow = nil
 	}
 	v.dagID = dagID
+	v.syncFleetMode()
 	v.state = "loading"
 	v.errorMessage = ""
 	v.renderSnapshot(80, 10)
@@ -136,8 +170,10 @@ func (v *MonitorView) SetError(dagID, message string) {
 	if dagID != v.dagID {
 		v.runs = v.runs[:0]
 		v.tasks = v.tasks[:0]
+		v.slow = nil
 	}
 	v.dagID = dagID
+	v.syncFleetMode()
 	v.state = "error"
 	v.errorMessage = tview.Escape(message)
 	v.renderSnapshot(80, 10)
@@ -165,6 +201,10 @@ func (v *MonitorView) Draw(screen tcell.Screen) {
 
 func (v *MonitorView) rebuildLayout() {
 	v.Clear().SetDirection(tview.FlexRow)
+	if v.FleetMode() {
+		v.AddItem(v.fleetHeader, 2, 0, false).AddItem(v.fleet, 0, 1, false)
+		return
+	}
 	if v.dagID == "" || (v.state != "" && len(v.runs) == 0) {
 		v.AddItem(v.empty, 0, 1, false)
 		return
@@ -178,12 +218,15 @@ func (v *MonitorView) rebuildLayout() {
 		v.AddItem(v.header, 2, 0, false).
 			AddItem(v.kpis, 4, 0, false).
 			AddItem(v.body, 0, 1, false).
-			AddItem(v.mediumSummary, 2, 0, false)
+			AddItem(v.mediumSummary, 3, 0, false)
 		return
 	}
+	side := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(v.reliability, 0, 1, false).
+		AddItem(v.anomalies, 0, 1, false)
 	v.body.Clear().SetDirection(tview.FlexColumn).
 		AddItem(v.chart, 0, 7, false).
-		AddItem(v.reliability, 0, 3, false)
+		AddItem(side, 0, 3, false)
 	v.AddItem(v.header, 2, 0, false).
 		AddItem(v.kpis, 5, 0, false).
 		AddItem(v.body, 0, 1, false).
@@ -191,9 +234,9 @@ func (v *MonitorView) rebuildLayout() {
 }
 
 func (v *MonitorView) renderSnapshot(width, height int) {
-	if v.dagID == "" {
-		v.empty.SetText("\n[gray]Select a DAG from the list above to view run health.[-]\n[yellow]No DAG selected[-]")
-		v.compact.SetText("[gray]Select a DAG from the list")
+	if v.FleetMode() {
+		v.renderFleet()
+		v.compact.SetText("[gray]All DAGs · Select a DAG from the list, or press Enter on a row")
 		return
 	}
 	if v.state == "loading" && len(v.runs) == 0 {
@@ -236,8 +279,10 @@ func (v *MonitorView) renderSnapshot(width, height int) {
 	v.reliability.SetText(renderReliability(success, failed, terminal, streak, flaky, len(v.tasks), trend, max(innerWidth*3/10-4, 8)))
 	recentLimit := min(monitorRecentLimit, max((innerWidth-2)/7, 1))
 	v.recent.SetText(renderRecentRuns(v.runs, recentLimit))
-	v.mediumSummary.SetText(renderMediumSummary(success, failed, terminal, streak, flaky, len(v.tasks), trend, v.runs))
-	v.compact.SetText(renderCompactMonitor(v.dagID, v.windowSelector(), v.runs, v.tasks))
+	v.anomalies.SetText(renderAnomalies(v.slow, len(v.tasks)))
+	v.mediumSummary.SetText(renderMediumSummary(success, failed, terminal, streak, flaky, len(v.tasks), trend, v.runs) +
+		"\n[yellow]Slow tasks[-]   " + anomalySummary(v.slow, 3))
+	v.compact.SetText(renderCompactMonitor(v.dagID, v.windowSelector(), v.runs, v.tasks, v.slow))
 	if v.state == "error" {
 		v.compact.SetText(fmt.Sprintf("[red]stale · %s[-]\n%s", v.errorMessage, v.compact.GetText(false)))
 	}
@@ -293,7 +338,7 @@ func observedDuration(d time.Duration) string {
 	return formatDuration(d)
 }
 
-func renderCompactMonitor(dagID, window string, runs []models.DAGRun, tasks []models.TaskInstance) string {
+func renderCompactMonitor(dagID, window string, runs []models.DAGRun, tasks []models.TaskInstance, slow []metrics.Anomaly) string {
 	success, failed := metrics.SuccessFailed(runs)
 	terminal := success + failed
 	rate := "—"
@@ -313,10 +358,11 @@ func renderCompactMonitor(dagID, window string, runs []models.DAGRun, tasks []mo
 		"[white::b]%s[-::-]   [gray]window:[-] %s\n"+
 			"[yellow]Reliability[-]  runs %d | success %s (%s) | failed %s | streak %s | flaky %s\n"+
 			"[yellow]Latency[-]      p50 %s | p90 %s | p99 %s | queue avg %s\n"+
-			"[yellow]Recent[-]       %s   trend %s",
+			"[yellow]Recent[-]       %s   trend %s\n"+
+			"[yellow]Slow tasks[-]   %s",
 		dagID, window, len(runs), successValue, rate, failedValue, streakValue, flakyValue,
 		observedDuration(p50), observedDuration(p90), observedDuration(p99), observedDuration(metrics.AvgQueueTime(tasks)),
-		renderSparkline(runs), metrics.Trend(runs),
+		renderSparkline(runs), metrics.Trend(runs), anomalySummary(slow, 3),
 	)
 }
 
@@ -355,6 +401,39 @@ func renderMediumSummary(success, failed, terminal, streak, flaky, taskSamples i
 	)
 }
 
+// renderAnomalies lists the window's slowest outliers (metrics.TopAnomalies),
+// one task per line, each against its own median over the same window.
+func renderAnomalies(slow []metrics.Anomaly, taskSamples int) string {
+	if len(slow) == 0 {
+		if taskSamples == 0 {
+			return "[gray]No task samples[-]"
+		}
+		return fmt.Sprintf("[gray]No slow outliers[-]\n[gray](needs %d successful runs per task)[-]", metrics.MinBaselineSamples)
+	}
+	var b strings.Builder
+	for i, a := range slow {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "[yellow]⚠[-] %s  %s [gray](median %s, z %s)[-]",
+			tview.Escape(truncate(a.TaskId, 24)), formatDuration(a.Duration), formatDuration(a.Baseline.Median), formatZ(a.Z))
+	}
+	return b.String()
+}
+
+// anomalySummary is the one-line form of renderAnomalies for small layouts.
+func anomalySummary(slow []metrics.Anomaly, limit int) string {
+	if len(slow) == 0 {
+		return "[gray]none[-]"
+	}
+	slow = slow[:min(limit, len(slow))]
+	parts := make([]string, len(slow))
+	for i, a := range slow {
+		parts[i] = fmt.Sprintf("%s +%s", tview.Escape(a.TaskId), formatDuration(a.Duration-a.Baseline.Median))
+	}
+	return strings.Join(parts, " | ")
+}
+
 func renderHorizontalBar(value, total, width int, color string) string {
 	if width <= 0 || total <= 0 {
 		return ""
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/yjinheon/lazyflow/internal/cache"
 	"github.com/yjinheon/lazyflow/pkg/airflow/models"
 )
 
@@ -191,3 +193,67 @@ func TestMonitorView_cycleWindow(t *testing.T) {
 		t.Fatalf("wrap back = %v, want 24h", v.Window())
 	}
 }
+
+func TestMonitorView_wideDashboardListsSlowTasks(t *testing.T) {
+	now := time.Now()
+	runs := []models.DAGRun{{State: "success", RunAfter: now.Add(-time.Hour), StartDate: now.Add(-70 * time.Minute), EndDate: now.Add(-time.Hour)}}
+	var tasks []models.TaskInstance
+	for i, secs := range []float64{60, 61, 59, 62, 60, 58, 900} {
+		tasks = append(tasks, models.TaskInstance{TaskId: "transform", RunId: string(rune('a' + i)), State: "success", Duration: secs})
+	}
+	v := NewMonitorView()
+	v.Update("etl_daily", runs, tasks)
+
+	got := drawMonitor(t, v, 120, 28)
+	for _, want := range []string{"Slow tasks", "transform", "15m0s"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("wide dashboard missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestMonitorView_fleet(t *testing.T) {
+	v := NewMonitorView()
+	if !v.FleetMode() {
+		t.Fatal("fleet table should show before a DAG is selected")
+	}
+	now := time.Now()
+	v.UpdateFleet([]cache.DagDashboardRow{
+		{DagId: "steady", Runs: 10, Success: 10, AvgDuration: time.Minute},
+		{DagId: "flaky", Runs: 10, Success: 6, Failed: 4, AvgDuration: 5 * time.Minute},
+		{DagId: "slow", Runs: 3, Success: 3, AvgDuration: time.Hour},
+	}, []models.DAGRun{{DagId: "flaky", State: "failed", RunAfter: now}})
+
+	got := drawMonitor(t, v, 140, 12)
+	for _, want := range []string{"All DAGs", "sort: failed", "60%", "✗"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("fleet missing %q:\n%s", want, got)
+		}
+	}
+	if v.fleetRows[0].DagId != "flaky" {
+		t.Fatalf("default order starts with %q, want the DAG with failures", v.fleetRows[0].DagId)
+	}
+	v.CycleFleetSort() // success rate, worst first
+	v.CycleFleetSort() // runs
+	v.CycleFleetSort() // avg duration
+	if v.fleetRows[0].DagId != "slow" {
+		t.Fatalf("avg-duration order starts with %q, want slow", v.fleetRows[0].DagId)
+	}
+
+	var picked string
+	v.SetOnFleetSelected(func(dagId string) { picked = dagId })
+	v.fleet.Select(1, 0)
+	v.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(tview.Primitive) {})
+	if picked != "slow" {
+		t.Fatalf("Enter picked %q, want slow", picked)
+	}
+
+	v.Update("slow", nil, nil)
+	if v.FleetMode() {
+		t.Fatal("a selected DAG should show its own dashboard")
+	}
+	v.ToggleFleet()
+	if !v.FleetMode() {
+		t.Fatal("F should bring the fleet table back")
+	}
+}
@@ -17,6 +17,7 @@ type RunsView struct {
 	stateFilter string          // "" = all
 	since       time.Time       // window cutoff for success/failed (zero = none)
 	activeRunId string          // committed via Enter; distinct from the cursor row
+	marked      []string        // runs picked for compare, oldest mark first (max 2)
 	onSelected  func(runId string)
 }
 
@@ -43,7 +44,7 @@ func (v *RunsView) setup() {
 	v.SetFocusFunc(func() { v.SetBorderColor(theme.ActiveTheme().BorderFocused) })
 	v.SetBlurFunc(func() { v.SetBorderColor(theme.ActiveTheme().BorderColor) })
 
-	headers := []string{"Run ID", "State", "Start", "End", "Duration", "Type"}
+	headers := []string{"Run ID", "State", "Start", "End", "Duration", "Type", "Note"}
 	for i, h := range headers {
 		cell := tview.NewTableCell(h).
 			SetTextColor(theme.ActiveTheme().TableHeaderText).
@@ -83,6 +84,87 @@ func (v *RunsView) SetOnSelected(handler func(runId string)) {
 	v.onSelected = handler
 }
 
+// SelectRun commits runId as if Enter were pressed on its row, moving the
+// cursor there when the current filter shows it.
+func (v *RunsView) SelectRun(runId string) {
+	for i, r := range v.runs {
+		if r.RunId == runId {
+			v.Select(i+1, 0)
+			break
+		}
+	}
+	v.setActiveRun(runId)
+	if v.onSelected != nil {
+		v.onSelected(runId)
+	}
+}
+
+// CurrentRun returns the run under the cursor, which may differ from the run
+// committed with Enter. Row actions (view conf, re-trigger, edit note) act on
+// this one.
+func (v *RunsView) CurrentRun() (models.DAGRun, bool) {
+	row, _ := v.GetSelection()
+	if row <= 0 || row > len(v.runs) {
+		return models.DAGRun{}, false
+	}
+	return v.runs[row-1], true
+}
+
+// ToggleMark marks or unmarks the cursor row for comparison. At most two runs
+// stay marked; marking a third drops the oldest mark.
+func (v *RunsView) ToggleMark() {
+	run, ok := v.CurrentRun()
+	if !ok {
+		return
+	}
+	for i, id := range v.marked {
+		if id == run.RunId {
+			v.marked = append(v.marked[:i], v.marked[i+1:]...)
+			v.relabel()
+			return
+		}
+	}
+	v.marked = append(v.marked, run.RunId)
+	if len(v.marked) > 2 {
+		v.marked = v.marked[len(v.marked)-2:]
+	}
+	v.relabel()
+}
+
+// MarkedRuns returns the marked runs still present in the current data,
+// baseline first: the one with the earlier logical date (falling back to
+// mark order) becomes A.
+func (v *RunsView) MarkedRuns() []models.DAGRun {
+	var out []models.DAGRun
+	for _, id := range v.marked {
+		for _, r := range v.allRuns {
+			if r.RunId == id {
+				out = append(out, r)
+				break
+			}
+		}
+	}
+	if len(out) == 2 && runRecency(out[1]).Before(runRecency(out[0])) {
+		out[0], out[1] = out[1], out[0]
+	}
+	return out
+}
+
+// ClearMarks drops all compare marks.
+func (v *RunsView) ClearMarks() {
+	v.marked = nil
+	v.relabel()
+}
+
+func (v *RunsView) markOf(runId string) string {
+	for i, id := range v.marked {
+		if id == runId {
+			return fmt.Sprintf(" ◆%d", i+1)
+		}
+	}
+	return ""
+}
+
 // SetStateFilter narrows the displayed runs to a single state. The window
 // (since) applies to success/failed only — running is current by definition —
 // matching metrics.CountWindowStates so the DagInfo counts equal the row count.
@@ -138,13 +220,18 @@ func (v *RunsView) setActiveRun(runId string) {
 		return
 	}
 	v.activeRunId = runId
+	v.relabel()
+}
+
+// relabel rewrites the Run ID cells for the active row and compare marks.
+func (v *RunsView) relabel() {
 	for i, r := range v.runs {
 		cell := v.GetCell(i+1, 0)
 		if cell == nil {
 			continue
 		}
-		active := r.RunId == runId
-		cell.SetText(rowLabel(r.RunId, active)).SetTextColor(rowLabelColor(active))
+		active := r.RunId == v.activeRunId
+		cell.SetText(rowLabel(r.RunId, active) + v.markOf(r.RunId)).SetTextColor(rowLabelColor(active))
 	}
 }
 
@@ -167,7 +254,7 @@ func (v *RunsView) render() {
 		}
 
 		active := run.RunId == v.activeRunId
-		v.SetCell(row, 0, tview.NewTableCell(rowLabel(run.RunId, active)).
+		v.SetCell(row, 0, tview.NewTableCell(rowLabel(run.RunId, active)+v.markOf(run.RunId)).
 			SetTextColor(rowLabelColor(active)).SetExpansion(1).SetBackgroundColor(bg))
 
 		symbol, color := t.StatusStyle(run.State)
@@ -193,6 +280,9 @@ func (v *RunsView) render() {
 
 		v.SetCell(row, 5, tview.NewTableCell(run.RunType).
 			SetTextColor(t.PrimaryText).SetBackgroundColor(bg))
+
+		v.SetCell(row, 6, tview.NewTableCell(tview.Escape(notePreview(run.Note, 30))).
+			SetTextColor(t.MutedText).SetBackgroundColor(bg))
 	}
 }
 
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/rivo/tview"
 	"github.com/yjinheon/lazyflow/pkg/airflow/models"
 )
 
@@ -46,3 +47,59 @@ func runIDs(runs []models.DAGRun) []string {
 	}
 	return ids
 }
+
+func TestNotePreview(t *testing.T) {
+	cases := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{"", 10, ""},
+		{"  rerun after fix  ", 20, "rerun after fix"},
+		{"first line\nsecond", 20, "first line …"},
+		{"a very long single line note", 10, "a very lo…"},
+		{"trailing\n\n", 20, "trailing"},
+	}
+	for _, c := range cases {
+		if got := notePreview(c.in, c.n); got != c.want {
+			t.Errorf("notePreview(%q, %d) = %q, want %q", c.in, c.n, got, c.want)
+		}
+	}
+}
+
+func TestRunsViewShowsNote(t *testing.T) {
+	v := NewRunsView()
+	v.Update([]models.DAGRun{{RunId: "r1", State: "success", Note: "fixed [manually]\nsee ticket"}})
+	cell := v.GetCell(1, 6)
+	if cell == nil || cell.Text != tview.Escape("fixed [manually] …") {
+		t.Fatalf("note cell = %+v", cell)
+	}
+}
+
+func TestRunsViewMarksKeepTwoOrderedByDate(t *testing.T) {
+	base := time.Date(2026, 6, 10, 0, 0, 0, 0, time.UTC)
+	v := NewRunsView()
+	v.Update([]models.DAGRun{
+		{RunId: "new", LogicalDate: base.Add(48 * time.Hour)},
+		{RunId: "mid", LogicalDate: base.Add(24 * time.Hour)},
+		{RunId: "old", LogicalDate: base},
+	})
+
+	mark := func(row int) {
+		v.Select(row, 0)
+		v.ToggleMark()
+	}
+	mark(1) // new
+	mark(3) // old
+	mark(2) // mid: drops "new", the oldest mark
+
+	got := runIDs(v.MarkedRuns())
+	if len(got) != 2 || got[0] != "old" || got[1] != "mid" {
+		t.Fatalf("marked = %v, want [old mid] (baseline first)", got)
+	}
+
+	mark(3) // unmark old
+	if got := runIDs(v.MarkedRuns()); len(got) != 1 || got[0] != "mid" {
+		t.Fatalf("after unmark = %v, want [mid]", got)
+	}
+}
@@ -0,0 +1,107 @@
+package views
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/yjinheon/lazyflow/internal/metrics"
+	"github.com/yjinheon/lazyflow/internal/ui/theme"
+)
+
+// SLAView lists the DAGs currently breaching their configured SLA, most late
+// first. Enter jumps to the DAG's runs.
+type SLAView struct {
+	*tview.Table
+	breaches   []metrics.SLABreach
+	configured int // DAGs with an SLA in the config
+	onSelected func(dagId string)
+}
+
+func NewSLAView() *SLAView {
+	v := &SLAView{Table: tview.NewTable()}
+	v.SetBorder(true).SetTitle(" SLA Breaches ")
+	v.SetFixed(1, 0)
+	v.SetSelectedStyle(tcell.StyleDefault.
+		Background(theme.ActiveTheme().TableSelected).
+		Foreground(theme.ActiveTheme().PrimaryText).
+		Attributes(tcell.AttrBold))
+	v.SetFocusFunc(func() { v.SetBorderColor(theme.ActiveTheme().BorderFocused) })
+	v.SetBlurFunc(func() { v.SetBorderColor(theme.ActiveTheme().BorderColor) })
+	v.SetSelectedFunc(func(row, _ int) {
+		if row > 0 && row <= len(v.breaches) && v.onSelected != nil {
+			v.onSelected(v.breaches[row-1].DagId)
+		}
+	})
+	v.render()
+	return v
+}
+
+// SetOnSelected registers the callback fired when Enter is pressed on a row.
+func (v *SLAView) SetOnSelected(fn func(dagId string)) { v.onSelected = fn }
+
+// Update replaces the breach list; configured is how many DAGs declare an SLA,
+// which decides the empty-state hint.
+func (v *SLAView) Update(breaches []metrics.SLABreach, configured int) {
+	v.breaches = append([]metrics.SLABreach(nil), breaches...)
+	metrics.SortBreaches(v.breaches)
+	v.configured = configured
+	v.render()
+}
+
+func (v *SLAView) render() {
+	v.Clear()
+	t := theme.ActiveTheme()
+	headers := []string{"DAG", "Rule", "Late by", "Run", "Detail"}
+	for i, h := range headers {
+		cell := tview.NewTableCell(h).
+			SetTextColor(t.TableHeaderText).
+			SetSelectable(false)
+		if i == len(headers)-1 {
+			cell.SetExpansion(1)
+		}
+		v.SetCell(0, i, cell)
+	}
+	if len(v.breaches) == 0 {
+		// keep table non-selectable while empty (see RunsView.setup)
+		v.SetSelectable(false, false)
+		setEmptyHint(v.Table, v.emptyHint())
+		return
+	}
+	v.SetSelectable(true, false)
+
+	for i, b := range v.breaches {
+		row := i + 1
+		bg := t.PrimaryBg
+		if row%2 == 0 {
+			bg = t.TableRowAlt
+		}
+		late := "—"
+		if b.Late > 0 {
+			late = metrics.FormatLate(b.Late)
+		}
+		cells := []struct {
+			text  string
+			color tcell.Color
+		}{
+			{b.DagId, t.PrimaryText},
+			{b.Kind, t.Accent},
+			{late, t.StatusFailed},
+			{b.RunId, t.MutedText},
+			{b.Detail, t.PrimaryText},
+		}
+		for c, cell := range cells {
+			v.SetCell(row, c, tview.NewTableCell(tview.Escape(cell.text)).
+				SetTextColor(cell.color).SetBackgroundColor(bg))
+		}
+	}
+}
+
+func (v *SLAView) emptyHint() string {
+	if v.configured == 0 {
+		return "No SLAs configured — add an sla: section to the lazyflow config."
+	}
+	return "All SLAs met."
+}
+
+func (v *SLAView) Root() *tview.Table {
+	return v.Table
+}
@@ -0,0 +1,50 @@
+package views
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/yjinheon/lazyflow/internal/metrics"
+)
+
+func TestSLAViewOrdersByLateness(t *testing.T) {
+	v := NewSLAView()
+	if got := v.GetCell(1, 0).Text; !strings.Contains(got, "No SLAs configured") {
+		t.Fatalf("empty hint = %q, want the not-configured hint", got)
+	}
+
+	v.Update(nil, 2)
+	if got := v.GetCell(1, 0).Text; got != "All SLAs met." {
+		t.Fatalf("empty hint = %q, want all met", got)
+	}
+
+	var picked string
+	v.SetOnSelected(func(dagId string) { picked = dagId })
+	v.Update([]metrics.SLABreach{
+		{DagId: "a", Kind: metrics.SLASuccessWithin, Detail: "no success"},
+		{DagId: "b", Kind: metrics.SLAFinishBy, Late: 2 * time.Hour},
+		{DagId: "c", Kind: metrics.SLAMaxDuration, Late: 10 * time.Minute},
+	}, 3)
+	var order []string
+	for row := 1; row <= 3; row++ {
+		order = append(order, v.GetCell(row, 0).Text)
+	}
+	if strings.Join(order, ",") != "b,c,a" {
+		t.Fatalf("order = %v, want b,c,a", order)
+	}
+	if got := v.GetCell(1, 2).Text; got != "2h00m" {
+		t.Errorf("late = %q, want 2h00m", got)
+	}
+	if got := v.GetCell(3, 2).Text; got != "—" {
+		t.Errorf("unmeasured late = %q, want —", got)
+	}
+
+	v.Select(2, 0)
+	v.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(p tview.Primitive) {})
+	if picked != "c" {
+		t.Errorf("Enter picked %q, want c", picked)
+	}
+}
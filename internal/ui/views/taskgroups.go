@@ -0,0 +1,204 @@
+package views
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+// groupStatePriority orders task states for summing up a task group: the
+// group shows the first state any of its tasks is in.
+var groupStatePriority = []string{
+	"failed", "upstream_failed", "up_for_retry", "up_for_reschedule",
+	"running", "restarting", "deferred", "queued", "scheduled",
+	"", "success", "skipped", "removed",
+}
+
+// groupState sums up the states of a group's tasks: a failure anywhere wins,
+// then work in progress, then work not started; a group is only success or
+// skipped once every task is.
+func groupState(states []string) string {
+	best := len(groupStatePriority)
+	for _, s := range states {
+		i := slices.Index(groupStatePriority, s)
+		if i < 0 {
+			i = slices.Index(groupStatePriority, "")
+		}
+		best = min(best, i)
+	}
+	if best == len(groupStatePriority) {
+		return ""
+	}
+	return groupStatePriority[best]
+}
+
+// groupLabel is a group's or task's id without the prefix of the group it
+// sits in: "load.marts" inside "load" reads "marts".
+func groupLabel(id string, parents []string) string {
+	if len(parents) == 0 {
+		return id
+	}
+	return strings.TrimPrefix(id, parents[len(parents)-1]+".")
+}
+
+// collapseGroups folds every task group that is not in expanded into a single
+// node standing in for its tasks. A group node has the group id as TaskId,
+// its enclosing groups as Groups, and the edges of its tasks to the outside.
+// members maps each group node to the tasks it folds. Edges to tasks not in
+// the list are dropped, so a group's tasks collapse on their own.
+func collapseGroups(tasks []models.Task, expanded map[string]bool) (units []models.Task, members map[string][]models.Task) {
+	unitOf := make(map[string]string, len(tasks))
+	index := map[string]int{}
+	members = map[string][]models.Task{}
+	for _, t := range tasks {
+		id, depth := t.TaskId, -1
+		for i, g := range t.Groups {
+			if !expanded[g] {
+				id, depth = g, i
+				break
+			}
+		}
+		unitOf[t.TaskId] = id
+		if depth >= 0 {
+			members[id] = append(members[id], t)
+		}
+		if _, ok := index[id]; ok {
+			continue
+		}
+		index[id] = len(units)
+		if depth >= 0 {
+			units = append(units, models.Task{TaskId: id, Groups: t.Groups[:depth:depth]})
+		} else {
+			u := t
+			u.UpstreamTaskIds, u.DownstreamTaskIds = nil, nil
+			units = append(units, u)
+		}
+	}
+	for _, t := range tasks {
+		to := unitOf[t.TaskId]
+		for _, up := range t.UpstreamTaskIds {
+			from, ok := unitOf[up]
+			if !ok || from == to {
+				continue
+			}
+			u, d := &units[index[to]], &units[index[from]]
+			if !slices.Contains(u.UpstreamTaskIds, from) {
+				u.UpstreamTaskIds = append(u.UpstreamTaskIds, from)
+				d.DownstreamTaskIds = append(d.DownstreamTaskIds, to)
+			}
+		}
+	}
+	return units, members
+}
+
+// groupRow is one line of a grouped task list: a group header or an item.
+type groupRow struct {
+	group   string // the group id on a header, "" on an item
+	parents []string
+	index   int   // the item's position in the input; -1 on a header
+	members []int // a header's items, nested groups included
+}
+
+// key identifies the row across refreshes.
+func (r groupRow) key(ids []string) string {
+	if r.group != "" {
+		return r.group
+	}
+	return ids[r.index]
+}
+
+// label indents the row by its depth; headers carry an open/closed marker
+// and their size.
+func (r groupRow) label(ids []string, expanded bool) string {
+	indent := strings.Repeat("  ", len(r.parents))
+	if r.group == "" {
+		return indent + groupLabel(ids[r.index], r.parents)
+	}
+	marker := "▸ "
+	if expanded {
+		marker = "▾ "
+	}
+	return fmt.Sprintf("%s%s%s (%d)", indent, marker, groupLabel(r.group, r.parents), len(r.members))
+}
+
+// groupedRows lays out ids under their task-group headers. groupsOf gives an
+// id's groups, outermost first. A group's items are gathered where its first
+// item appears; a group not in expanded shows its header only.
+func groupedRows(ids []string, groupsOf map[string][]string, expanded map[string]bool) []groupRow {
+	type node struct {
+		group    string
+		index    int
+		children []*node
+	}
+	root := &node{}
+	groups := map[string]*node{}
+	for i, id := range ids {
+		parent := root
+		for _, g := range groupsOf[id] {
+			n, ok := groups[g]
+			if !ok {
+				n = &node{group: g, index: -1}
+				groups[g] = n
+				parent.children = append(parent.children, n)
+			}
+			parent = n
+		}
+		parent.children = append(parent.children, &node{index: i})
+	}
+
+	var items func(n *node) []int
+	items = func(n *node) []int {
+		if n.group == "" {
+			return []int{n.index}
+		}
+		var out []int
+		for _, c := range n.children {
+			out = append(out, items(c)...)
+		}
+		return out
+	}
+	var rows []groupRow
+	var walk func(n *node, parents []string)
+	walk = func(n *node, parents []string) {
+		for _, c := range n.children {
+			if c.group == "" {
+				rows = append(rows, groupRow{parents: parents, index: c.index})
+				continue
+			}
+			rows = append(rows, groupRow{group: c.group, parents: parents, index: -1, members: items(c)})
+			if expanded[c.group] {
+				walk(c, append(parents[:len(parents):len(parents)], c.group))
+			}
+		}
+	}
+	walk(root, nil)
+	return rows
+}
+
+// taskGroupsOf maps each task id to its groups.
+func taskGroupsOf(tasks []models.Task) map[string][]string {
+	out := make(map[string][]string, len(tasks))
+	for _, t := range tasks {
+		if len(t.Groups) > 0 {
+			out[t.TaskId] = t.Groups
+		}
+	}
+	return out
+}
+
+// groupNodeLabels names the nodes of a collapsed graph that sit in a group:
+// tasks by their name inside the group, group nodes by a closed marker, the
+// group's own name and how many tasks it folds, cut to fit.
+func groupNodeLabels(units []models.Task, members map[string][]models.Task) map[string]string {
+	labels := map[string]string{}
+	for _, u := range units {
+		if m, ok := members[u.TaskId]; ok {
+			labels[u.TaskId] = "▸ " + truncate(fmt.Sprintf("%s (%d)", groupLabel(u.TaskId, u.Groups), len(m)), graphNodeLabel-2)
+		} else if len(u.Groups) > 0 {
+			labels[u.TaskId] = truncate(groupLabel(u.TaskId, u.Groups), graphNodeLabel)
+		}
+	}
+	return labels
+}
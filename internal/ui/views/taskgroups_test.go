@@ -0,0 +1,126 @@
+package views
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/yjinheon/lazyflow/pkg/airflow/models"
+)
+
+// groupedTasks: start feeds an extract group of two tasks, which feeds load;
+// load.marts nests inside load.
+func groupedTasks() []models.Task {
+	return []models.Task{
+		{TaskId: "start"},
+		{TaskId: "extract.orders", UpstreamTaskIds: []string{"start"}, Groups: []string{"extract"}},
+		{TaskId: "extract.users", UpstreamTaskIds: []string{"start"}, Groups: []string{"extract"}},
+		{TaskId: "load.warehouse", UpstreamTaskIds: []string{"extract.orders", "extract.users"}, Groups: []string{"load"}},
+		{TaskId: "load.marts.sales", UpstreamTaskIds: []string{"load.warehouse"}, Groups: []string{"load", "load.marts"}},
+		{TaskId: "notify", UpstreamTaskIds: []string{"load.marts.sales"}},
+	}
+}
+
+func unitIds(units []models.Task) string {
+	ids := make([]string, len(units))
+	for i, u := range units {
+		ids[i] = u.TaskId + "<" + strings.Join(u.UpstreamTaskIds, "+")
+	}
+	return strings.Join(ids, " ")
+}
+
+func TestCollapseGroups(t *testing.T) {
+	units, members := collapseGroups(groupedTasks(), nil)
+	if got := unitIds(units); got != "start< extract<start load<extract notify<load" {
+		t.Fatalf("collapsed = %s", got)
+	}
+	if len(members["extract"]) != 2 || len(members["load"]) != 2 {
+		t.Fatalf("members = %v", members)
+	}
+
+	units, members = collapseGroups(groupedTasks(), map[string]bool{"load": true})
+	if got := unitIds(units); got != "start< extract<start load.warehouse<extract load.marts<load.warehouse notify<load.marts" {
+		t.Fatalf("load open = %s", got)
+	}
+	if _, ok := members["load"]; ok {
+		t.Fatal("an open group has no node")
+	}
+	for _, u := range units {
+		if u.TaskId == "load.marts" && (len(u.Groups) != 1 || u.Groups[0] != "load") {
+			t.Fatalf("load.marts sits in %v", u.Groups)
+		}
+	}
+}
+
+func TestGroupState(t *testing.T) {
+	cases := []struct {
+		states []string
+		want   string
+	}{
+		{[]string{"success", "success"}, "success"},
+		{[]string{"success", "running", "failed"}, "failed"},
+		{[]string{"success", "queued", "running"}, "running"},
+		{[]string{"success", ""}, ""},
+		{[]string{"skipped", "success"}, "success"},
+		{nil, ""},
+	}
+	for _, c := range cases {
+		if got := groupState(c.states); got != c.want {
+			t.Errorf("groupState(%v) = %q, want %q", c.states, got, c.want)
+		}
+	}
+}
+
+func TestGroupedRows(t *testing.T) {
+	ids := []string{"start", "extract.orders", "notify", "extract.users", "load.marts.sales"}
+	groups := taskGroupsOf(groupedTasks())
+	label := func(expanded map[string]bool) string {
+		var out []string
+		for _, r := range groupedRows(ids, groups, expanded) {
+			out = append(out, r.label(ids, expanded[r.group]))
+		}
+		return strings.Join(out, "|")
+	}
+	if got := label(nil); got != "start|▸ extract (2)|notify|▸ load (1)" {
+		t.Fatalf("closed = %s", got)
+	}
+	// A group's tasks are gathered under its header.
+	if got := label(map[string]bool{"extract": true, "load": true}); got != "start|▾ extract (2)|  orders|  users|notify|▾ load (1)|  ▸ marts (1)" {
+		t.Fatalf("open = %s", got)
+	}
+}
+
+func TestGraphView_collapsesGroups(t *testing.T) {
+	v := NewGraphView()
+	v.SetRect(0, 0, 120, 20)
+	v.SetTasks(groupedTasks())
+	v.SetStates(map[string]string{"extract.orders": "success", "extract.users": "failed"})
+
+	graphKey(v, tcell.KeyRight, 0)
+	if v.Selected() != "extract" || !v.IsGroup("extract") {
+		t.Fatalf("cursor on %q, want the extract group's node", v.Selected())
+	}
+	if got := v.state("extract"); got != "failed" {
+		t.Fatalf("extract group state = %q", got)
+	}
+	if !strings.Contains(v.GetText(false), "▸ extract (2)") {
+		t.Fatalf("group node label missing\n%s", v.GetText(false))
+	}
+
+	var opened string
+	v.SetOnSelected(func(id string) { opened = id })
+	graphKey(v, tcell.KeyEnter, 0)
+	if opened != "" || v.IsGroup("extract") || v.Selected() != "extract.orders" {
+		t.Fatalf("Enter on a group should open it: cursor %q, logs %q", v.Selected(), opened)
+	}
+	graphKey(v, tcell.KeyRune, ' ')
+	if v.Selected() != "extract" || !v.IsGroup("extract") {
+		t.Fatalf("space on a member should close its group, cursor on %q", v.Selected())
+	}
+
+	// A refresh keeps groups open and the cursor on the group's node.
+	v.SetTasks(groupedTasks())
+	if v.Selected() != "extract" {
+		t.Fatalf("refresh moved the cursor to %q", v.Selected())
+	}
+}
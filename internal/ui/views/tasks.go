@@ -5,6 +5,7 @@ import (
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+	"github.com/yjinheon/lazyflow/internal/metrics"
 	"github.com/yjinheon/lazyflow/internal/ui/theme"
 	"github.com/yjinheon/lazyflow/pkg/airflow/models"
 )
@@ -19,10 +20,14 @@ type TasksView struct {
 	run   *ExecutionView
 
 	taskDefinitions []models.Task
+	defRows         []groupRow // definitions table rows after the header
+	expanded        map[string]bool
 	activeTaskId    string
+	mapIndex        int // the selected expansion of a mapped task
 	hasRun          bool
 	ganttMode       bool
 	onSelected      func(taskId string)
+	onOpenLogs      func(taskId string)
 }
 
 const (
@@ -37,9 +42,20 @@ func NewTasksView() *TasksView {
 		table: tview.NewTable(),
 		gantt: NewGanttView(),
 		run:   NewExecutionView(),
+
+		expanded: map[string]bool{},
+		mapIndex: models.NotMapped,
 	}
 	v.setupTable()
-	v.run.SetOnTaskSelected(v.selectTask)
+	v.run.SetOnTaskSelected(func(taskId string) {
+		v.selectInstance(taskId, v.run.CurrentMapIndex())
+	})
+	v.gantt.SetOnSelected(func(taskId string) {
+		v.selectInstance(taskId, v.gantt.CurrentMapIndex())
+		if v.onOpenLogs != nil {
+			v.onOpenLogs(taskId)
+		}
+	})
 	v.AddPage(tasksPageDefs, v.table, true, true)
 	v.AddPage(tasksPageRun, v.run.Root(), true, false)
 	v.AddPage(tasksPageGantt, v.gantt, true, false)
@@ -60,8 +76,15 @@ func (v *TasksView) setupTable() {
 	v.renderHeaders([]string{"Task ID", "Operator", "Owner", "Retries", "Trigger", "Pool", "Queue", "Downstream"})
 
 	v.table.SetSelectedFunc(func(row, column int) {
-		if row > 0 && row <= len(v.taskDefinitions) {
-			v.selectTask(v.taskDefinitions[row-1].TaskId)
+		if row <= 0 || row > len(v.defRows) {
+			return
+		}
+		if r := v.defRows[row-1]; r.group != "" {
+			v.expanded[r.group] = !v.expanded[r.group]
+			v.renderDefinitions()
+			v.table.Select(row, 0)
+		} else {
+			v.selectTask(v.taskDefinitions[r.index].TaskId)
 		}
 	})
 }
@@ -80,6 +103,12 @@ func (v *TasksView) renderHeaders(headers []string) {
 }
 
 func (v *TasksView) selectTask(taskId string) {
+	v.selectInstance(taskId, models.NotMapped)
+}
+
+// selectInstance commits a task, or one expansion of a mapped task.
+func (v *TasksView) selectInstance(taskId string, mapIndex int) {
+	v.mapIndex = mapIndex
 	v.setActiveTask(taskId)
 	if v.onSelected != nil {
 		v.onSelected(taskId)
@@ -92,20 +121,51 @@ func (v *TasksView) setActiveTask(taskId string) {
 		return
 	}
 	v.activeTaskId = taskId
-	for i, t := range v.taskDefinitions {
+	ids := v.definitionIds()
+	for i, r := range v.defRows {
 		cell := v.table.GetCell(i+1, 0)
-		if cell == nil {
+		if cell == nil || r.group != "" {
 			continue
 		}
-		active := t.TaskId == taskId
-		cell.SetText(rowLabel(t.TaskId, active)).SetTextColor(rowLabelColor(active))
+		active := ids[r.index] == taskId
+		cell.SetText(rowLabel(r.label(ids, false), active)).SetTextColor(rowLabelColor(active))
 	}
 }
 
+func (v *TasksView) definitionIds() []string {
+	ids := make([]string, len(v.taskDefinitions))
+	for i, t := range v.taskDefinitions {
+		ids[i] = t.TaskId
+	}
+	return ids
+}
+
 func (v *TasksView) SetOnSelected(handler func(taskId string)) {
 	v.onSelected = handler
 }
 
+// SelectTask commits taskId as if Enter were pressed on its row.
+func (v *TasksView) SelectTask(taskId string) {
+	v.selectTask(taskId)
+}
+
+// SelectInstance commits one expansion of a mapped task, or with
+// models.NotMapped a whole task, as if Enter were pressed on its row.
+func (v *TasksView) SelectInstance(taskId string, mapIndex int) {
+	v.selectInstance(taskId, mapIndex)
+}
+
+// SelectedMapIndex is the expansion picked along with the selected task:
+// models.NotMapped unless it was chosen on an expansion's row in the run
+// dashboard or the Gantt.
+func (v *TasksView) SelectedMapIndex() int { return v.mapIndex }
+
+// SetOnOpenLogs is called after Enter on a Gantt row has selected the task;
+// the Gantt has no log pane of its own, so the caller shows the logs tab.
+func (v *TasksView) SetOnOpenLogs(handler func(taskId string)) {
+	v.onOpenLogs = handler
+}
+
 // showActive brings the page matching the current mode to the front.
 func (v *TasksView) showActive() {
 	switch {
@@ -132,22 +192,46 @@ func (v *TasksView) UpdateDefinitions(dagId string, tasks []models.Task) {
 	v.table.Clear()
 	v.setupTable()
 	if len(tasks) == 0 {
+		v.defRows = nil
 		setEmptyHint(v.table, fmt.Sprintf("No DAG tasks loaded for %s", dagId))
 		v.showActive()
 		return
 	}
 	v.table.SetSelectable(true, false)
+	v.renderDefinitions()
+	v.showActive()
+}
+
+// renderDefinitions fills the definitions table. Tasks in task groups sit
+// under a header row per group, closed until Enter opens it.
+func (v *TasksView) renderDefinitions() {
+	ids := v.definitionIds()
+	v.defRows = groupedRows(ids, taskGroupsOf(v.taskDefinitions), v.expanded)
+	for row := v.table.GetRowCount() - 1; row > 0; row-- {
+		v.table.RemoveRow(row)
+	}
 
 	t := theme.ActiveTheme()
-	for i, task := range tasks {
+	for i, r := range v.defRows {
 		row := i + 1
 		bg := t.PrimaryBg
 		if row%2 == 0 {
 			bg = t.TableRowAlt
 		}
 
+		if r.group != "" {
+			v.table.SetCell(row, 0, tview.NewTableCell(inactiveMarker+r.label(ids, v.expanded[r.group])).
+				SetTextColor(t.Accent).SetExpansion(1).SetBackgroundColor(bg))
+			v.table.SetCell(row, 1, tview.NewTableCell("TaskGroup").
+				SetTextColor(t.MutedText).SetBackgroundColor(bg))
+			for col := 2; col < 8; col++ {
+				v.table.SetCell(row, col, tview.NewTableCell("").SetBackgroundColor(bg))
+			}
+			continue
+		}
+		task := v.taskDefinitions[r.index]
 		active := task.TaskId == v.activeTaskId
-		v.table.SetCell(row, 0, tview.NewTableCell(rowLabel(task.TaskId, active)).
+		v.table.SetCell(row, 0, tview.NewTableCell(rowLabel(r.label(ids, false), active)).
 			SetTextColor(rowLabelColor(active)).SetExpansion(1).SetBackgroundColor(bg))
 		v.table.SetCell(row, 1, tview.NewTableCell(task.Operator).
 			SetTextColor(t.PrimaryText).SetBackgroundColor(bg))
@@ -164,7 +248,6 @@ func (v *TasksView) UpdateDefinitions(dagId string, tasks []models.Task) {
 		v.table.SetCell(row, 7, tview.NewTableCell(fmt.Sprintf("%d", len(task.DownstreamTaskIds))).
 			SetTextColor(t.PrimaryText).SetBackgroundColor(bg))
 	}
-	v.showActive()
 }
 
 // SetGanttMode switches which child page is visible.
@@ -178,6 +261,15 @@ func (v *TasksView) UpdateGantt(runId string, tis []models.TaskInstance, onCriti
 	v.gantt.Update(runId, tis, onCritical)
 }
 
+// SetGanttTimings forwards historical task timings for the Gantt's ghost bars.
+func (v *TasksView) SetGanttTimings(runId string, timings map[string]metrics.TaskTiming) {
+	v.gantt.SetTimings(runId, timings)
+}
+
+// ShowingRun reports whether the run dashboard is the visible page, i.e. the
+// cursor in its task list is what row actions should act on.
+func (v *TasksView) ShowingRun() bool { return v.hasRun && !v.ganttMode }
+
 // Run exposes the run dashboard so callers can push logs into its preview pane.
 func (v *TasksView) Run() *ExecutionView { return v.run }
 
@@ -3,6 +3,7 @@ package views
 import (
 	"testing"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/yjinheon/lazyflow/pkg/airflow/models"
 )
@@ -100,3 +101,44 @@ func TestTasksViewOnSelected_firesFromRunDashboard(t *testing.T) {
 		t.Fatalf("onSelected got %q, want %q", got, "extract")
 	}
 }
+
+func TestTasksViewSelectInstance_keepsMapIndex(t *testing.T) {
+	v := NewTasksView()
+	var got string
+	v.SetOnSelected(func(taskId string) { got = taskId })
+
+	v.SelectInstance("render", 2)
+	if got != "render" || v.SelectedMapIndex() != 2 {
+		t.Fatalf("selected %q[%d], want render[2]", got, v.SelectedMapIndex())
+	}
+	v.SelectTask("render")
+	if v.SelectedMapIndex() != models.NotMapped {
+		t.Fatalf("SelectTask kept map index %d", v.SelectedMapIndex())
+	}
+}
+
+func TestTasksViewDefinitionsGroupTasks(t *testing.T) {
+	v := NewTasksView()
+	var got string
+	v.SetOnSelected(func(taskId string) { got = taskId })
+	v.UpdateDefinitions("etl", groupedTasks())
+
+	if rows := v.table.GetRowCount() - 1; rows != 4 {
+		t.Fatalf("rows = %d, want start, extract, load and notify", rows)
+	}
+	enter := func(row int) {
+		v.table.Select(row, 0)
+		v.table.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(tview.Primitive) {})
+	}
+	enter(2)
+	if got != "" || v.table.GetRowCount()-1 != 6 {
+		t.Fatalf("Enter on extract should open it: %d rows, selected %q", v.table.GetRowCount()-1, got)
+	}
+	enter(4)
+	if got != "extract.users" {
+		t.Fatalf("selected %q", got)
+	}
+	if cell := v.table.GetCell(4, 0).Text; cell != rowLabel("  users", true) {
+		t.Fatalf("active row label = %q", cell)
+	}
+}
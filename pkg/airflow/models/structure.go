@@ -0,0 +1,36 @@
+package models
+
+// DAGStructure is the node tree the Airflow UI draws its graph from. Task
+// groups are nodes with children; tasks are the leaves.
+type DAGStructure struct {
+	Nodes []StructureNode `json:"nodes"`
+}
+
+type StructureNode struct {
+	Id       string          `json:"id"`
+	Label    string          `json:"label"`
+	Type     string          `json:"type"`
+	IsMapped bool            `json:"is_mapped"`
+	Children []StructureNode `json:"children"`
+}
+
+// TaskGroups maps every task id to the ids of its enclosing groups,
+// outermost first. Tasks outside any group map to nil. The upstream and
+// downstream "join" placeholders the UI adds to each group are skipped.
+func (s DAGStructure) TaskGroups() map[string][]string {
+	out := map[string][]string{}
+	var walk func(nodes []StructureNode, path []string)
+	walk = func(nodes []StructureNode, path []string) {
+		for _, n := range nodes {
+			switch {
+			case n.Type == "join":
+			case len(n.Children) > 0:
+				walk(n.Children, append(path[:len(path):len(path)], n.Id))
+			default:
+				out[n.Id] = path
+			}
+		}
+	}
+	walk(s.Nodes, nil)
+	return out
+}
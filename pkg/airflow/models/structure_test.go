@@ -0,0 +1,33 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDAGStructure_TaskGroups(t *testing.T) {
+	raw := `{"nodes":[
+		{"id":"start","label":"start","type":"task"},
+		{"id":"extract","label":"extract","type":"task","children":[
+			{"id":"extract.upstream_join_id","label":"","type":"join"},
+			{"id":"extract.orders","label":"orders","type":"task"},
+			{"id":"extract.api","label":"api","type":"task","children":[
+				{"id":"extract.api.users","label":"users","type":"task"}
+			]},
+			{"id":"extract.downstream_join_id","label":"","type":"join"}
+		]}
+	],"edges":[]}`
+	var s DAGStructure
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"start":             nil,
+		"extract.orders":    {"extract"},
+		"extract.api.users": {"extract", "extract.api"},
+	}
+	if got := s.TaskGroups(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("TaskGroups() = %v, want %v", got, want)
+	}
+}
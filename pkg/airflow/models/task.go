@@ -10,6 +10,10 @@ type Task struct {
 	UpstreamTaskIds   []string `json:"upstream_task_ids"`
 	TriggerRule       string   `json:"trigger_rule"`
 	Retries           float64  `json:"retries"`
+	IsMapped          bool     `json:"is_mapped"`
+	// Groups are the task groups enclosing the task, outermost first. The
+	// tasks endpoint does not send them; they come from the DAG structure.
+	Groups []string `json:"task_groups,omitempty"`
 }
 
 type TaskCollection struct {
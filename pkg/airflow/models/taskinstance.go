@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"strconv"
+	"time"
+)
 
 type TaskInstance struct {
 	TaskId          string     `json:"task_id"`
@@ -17,6 +20,27 @@ type TaskInstance struct {
 	Pool            string     `json:"pool"`
 	Queue           string     `json:"queue"`
 	Hostname        string     `json:"hostname"`
+	Note            string     `json:"note"`
+	// MapIndex is the instance's place in a mapped task's expansion,
+	// NotMapped for an ordinary task. RenderedMapIndex is the label the DAG
+	// gave it (map_index_template), empty when it sets none.
+	MapIndex         int    `json:"map_index"`
+	RenderedMapIndex string `json:"rendered_map_index"`
+}
+
+// NotMapped is the map index of a task instance that is not mapped.
+const NotMapped = -1
+
+// Mapped reports whether the instance is one expansion of a mapped task.
+func (ti TaskInstance) Mapped() bool { return ti.MapIndex > NotMapped }
+
+// MapLabel names one expansion of a mapped task: the rendered map index
+// when the DAG sets one, the bare index otherwise.
+func (ti TaskInstance) MapLabel() string {
+	if ti.RenderedMapIndex != "" {
+		return ti.RenderedMapIndex
+	}
+	return strconv.Itoa(ti.MapIndex)
 }
 
 type TaskInstanceCollection struct {
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTaskInstance_mapLabel(t *testing.T) {
+	var tis TaskInstanceCollection
+	data := `{"task_instances":[
+		{"task_id":"extract","map_index":-1},
+		{"task_id":"render","map_index":0,"rendered_map_index":"emea"},
+		{"task_id":"render","map_index":1,"rendered_map_index":null}
+	]}`
+	if err := json.Unmarshal([]byte(data), &tis); err != nil {
+		t.Fatal(err)
+	}
+	plain, named, bare := tis.TaskInstances[0], tis.TaskInstances[1], tis.TaskInstances[2]
+	if plain.Mapped() || !named.Mapped() || !bare.Mapped() {
+		t.Fatalf("mapped = %v %v %v", plain.Mapped(), named.Mapped(), bare.Mapped())
+	}
+	if named.MapLabel() != "emea" || bare.MapLabel() != "1" {
+		t.Fatalf("labels = %q, %q", named.MapLabel(), bare.MapLabel())
+	}
+}